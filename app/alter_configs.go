@@ -0,0 +1,66 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type AlterConfigsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewAlterConfigsHandler(broker *Broker) *AlterConfigsHandler {
+	return &AlterConfigsHandler{FlexibleSince: 2, broker: broker}
+}
+
+func (h *AlterConfigsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseAlterConfigs(r, version)
+}
+
+// Handle replaces the whole set of dynamic configs of each resource.
+func (h *AlterConfigsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.AlterConfigs)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.AlterConfigs{Version: rb.Version, Responses: []responses.AlterConfigsResourceResponse{}}
+	for _, res := range rb.Resources {
+		err := h.broker.replaceConfigs(res, rb.ValidateOnly)
+		resp.Responses = append(resp.Responses, alterConfigsResponse(res.ResourceType, res.ResourceName, err))
+	}
+	return resp, nil
+}
+
+func (b *Broker) replaceConfigs(res requests.AlterConfigsResource, validateOnly bool) error {
+	configs := make(map[string]string, len(res.Configs))
+	for _, c := range res.Configs {
+		if _, ok := configs[c.Name]; ok {
+			return fmt.Errorf("%w: config %s is listed more than once", errInvalidRequest, c.Name)
+		}
+		if c.Value == nil {
+			return fmt.Errorf("%w: null value for config %s", errInvalidRequest, c.Name)
+		}
+		configs[c.Name] = *c.Value
+	}
+	resource, err := b.configResource(res.ResourceType, res.ResourceName)
+	if err != nil {
+		return err
+	}
+	return b.alterConfigs(resource, func(map[string]string) (map[string]string, error) {
+		return configs, nil
+	}, validateOnly)
+}
+
+func alterConfigsResponse(resourceType int8, name string, err error) responses.AlterConfigsResourceResponse {
+	r := responses.AlterConfigsResourceResponse{ResourceType: resourceType, ResourceName: name, ErrorCode: NONE}
+	if err != nil {
+		message := err.Error()
+		r.ErrorCode, r.ErrorMessage = topicErrorCode(err), &message
+	}
+	return r
+}
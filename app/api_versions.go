@@ -0,0 +1,53 @@
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+// APIVersionsHandler advertises every API known to its registry and the
+// features of the broker.
+type APIVersionsHandler struct {
+	FlexibleSince
+	registry *Registry
+	broker   *Broker
+}
+
+func NewAPIVersionsHandler(registry *Registry, broker *Broker) *APIVersionsHandler {
+	return &APIVersionsHandler{FlexibleSince: 3, registry: registry, broker: broker}
+}
+
+func (h *APIVersionsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseAPIVersions(r, version)
+}
+
+// ResponseHeaderVersion is always 0: clients have to be able to read the
+// ApiVersions response before they know which versions the broker speaks.
+func (h *APIVersionsHandler) ResponseHeaderVersion(version int16) int16 {
+	return 0
+}
+
+// Handle answers unsupported versions with UNSUPPORTED_VERSION in the v0
+// body format, which every client can read, so that the client can pick
+// a version from the list and retry.
+func (h *APIVersionsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	version := req.Header.GetAPIVersion()
+	if !h.registry.Supports(ApiVersions, version) {
+		return &responses.APIVersions{
+			Version:   0,
+			ErrorCode: UNSUPPORTED_VERSION,
+			APIKeys:   h.registry.APIKeys(),
+		}, nil
+	}
+	return &responses.APIVersions{
+		Version:                version,
+		ErrorCode:              NONE,
+		APIKeys:                h.registry.APIKeys(),
+		SupportedFeatures:      supportedFeatureList(),
+		FinalizedFeaturesEpoch: h.broker.Metadata.Offset(),
+		FinalizedFeatures:      h.broker.finalizedFeatureList(),
+	}, nil
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/config"
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Broker holds the state shared by the request handlers.
+type Broker struct {
+	Config   *config.Config
+	Logs     *log.Manager
+	Metadata *metadata.Store
+	Groups   *group.Coordinator
+	// ClusterID comes from the meta.properties written when the storage
+	// was formatted, nil if there is none.
+	ClusterID *string
+
+	topicConfigs topicConfigCache
+}
+
+// NewBroker replays the metadata log and opens every partition log found
+// under log.dirs. Groups are unavailable until LoadGroups has read them
+// back from __consumer_offsets.
+func NewBroker(cfg *config.Config) (*Broker, error) {
+	md, err := metadata.Open(cfg.MetadataLogDir)
+	if err != nil {
+		return nil, err
+	}
+	b := &Broker{Config: cfg, Metadata: md, ClusterID: readClusterID(cfg.MetadataLogDir)}
+	offsetsPartitions := cfg.OffsetsTopicNumPartitions
+	if t, ok := md.Topic(OffsetsTopic); ok {
+		offsetsPartitions = int32(len(t.Partitions))
+	}
+	b.Groups = group.NewCoordinator(group.Config{
+		MinSessionTimeout:     time.Duration(cfg.GroupMinSessionTimeoutMs) * time.Millisecond,
+		MaxSessionTimeout:     time.Duration(cfg.GroupMaxSessionTimeoutMs) * time.Millisecond,
+		InitialRebalanceDelay: time.Duration(cfg.GroupInitialRebalanceDelay) * time.Millisecond,
+		MaxSize:               int(cfg.GroupMaxSize),
+		OffsetsPartitions:     offsetsPartitions,
+		MaxMetadataSize:       int(cfg.OffsetMetadataMaxBytes),
+	})
+	b.Groups.SetStore(b.appendGroupRecords)
+	b.Logs = log.NewManager(cfg.LogDirs, LogConfig(cfg))
+	b.Logs.SetTopicConfig(b.logConfig)
+	if err := b.Logs.Load(); err != nil {
+		md.Close()
+		return nil, err
+	}
+	if t, ok := md.Topic(OffsetsTopic); ok {
+		partitions := make([]int32, len(t.Partitions))
+		for i, p := range t.Partitions {
+			partitions[i] = p.Index
+		}
+		b.Groups.StartLoading(partitions)
+	}
+	return b, nil
+}
+
+// MetaPropertiesFile identifies the cluster and node a log directory
+// belongs to.
+const MetaPropertiesFile = "meta.properties"
+
+func readClusterID(dir string) *string {
+	props, err := config.LoadProperties(filepath.Join(dir, MetaPropertiesFile))
+	if err != nil {
+		return nil
+	}
+	if id, ok := props["cluster.id"]; ok {
+		return &id
+	}
+	return nil
+}
+
+// LogConfig derives the partition log settings from server.properties
+// alone; logConfig adds the dynamic and per-topic layers.
+func LogConfig(cfg *config.Config) log.Config {
+	return log.Config{
+		SegmentBytes:           cfg.LogSegmentBytes,
+		SegmentMs:              cfg.LogRollMs,
+		IndexIntervalBytes:     cfg.LogIndexIntervalBytes,
+		MaxIndexBytes:          cfg.LogIndexSizeMaxBytes,
+		RetentionMs:            cfg.LogRetentionMs,
+		RetentionBytes:         cfg.LogRetentionBytes,
+		Delete:                 true,
+		DeleteRetentionMs:      cfg.LogCleanerDeleteRetentionMs,
+		MinCleanableDirtyRatio: cfg.LogCleanerMinCleanableRatio,
+		MinCompactionLagMs:     cfg.LogCleanerMinCompactionLag,
+		MaxCompactionLagMs:     cfg.LogCleanerMaxCompactionLag,
+		FileDeleteDelayMs:      cfg.LogSegmentDeleteDelayMs,
+	}
+}
+
+// partitionLog returns the log of a partition, creating it when the
+// metadata image assigns the partition but it has no directory yet.
+func (b *Broker) partitionLog(topic string, partition int32) (*log.Log, bool, error) {
+	tp := log.TopicPartition{Topic: topic, Partition: partition}
+	if l, ok := b.Logs.Get(tp); ok {
+		return l, true, nil
+	}
+	t, ok := b.Metadata.Topic(topic)
+	if !ok || !hasPartition(t, partition) {
+		return nil, false, nil
+	}
+	l, err := b.Logs.GetOrCreate(tp)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := l.SetTopicID(t.ID); err != nil {
+		return nil, false, err
+	}
+	return l, true, nil
+}
+
+func hasPartition(t metadata.Topic, partition int32) bool {
+	for _, p := range t.Partitions {
+		if p.Index == partition {
+			return true
+		}
+	}
+	return false
+}
+
+// leaderEpoch is the current leader epoch of a partition, -1 when the
+// metadata image does not know the partition.
+func (b *Broker) leaderEpoch(topic string, partition int32) int32 {
+	t, _ := b.Metadata.Topic(topic)
+	for _, p := range t.Partitions {
+		if p.Index == partition {
+			return p.LeaderEpoch
+		}
+	}
+	return -1
+}
+
+// topicName resolves a topic id, falling back to the partition.metadata
+// of logs the metadata image does not know about.
+func (b *Broker) topicName(id types.UUID) (string, bool) {
+	if t, ok := b.Metadata.TopicByID(id); ok {
+		return t.Name, true
+	}
+	return b.Logs.TopicName(id)
+}
+
+func (b *Broker) Close() error {
+	return errors.Join(b.Logs.Close(), b.Metadata.Close())
+}
@@ -0,0 +1,231 @@
+// Package compression implements the record batch codecs selected by the
+// low three bits of the batch attributes.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+type Codec int16
+
+const (
+	None   Codec = 0
+	Gzip   Codec = 1
+	Snappy Codec = 2
+	LZ4    Codec = 3
+	Zstd   Codec = 4
+)
+
+// Producer is the compression.type value that keeps batches compressed
+// the way the producer sent them.
+const Producer = "producer"
+
+var (
+	ErrUnsupported = errors.New("unsupported compression codec")
+	ErrTooLarge    = errors.New("decompressed records too large")
+)
+
+func (c Codec) String() string {
+	switch c {
+	case None:
+		return "uncompressed"
+	case Gzip:
+		return "gzip"
+	case Snappy:
+		return "snappy"
+	case LZ4:
+		return "lz4"
+	case Zstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("codec(%d)", int16(c))
+}
+
+// Valid reports whether c is a codec this package implements.
+func (c Codec) Valid() bool {
+	return c >= None && c <= Zstd
+}
+
+// ParseType maps a compression.type value to its codec. "producer" has no
+// codec of its own and is reported with ok false.
+func ParseType(name string) (c Codec, ok bool, err error) {
+	switch name {
+	case Producer:
+		return None, false, nil
+	case "uncompressed", "none":
+		return None, true, nil
+	case "gzip":
+		return Gzip, true, nil
+	case "snappy":
+		return Snappy, true, nil
+	case "lz4":
+		return LZ4, true, nil
+	case "zstd":
+		return Zstd, true, nil
+	}
+	return None, false, fmt.Errorf("%w: %q", ErrUnsupported, name)
+}
+
+// Decompress returns the records section held in data, failing with
+// ErrTooLarge rather than decompressing more than limit bytes.
+func Decompress(c Codec, data []byte, limit int) ([]byte, error) {
+	switch c {
+	case None:
+		return data, nil
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readLimited(r, limit)
+	case Snappy:
+		return decodeSnappy(data, limit)
+	case LZ4:
+		return readLimited(lz4.NewReader(bytes.NewReader(data)), limit)
+	case Zstd:
+		// The memory bound also caps the window size a frame may ask for,
+		// so it cannot go below the window of the default levels.
+		memory := max(uint64(limit)+1, zstdMinMemory)
+		r, err := zstd.NewReader(bytes.NewReader(data),
+			zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(memory))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readLimited(r, limit)
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnsupported, c)
+}
+
+// readLimited reads r to the end, failing once it yields more than limit
+// bytes.
+func readLimited(r io.Reader, limit int) ([]byte, error) {
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, limit)
+		}
+		return nil, err
+	}
+	if len(out) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, limit)
+	}
+	return out, nil
+}
+
+// Compress encodes data with c, using the framing the Java client emits
+// so that any consumer can read the result.
+func Compress(c Codec, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch c {
+	case None:
+		return data, nil
+	case Gzip:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case Snappy:
+		return encodeSnappy(data), nil
+	case LZ4:
+		w := lz4.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case Zstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupported, c)
+	}
+	return buf.Bytes(), nil
+}
+
+var zstdEncoder, _ = zstd.NewWriter(nil)
+
+const zstdMinMemory = 8 << 20
+
+// The Java client wraps snappy in the xerial stream format: a magic
+// header, a version pair and a sequence of length prefixed raw blocks.
+var xerialMagic = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+const (
+	xerialHeaderSize = 16
+	xerialBlockSize  = 32 * 1024
+)
+
+// decodeSnappy checks the decoded length every block declares before
+// decoding it.
+func decodeSnappy(data []byte, limit int) ([]byte, error) {
+	if !bytes.HasPrefix(data, xerialMagic) {
+		if err := checkSnappyLen(data, limit); err != nil {
+			return nil, err
+		}
+		return snappy.Decode(nil, data)
+	}
+	if len(data) < xerialHeaderSize {
+		return nil, fmt.Errorf("snappy: truncated xerial header")
+	}
+	var out []byte
+	for rest := data[xerialHeaderSize:]; len(rest) > 0; {
+		if len(rest) < 4 {
+			return nil, fmt.Errorf("snappy: truncated xerial block length")
+		}
+		n := int(binary.BigEndian.Uint32(rest))
+		rest = rest[4:]
+		if n > len(rest) {
+			return nil, fmt.Errorf("snappy: xerial block of %d bytes exceeds %d remaining", n, len(rest))
+		}
+		if err := checkSnappyLen(rest[:n], limit-len(out)); err != nil {
+			return nil, err
+		}
+		block, err := snappy.Decode(nil, rest[:n])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, block...)
+		rest = rest[n:]
+	}
+	return out, nil
+}
+
+func checkSnappyLen(block []byte, limit int) error {
+	n, err := snappy.DecodedLen(block)
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("%w: more than %d bytes", ErrTooLarge, limit)
+	}
+	return nil
+}
+
+func encodeSnappy(data []byte) []byte {
+	out := make([]byte, 0, xerialHeaderSize+snappy.MaxEncodedLen(len(data)))
+	out = append(out, xerialMagic...)
+	out = binary.BigEndian.AppendUint32(out, 1) // version
+	out = binary.BigEndian.AppendUint32(out, 1) // minimum compatible version
+	for len(data) > 0 {
+		n := min(len(data), xerialBlockSize)
+		block := snappy.Encode(nil, data[:n])
+		out = binary.BigEndian.AppendUint32(out, uint32(len(block)))
+		out = append(out, block...)
+		data = data[n:]
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var codecs = []Codec{None, Gzip, Snappy, LZ4, Zstd}
+
+func TestRoundTrip(t *testing.T) {
+	inputs := map[string][]byte{
+		"empty":      {},
+		"short":      []byte("hello, kafka"),
+		"repetitive": bytes.Repeat([]byte("abcd"), 20000),
+		// Longer than one xerial block, so snappy frames several.
+		"multi-block": bytes.Repeat([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 10000),
+	}
+	for _, c := range codecs {
+		for name, in := range inputs {
+			t.Run(c.String()+"/"+name, func(t *testing.T) {
+				compressed, err := Compress(c, in)
+				if err != nil {
+					t.Fatalf("Compress: %v", err)
+				}
+				out, err := Decompress(c, compressed, len(in))
+				if err != nil {
+					t.Fatalf("Decompress: %v", err)
+				}
+				if !bytes.Equal(out, in) {
+					t.Fatalf("round trip changed %d bytes into %d bytes", len(in), len(out))
+				}
+			})
+		}
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	bomb := make([]byte, 4<<20)
+	for _, c := range codecs[1:] {
+		t.Run(c.String(), func(t *testing.T) {
+			compressed, err := Compress(c, bomb)
+			if err != nil {
+				t.Fatalf("Compress: %v", err)
+			}
+			if len(compressed) >= len(bomb)/10 {
+				t.Fatalf("%d bytes compressed to %d, not a bomb", len(bomb), len(compressed))
+			}
+			if _, err := Decompress(c, compressed, len(bomb)-1); !errors.Is(err, ErrTooLarge) {
+				t.Fatalf("Decompress below the size: got %v, want ErrTooLarge", err)
+			}
+			if _, err := Decompress(c, compressed, len(bomb)); err != nil {
+				t.Fatalf("Decompress at the size: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecompressCorrupt(t *testing.T) {
+	for _, c := range codecs[1:] {
+		t.Run(c.String(), func(t *testing.T) {
+			if _, err := Decompress(c, []byte("definitely not compressed"), 1<<20); err == nil {
+				t.Fatal("Decompress accepted garbage")
+			}
+		})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+		ok    bool
+		err   bool
+	}{
+		{"producer", None, false, false},
+		{"uncompressed", None, true, false},
+		{"gzip", Gzip, true, false},
+		{"snappy", Snappy, true, false},
+		{"lz4", LZ4, true, false},
+		{"zstd", Zstd, true, false},
+		{"brotli", None, false, true},
+	}
+	for _, tt := range tests {
+		c, ok, err := ParseType(tt.name)
+		if c != tt.codec || ok != tt.ok || (err != nil) != tt.err {
+			t.Errorf("ParseType(%q) = %v, %v, %v; want %v, %v, error %v", tt.name, c, ok, err, tt.codec, tt.ok, tt.err)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Type int
+
+const (
+	Boolean Type = iota
+	String
+	Short
+	Int
+	Long
+	Double
+	List
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "BOOLEAN"
+	case String:
+		return "STRING"
+	case Short:
+		return "SHORT"
+	case Int:
+		return "INT"
+	case Long:
+		return "LONG"
+	case Double:
+		return "DOUBLE"
+	case List:
+		return "LIST"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Validator checks an already type-converted value.
+type Validator func(v any) error
+
+// Def describes a single broker property.
+type Def struct {
+	Name      string
+	Type      Type
+	Default   string
+	Validator Validator
+}
+
+func atLeast(n float64) Validator {
+	return func(v any) error {
+		if toFloat(v) < n {
+			return fmt.Errorf("value must be at least %v", n)
+		}
+		return nil
+	}
+}
+
+func between(lo, hi float64) Validator {
+	return func(v any) error {
+		if f := toFloat(v); f < lo || f > hi {
+			return fmt.Errorf("value must be between %v and %v", lo, hi)
+		}
+		return nil
+	}
+}
+
+func oneOf(values ...string) Validator {
+	return func(v any) error {
+		var items []string
+		switch t := v.(type) {
+		case string:
+			items = []string{t}
+		case []string:
+			items = t
+		}
+		for _, item := range items {
+			if !slices.Contains(values, item) {
+				return fmt.Errorf("value %q must be one of %s", item, strings.Join(values, ", "))
+			}
+		}
+		return nil
+	}
+}
+
+func toFloat(v any) float64 {
+	switch t := v.(type) {
+	case int16:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case float64:
+		return t
+	}
+	return math.NaN()
+}
+
+var (
+	compressionTypes = []string{"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"}
+	cleanupPolicies  = []string{"delete", "compact"}
+	timestampTypes   = []string{"CreateTime", "LogAppendTime"}
+)
+
+// Defs lists every property the broker understands. Unknown properties
+// are accepted and kept in Config.Originals but otherwise ignored.
+var Defs = []Def{
+	{Name: "node.id", Type: Int, Default: "-1", Validator: atLeast(-1)},
+	{Name: "broker.id", Type: Int, Default: "-1", Validator: atLeast(-1)},
+	{Name: "process.roles", Type: List, Default: "", Validator: oneOf("broker", "controller")},
+	{Name: "listeners", Type: List, Default: "PLAINTEXT://:9092"},
+	{Name: "advertised.listeners", Type: List, Default: ""},
+	{Name: "controller.listener.names", Type: List, Default: ""},
+	{Name: "inter.broker.listener.name", Type: String, Default: ""},
+	{Name: "listener.security.protocol.map", Type: List, Default: "PLAINTEXT:PLAINTEXT,SSL:SSL,SASL_PLAINTEXT:SASL_PLAINTEXT,SASL_SSL:SASL_SSL"},
+	{Name: "controller.quorum.voters", Type: List, Default: ""},
+	{Name: "broker.rack", Type: String, Default: ""},
+	{Name: "log.dir", Type: String, Default: "/tmp/kafka-logs"},
+	{Name: "log.dirs", Type: List, Default: ""},
+	{Name: "metadata.log.dir", Type: String, Default: ""},
+	{Name: "num.partitions", Type: Int, Default: "1", Validator: atLeast(1)},
+	{Name: "default.replication.factor", Type: Short, Default: "1", Validator: atLeast(1)},
+	{Name: "auto.create.topics.enable", Type: Boolean, Default: "true"},
+	{Name: "delete.topic.enable", Type: Boolean, Default: "true"},
+	{Name: "socket.request.max.bytes", Type: Int, Default: "104857600", Validator: atLeast(1)},
+	{Name: "message.max.bytes", Type: Int, Default: "1048588", Validator: atLeast(0)},
+	{Name: "max.request.partition.size.limit", Type: Int, Default: "2000", Validator: atLeast(1)},
+	{Name: "compression.type", Type: String, Default: "producer", Validator: oneOf(compressionTypes...)},
+	{Name: "log.message.timestamp.type", Type: String, Default: "CreateTime", Validator: oneOf(timestampTypes...)},
+	{Name: "log.segment.bytes", Type: Int, Default: "1073741824", Validator: atLeast(14)},
+	{Name: "log.roll.hours", Type: Int, Default: "168", Validator: atLeast(1)},
+	{Name: "log.roll.ms", Type: Long, Default: "", Validator: atLeast(1)},
+	{Name: "log.index.interval.bytes", Type: Int, Default: "4096", Validator: atLeast(0)},
+	{Name: "log.index.size.max.bytes", Type: Int, Default: "10485760", Validator: atLeast(4)},
+	{Name: "log.retention.hours", Type: Int, Default: "168"},
+	{Name: "log.retention.minutes", Type: Int, Default: ""},
+	{Name: "log.retention.ms", Type: Long, Default: ""},
+	{Name: "log.retention.bytes", Type: Long, Default: "-1"},
+	{Name: "log.retention.check.interval.ms", Type: Long, Default: "300000", Validator: atLeast(1)},
+	{Name: "log.cleanup.policy", Type: List, Default: "delete", Validator: oneOf(cleanupPolicies...)},
+	{Name: "log.segment.delete.delay.ms", Type: Long, Default: "60000", Validator: atLeast(0)},
+	{Name: "log.flush.interval.messages", Type: Long, Default: maxLong, Validator: atLeast(1)},
+	{Name: "log.flush.interval.ms", Type: Long, Default: ""},
+	{Name: "min.insync.replicas", Type: Int, Default: "1", Validator: atLeast(1)},
+	{Name: "unclean.leader.election.enable", Type: Boolean, Default: "false"},
+	{Name: "offsets.topic.num.partitions", Type: Int, Default: "50", Validator: atLeast(1)},
+	{Name: "offsets.topic.replication.factor", Type: Short, Default: "3", Validator: atLeast(1)},
+	{Name: "offsets.topic.segment.bytes", Type: Int, Default: "104857600", Validator: atLeast(1)},
+	{Name: "offset.metadata.max.bytes", Type: Int, Default: "4096", Validator: atLeast(0)},
+	{Name: "group.min.session.timeout.ms", Type: Int, Default: "6000"},
+	{Name: "group.max.session.timeout.ms", Type: Int, Default: "1800000"},
+	{Name: "group.initial.rebalance.delay.ms", Type: Int, Default: "3000", Validator: atLeast(0)},
+	{Name: "group.max.size", Type: Int, Default: "2147483647", Validator: atLeast(1)},
+	{Name: "num.recovery.threads.per.data.dir", Type: Int, Default: "1", Validator: atLeast(1)},
+	{Name: "transaction.state.log.replication.factor", Type: Short, Default: "3", Validator: atLeast(1)},
+	{Name: "transaction.state.log.min.isr", Type: Int, Default: "2", Validator: atLeast(1)},
+	{Name: "log.cleaner.enable", Type: Boolean, Default: "true"},
+	{Name: "log.cleaner.threads", Type: Int, Default: "1", Validator: between(0, 64)},
+	{Name: "log.cleaner.backoff.ms", Type: Long, Default: "15000", Validator: atLeast(1)},
+	{Name: "log.cleaner.delete.retention.ms", Type: Long, Default: "86400000", Validator: atLeast(0)},
+	{Name: "log.cleaner.min.cleanable.ratio", Type: Double, Default: "0.5", Validator: between(0, 1)},
+	{Name: "log.cleaner.min.compaction.lag.ms", Type: Long, Default: "0", Validator: atLeast(0)},
+	{Name: "log.cleaner.max.compaction.lag.ms", Type: Long, Default: maxLong, Validator: atLeast(1)},
+}
+
+var defsByName = func() map[string]Def {
+	m := make(map[string]Def, len(Defs))
+	for _, d := range Defs {
+		m[d.Name] = d
+	}
+	return m
+}()
+
+// Lookup returns the definition for a property name.
+func Lookup(name string) (Def, bool) {
+	d, ok := defsByName[name]
+	return d, ok
+}
+
+// ParseValue converts raw into the Go type matching def.Type and runs the
+// definition's validator. Empty strings parse to nil for non-string types.
+func ParseValue(def Def, raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	var v any
+	switch def.Type {
+	case String:
+		v = raw
+	case List:
+		v = splitList(raw)
+	default:
+		if raw == "" {
+			return nil, nil
+		}
+		var err error
+		switch def.Type {
+		case Boolean:
+			switch strings.ToLower(raw) {
+			case "true":
+				v = true
+			case "false":
+				v = false
+			default:
+				err = fmt.Errorf("expected true or false")
+			}
+		case Short:
+			var n int64
+			n, err = strconv.ParseInt(raw, 10, 16)
+			v = int16(n)
+		case Int:
+			var n int64
+			n, err = strconv.ParseInt(raw, 10, 32)
+			v = int32(n)
+		case Long:
+			var n int64
+			n, err = strconv.ParseInt(raw, 10, 64)
+			v = n
+		case Double:
+			v, err = strconv.ParseFloat(raw, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s (%s): %w", raw, def.Name, def.Type, err)
+		}
+	}
+	if def.Validator != nil {
+		if err := def.Validator(v); err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: %w", raw, def.Name, err)
+		}
+	}
+	return v, nil
+}
+
+func splitList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Endpoint is a parsed entry of listeners or advertised.listeners.
+type Endpoint struct {
+	Name string
+	Host string
+	Port int32
+}
+
+// Address is the host:port form suitable for net.Listen.
+func (e Endpoint) Address() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(int(e.Port)))
+}
+
+func ParseEndpoint(s string) (Endpoint, error) {
+	name, hostPort, ok := strings.Cut(s, "://")
+	if !ok || name == "" {
+		return Endpoint{}, fmt.Errorf("invalid endpoint %q: expected NAME://host:port", s)
+	}
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid endpoint %q: %w", s, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid port in endpoint %q", s)
+	}
+	return Endpoint{Name: strings.ToUpper(name), Host: host, Port: int32(port)}, nil
+}
+
+// Config is the typed, validated view of server.properties.
+type Config struct {
+	NodeID                      int32
+	ProcessRoles                []string
+	Listeners                   []Endpoint
+	AdvertisedListeners         []Endpoint
+	ControllerListenerNames     []string
+	InterBrokerListenerName     string
+	ListenerSecurityProtocolMap map[string]string
+	Rack                        string
+	LogDirs                     []string
+	MetadataLogDir              string
+	NumPartitions               int32
+	DefaultReplicationFactor    int16
+	AutoCreateTopicsEnable      bool
+	DeleteTopicEnable           bool
+	SocketRequestMaxBytes       int32
+	MessageMaxBytes             int32
+	MaxRequestPartitionSize     int32
+	CompressionType             string
+	LogMessageTimestampType     string
+	LogSegmentBytes             int32
+	LogRollMs                   int64
+	LogIndexIntervalBytes       int32
+	LogIndexSizeMaxBytes        int32
+	LogRetentionMs              int64
+	LogRetentionBytes           int64
+	LogRetentionCheckIntervalMs int64
+	LogSegmentDeleteDelayMs     int64
+	OffsetsTopicNumPartitions   int32
+	OffsetsTopicReplication     int16
+	OffsetsTopicSegmentBytes    int32
+	OffsetMetadataMaxBytes      int32
+	GroupMinSessionTimeoutMs    int32
+	GroupMaxSessionTimeoutMs    int32
+	GroupInitialRebalanceDelay  int32
+	GroupMaxSize                int32
+	LogCleanerEnable            bool
+	LogCleanerThreads           int32
+	LogCleanerBackoffMs         int64
+	LogCleanerDeleteRetentionMs int64
+	LogCleanerMinCleanableRatio float64
+	LogCleanerMinCompactionLag  int64
+	LogCleanerMaxCompactionLag  int64
+
+	// Originals holds every property as it appeared in the file.
+	Originals map[string]string
+	values    map[string]any
+}
+
+// Load reads and validates the properties file at path.
+func Load(path string) (*Config, error) {
+	props, err := LoadProperties(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(props)
+}
+
+// Default returns the configuration used when no properties file is given.
+func Default() *Config {
+	c, err := New(map[string]string{})
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// New validates props against Defs and builds a Config.
+func New(props map[string]string) (*Config, error) {
+	c := &Config{Originals: props, values: make(map[string]any, len(Defs))}
+	for _, def := range Defs {
+		raw, ok := props[def.Name]
+		if !ok {
+			raw = def.Default
+		}
+		v, err := ParseValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		c.values[def.Name] = v
+	}
+
+	c.NodeID = c.values["node.id"].(int32)
+	if c.NodeID < 0 {
+		c.NodeID = c.values["broker.id"].(int32)
+	}
+	c.ProcessRoles = c.values["process.roles"].([]string)
+	c.ControllerListenerNames = c.values["controller.listener.names"].([]string)
+	c.InterBrokerListenerName = c.values["inter.broker.listener.name"].(string)
+	c.Rack = c.values["broker.rack"].(string)
+	c.NumPartitions = c.values["num.partitions"].(int32)
+	c.DefaultReplicationFactor = c.values["default.replication.factor"].(int16)
+	c.AutoCreateTopicsEnable = c.values["auto.create.topics.enable"].(bool)
+	c.DeleteTopicEnable = c.values["delete.topic.enable"].(bool)
+	c.SocketRequestMaxBytes = c.values["socket.request.max.bytes"].(int32)
+	c.MessageMaxBytes = c.values["message.max.bytes"].(int32)
+	c.MaxRequestPartitionSize = c.values["max.request.partition.size.limit"].(int32)
+	c.CompressionType = c.values["compression.type"].(string)
+	c.LogMessageTimestampType = c.values["log.message.timestamp.type"].(string)
+	c.LogSegmentBytes = c.values["log.segment.bytes"].(int32)
+	c.LogIndexIntervalBytes = c.values["log.index.interval.bytes"].(int32)
+	c.LogIndexSizeMaxBytes = c.values["log.index.size.max.bytes"].(int32)
+	c.LogRetentionBytes = c.values["log.retention.bytes"].(int64)
+	c.LogRetentionCheckIntervalMs = c.values["log.retention.check.interval.ms"].(int64)
+	c.LogSegmentDeleteDelayMs = c.values["log.segment.delete.delay.ms"].(int64)
+	c.OffsetsTopicNumPartitions = c.values["offsets.topic.num.partitions"].(int32)
+	c.OffsetsTopicReplication = c.values["offsets.topic.replication.factor"].(int16)
+	c.OffsetsTopicSegmentBytes = c.values["offsets.topic.segment.bytes"].(int32)
+	c.OffsetMetadataMaxBytes = c.values["offset.metadata.max.bytes"].(int32)
+	c.GroupMinSessionTimeoutMs = c.values["group.min.session.timeout.ms"].(int32)
+	c.GroupMaxSessionTimeoutMs = c.values["group.max.session.timeout.ms"].(int32)
+	c.GroupInitialRebalanceDelay = c.values["group.initial.rebalance.delay.ms"].(int32)
+	c.GroupMaxSize = c.values["group.max.size"].(int32)
+	c.LogCleanerEnable = c.values["log.cleaner.enable"].(bool)
+	c.LogCleanerThreads = c.values["log.cleaner.threads"].(int32)
+	c.LogCleanerBackoffMs = c.values["log.cleaner.backoff.ms"].(int64)
+	c.LogCleanerDeleteRetentionMs = c.values["log.cleaner.delete.retention.ms"].(int64)
+	c.LogCleanerMinCleanableRatio = c.values["log.cleaner.min.cleanable.ratio"].(float64)
+	c.LogCleanerMinCompactionLag = c.values["log.cleaner.min.compaction.lag.ms"].(int64)
+	c.LogCleanerMaxCompactionLag = c.values["log.cleaner.max.compaction.lag.ms"].(int64)
+
+	if c.GroupMinSessionTimeoutMs > c.GroupMaxSessionTimeoutMs {
+		return nil, fmt.Errorf("group.min.session.timeout.ms must not exceed group.max.session.timeout.ms")
+	}
+
+	// The most specific unit wins, as upstream does.
+	c.LogRollMs = int64(c.values["log.roll.hours"].(int32)) * 60 * 60 * 1000
+	if v, ok := c.values["log.roll.ms"].(int64); ok {
+		c.LogRollMs = v
+	}
+	c.LogRetentionMs = int64(c.values["log.retention.hours"].(int32)) * 60 * 60 * 1000
+	if v, ok := c.values["log.retention.minutes"].(int32); ok {
+		c.LogRetentionMs = int64(v) * 60 * 1000
+	}
+	if v, ok := c.values["log.retention.ms"].(int64); ok {
+		c.LogRetentionMs = v
+	}
+	if c.LogRetentionMs < -1 {
+		return nil, fmt.Errorf("log retention must be -1 (unlimited) or non-negative, got %d", c.LogRetentionMs)
+	}
+
+	c.LogDirs = c.values["log.dirs"].([]string)
+	if len(c.LogDirs) == 0 {
+		c.LogDirs = []string{c.values["log.dir"].(string)}
+	}
+	c.MetadataLogDir = c.values["metadata.log.dir"].(string)
+	if c.MetadataLogDir == "" {
+		c.MetadataLogDir = c.LogDirs[0]
+	}
+
+	var err error
+	if c.ListenerSecurityProtocolMap, err = parseProtocolMap(c.values["listener.security.protocol.map"].([]string)); err != nil {
+		return nil, err
+	}
+	if c.Listeners, err = parseEndpoints("listeners", c.values["listeners"].([]string)); err != nil {
+		return nil, err
+	}
+	if len(c.Listeners) == 0 {
+		return nil, fmt.Errorf("listeners must not be empty")
+	}
+	if c.AdvertisedListeners, err = parseEndpoints("advertised.listeners", c.values["advertised.listeners"].([]string)); err != nil {
+		return nil, err
+	}
+	if len(c.AdvertisedListeners) == 0 {
+		for _, l := range c.Listeners {
+			if !slices.Contains(c.ControllerListenerNames, l.Name) {
+				c.AdvertisedListeners = append(c.AdvertisedListeners, l)
+			}
+		}
+	}
+	for _, l := range append(slices.Clone(c.Listeners), c.AdvertisedListeners...) {
+		if _, ok := c.ListenerSecurityProtocolMap[l.Name]; !ok {
+			return nil, fmt.Errorf("listener %s is not defined in listener.security.protocol.map", l.Name)
+		}
+	}
+	if c.InterBrokerListenerName == "" {
+		c.InterBrokerListenerName = "PLAINTEXT"
+	}
+	return c, nil
+}
+
+func parseEndpoints(name string, items []string) ([]Endpoint, error) {
+	endpoints := make([]Endpoint, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		e, err := ParseEndpoint(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("%s: duplicate listener name %s", name, e.Name)
+		}
+		seen[e.Name] = true
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
+
+func parseProtocolMap(items []string) (map[string]string, error) {
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		name, protocol, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("listener.security.protocol.map: invalid entry %q", item)
+		}
+		m[strings.ToUpper(strings.TrimSpace(name))] = strings.ToUpper(strings.TrimSpace(protocol))
+	}
+	return m, nil
+}
+
+// BrokerListeners returns the listeners that serve client traffic, that is
+// every listener not named in controller.listener.names.
+func (c *Config) BrokerListeners() []Endpoint {
+	var endpoints []Endpoint
+	for _, l := range c.Listeners {
+		if !slices.Contains(c.ControllerListenerNames, l.Name) {
+			endpoints = append(endpoints, l)
+		}
+	}
+	return endpoints
+}
+
+// AdvertisedListener returns the advertised endpoint for a listener name.
+func (c *Config) AdvertisedListener(name string) (Endpoint, bool) {
+	for _, l := range c.AdvertisedListeners {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// Value returns the typed value of a known property.
+func (c *Config) Value(name string) (any, bool) {
+	v, ok := c.values[name]
+	return v, ok
+}
+
+// Unknown lists properties that are not in Defs, sorted by name.
+func (c *Config) Unknown() []string {
+	var names []string
+	for name := range c.Originals {
+		if _, ok := defsByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
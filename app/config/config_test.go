@@ -0,0 +1,102 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewDefaults(t *testing.T) {
+	c, err := New(map[string]string{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.NumPartitions != 1 || c.MessageMaxBytes != 1048588 || !c.AutoCreateTopicsEnable {
+		t.Fatalf("unexpected defaults: %+v", c)
+	}
+	if want := int64(168 * 60 * 60 * 1000); c.LogRetentionMs != want || c.LogRollMs != want {
+		t.Fatalf("retention %d and roll %d, want %d", c.LogRetentionMs, c.LogRollMs, want)
+	}
+	if !slices.Equal(c.LogDirs, []string{"/tmp/kafka-logs"}) || c.MetadataLogDir != "/tmp/kafka-logs" {
+		t.Fatalf("log dirs %v and metadata log dir %q", c.LogDirs, c.MetadataLogDir)
+	}
+	want := []Endpoint{{Name: "PLAINTEXT", Port: 9092}}
+	if !slices.Equal(c.Listeners, want) || !slices.Equal(c.AdvertisedListeners, want) {
+		t.Fatalf("listeners %v, advertised %v", c.Listeners, c.AdvertisedListeners)
+	}
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(map[string]string{
+		"node.id":                        "2",
+		"process.roles":                  "broker,controller",
+		"listeners":                      "plaintext://localhost:9092,CONTROLLER://:9093",
+		"controller.listener.names":      "CONTROLLER",
+		"listener.security.protocol.map": "PLAINTEXT:PLAINTEXT,CONTROLLER:PLAINTEXT",
+		"log.dirs":                       "/a, /b",
+		"log.retention.hours":            "1",
+		"log.retention.minutes":          "2",
+		"log.roll.ms":                    "500",
+		"some.unknown.property":          "x",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.NodeID != 2 {
+		t.Errorf("NodeID = %d, want 2", c.NodeID)
+	}
+	if !slices.Equal(c.LogDirs, []string{"/a", "/b"}) || c.MetadataLogDir != "/a" {
+		t.Errorf("log dirs %v and metadata log dir %q", c.LogDirs, c.MetadataLogDir)
+	}
+	if c.LogRetentionMs != 2*60*1000 {
+		t.Errorf("LogRetentionMs = %d, want the minutes to win", c.LogRetentionMs)
+	}
+	if c.LogRollMs != 500 {
+		t.Errorf("LogRollMs = %d, want 500", c.LogRollMs)
+	}
+	broker := []Endpoint{{Name: "PLAINTEXT", Host: "localhost", Port: 9092}}
+	if got := c.BrokerListeners(); !slices.Equal(got, broker) {
+		t.Errorf("BrokerListeners() = %v, want %v", got, broker)
+	}
+	if !slices.Equal(c.AdvertisedListeners, broker) {
+		t.Errorf("AdvertisedListeners = %v, want the controller listener left out", c.AdvertisedListeners)
+	}
+	if got := c.Unknown(); !slices.Equal(got, []string{"some.unknown.property"}) {
+		t.Errorf("Unknown() = %v", got)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]string
+		want  string
+	}{
+		{"not a number", map[string]string{"num.partitions": "many"}, "num.partitions"},
+		{"out of range", map[string]string{"num.partitions": "0"}, "at least 1"},
+		{"overflow", map[string]string{"default.replication.factor": "40000"}, "default.replication.factor"},
+		{"not a boolean", map[string]string{"auto.create.topics.enable": "yes"}, "true or false"},
+		{"not one of", map[string]string{"compression.type": "brotli"}, "must be one of"},
+		{"bad role", map[string]string{"process.roles": "broker,voter"}, "must be one of"},
+		{"session timeouts", map[string]string{"group.min.session.timeout.ms": "10", "group.max.session.timeout.ms": "5"}, "must not exceed"},
+		{"negative retention", map[string]string{"log.retention.ms": "-2"}, "log retention"},
+		{"empty listeners", map[string]string{"listeners": ""}, "must not be empty"},
+		{"malformed listener", map[string]string{"listeners": "localhost:9092"}, "expected NAME://host:port"},
+		{"bad port", map[string]string{"listeners": "PLAINTEXT://:99999"}, "invalid port"},
+		{"duplicate listener", map[string]string{"listeners": "PLAINTEXT://:9092,plaintext://:9093"}, "duplicate listener name PLAINTEXT"},
+		{"duplicate advertised listener", map[string]string{"advertised.listeners": "PLAINTEXT://a:9092,PLAINTEXT://b:9092"}, "duplicate listener name PLAINTEXT"},
+		{"unmapped listener", map[string]string{"listeners": "INTERNAL://:9092"}, "not defined in listener.security.protocol.map"},
+		{"bad protocol map", map[string]string{"listener.security.protocol.map": "PLAINTEXT"}, "invalid entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.props)
+			if err == nil {
+				t.Fatalf("New: expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("New: got %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
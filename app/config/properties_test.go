@@ -0,0 +1,85 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{
+			name: "separators",
+			in:   "a=1\nb:2\nc 3\nd = 4\ne\t:\t5\nf\n",
+			want: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": ""},
+		},
+		{
+			name: "comments and blank lines",
+			in:   "# a comment\n! another one\n\n   \nkey=value\n  # indented comment\n",
+			want: map[string]string{"key": "value"},
+		},
+		{
+			name: "separator inside value",
+			in:   "listeners=PLAINTEXT://:9092,CONTROLLER://:9093\nurl = a=b:c\n",
+			want: map[string]string{"listeners": "PLAINTEXT://:9092,CONTROLLER://:9093", "url": "a=b:c"},
+		},
+		{
+			name: "line continuation",
+			in:   "log.dirs=/a,\\\n    /b,\\\n\t/c\nnext=1\n",
+			want: map[string]string{"log.dirs": "/a,/b,/c", "next": "1"},
+		},
+		{
+			name: "continuation at end of input",
+			in:   "key=a\\\n",
+			want: map[string]string{"key": "a"},
+		},
+		{
+			name: "escaped backslash does not continue",
+			in:   "path=C:\\\\\nnext=1\n",
+			want: map[string]string{"path": "C:\\", "next": "1"},
+		},
+		{
+			name: "escapes",
+			in:   "tab=a\\tb\nnl=a\\nb\ncr=a\\rb\nff=a\\fb\nother=\\q\\#\n",
+			want: map[string]string{"tab": "a\tb", "nl": "a\nb", "cr": "a\rb", "ff": "a\fb", "other": "q#"},
+		},
+		{
+			name: "unicode escape",
+			in:   "greeting=caf\\u00e9 \\u4E16\n",
+			want: map[string]string{"greeting": "café 世"},
+		},
+		{
+			name: "escaped separators in key",
+			in:   "a\\=b\\:c\\ d=value\n",
+			want: map[string]string{"a=b:c d": "value"},
+		},
+		{
+			name: "last value wins",
+			in:   "key=1\nkey=2\n",
+			want: map[string]string{"key": "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProperties(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("ParseProperties: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePropertiesMalformedUnicode(t *testing.T) {
+	for _, in := range []string{"key=\\u12\n", "key=\\u12zz\n"} {
+		if _, err := ParseProperties(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseProperties(%q): expected an error", in)
+		}
+	}
+}
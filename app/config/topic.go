@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TopicDef describes a topic-level config. Synonyms lists the broker
+// properties supplying its default, most specific first.
+type TopicDef struct {
+	Def
+	Synonyms []string
+}
+
+const maxLong = "9223372036854775807"
+
+// TopicDefs lists every topic config the broker accepts as an override.
+var TopicDefs = []TopicDef{
+	{Def{Name: "cleanup.policy", Type: List, Default: "delete", Validator: oneOf(cleanupPolicies...)}, []string{"log.cleanup.policy"}},
+	{Def{Name: "compression.type", Type: String, Default: "producer", Validator: oneOf(compressionTypes...)}, []string{"compression.type"}},
+	{Def{Name: "delete.retention.ms", Type: Long, Default: "86400000", Validator: atLeast(0)}, []string{"log.cleaner.delete.retention.ms"}},
+	{Def{Name: "file.delete.delay.ms", Type: Long, Default: "60000", Validator: atLeast(0)}, []string{"log.segment.delete.delay.ms"}},
+	{Def{Name: "flush.messages", Type: Long, Default: maxLong, Validator: atLeast(1)}, []string{"log.flush.interval.messages"}},
+	{Def{Name: "flush.ms", Type: Long, Default: maxLong, Validator: atLeast(0)}, []string{"log.flush.interval.ms"}},
+	{Def{Name: "index.interval.bytes", Type: Int, Default: "4096", Validator: atLeast(0)}, []string{"log.index.interval.bytes"}},
+	{Def{Name: "max.compaction.lag.ms", Type: Long, Default: maxLong, Validator: atLeast(1)}, []string{"log.cleaner.max.compaction.lag.ms"}},
+	{Def{Name: "max.message.bytes", Type: Int, Default: "1048588", Validator: atLeast(0)}, []string{"message.max.bytes"}},
+	{Def{Name: "message.timestamp.type", Type: String, Default: "CreateTime", Validator: oneOf(timestampTypes...)}, []string{"log.message.timestamp.type"}},
+	{Def{Name: "min.cleanable.dirty.ratio", Type: Double, Default: "0.5", Validator: between(0, 1)}, []string{"log.cleaner.min.cleanable.ratio"}},
+	{Def{Name: "min.compaction.lag.ms", Type: Long, Default: "0", Validator: atLeast(0)}, []string{"log.cleaner.min.compaction.lag.ms"}},
+	{Def{Name: "min.insync.replicas", Type: Int, Default: "1", Validator: atLeast(1)}, []string{"min.insync.replicas"}},
+	{Def{Name: "retention.bytes", Type: Long, Default: "-1"}, []string{"log.retention.bytes"}},
+	{Def{Name: "retention.ms", Type: Long, Default: "604800000", Validator: atLeast(-1)}, []string{"log.retention.ms", "log.retention.minutes", "log.retention.hours"}},
+	{Def{Name: "segment.bytes", Type: Int, Default: "1073741824", Validator: atLeast(14)}, []string{"log.segment.bytes"}},
+	{Def{Name: "segment.index.bytes", Type: Int, Default: "10485760", Validator: atLeast(4)}, []string{"log.index.size.max.bytes"}},
+	{Def{Name: "segment.ms", Type: Long, Default: "604800000", Validator: atLeast(1)}, []string{"log.roll.ms", "log.roll.hours"}},
+	{Def{Name: "unclean.leader.election.enable", Type: Boolean, Default: "false"}, []string{"unclean.leader.election.enable"}},
+}
+
+var topicDefsByName = func() map[string]TopicDef {
+	m := make(map[string]TopicDef, len(TopicDefs))
+	for _, d := range TopicDefs {
+		m[d.Name] = d
+	}
+	return m
+}()
+
+// synonymOf maps every broker property that defaults a topic config to
+// that config. Exactly these properties may be changed at runtime.
+var synonymOf = func() map[string]string {
+	m := make(map[string]string)
+	for _, d := range TopicDefs {
+		for _, s := range d.Synonyms {
+			m[s] = d.Name
+		}
+	}
+	return m
+}()
+
+// LookupTopic returns the definition for a topic config name.
+func LookupTopic(name string) (TopicDef, bool) {
+	d, ok := topicDefsByName[name]
+	return d, ok
+}
+
+// Dynamic reports whether a broker property can be altered at runtime.
+func Dynamic(name string) bool {
+	_, ok := synonymOf[name]
+	return ok
+}
+
+// synonymScale converts the coarser units of some synonyms to the
+// milliseconds of their topic config.
+var synonymScale = map[string]int64{
+	"log.retention.minutes": 60 * 1000,
+	"log.retention.hours":   60 * 60 * 1000,
+	"log.roll.hours":        60 * 60 * 1000,
+}
+
+// TopicValue converts the value of a broker synonym to the unit of the
+// topic config it defaults.
+func TopicValue(synonym, raw string) string {
+	scale, ok := synonymScale[synonym]
+	if !ok {
+		return raw
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil {
+		return raw
+	}
+	return strconv.FormatInt(n*scale, 10)
+}
+
+// Static returns a property as set in server.properties.
+func (c *Config) Static(name string) (string, bool) {
+	raw, ok := c.Originals[name]
+	return strings.TrimSpace(raw), ok
+}
@@ -0,0 +1,373 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/nabinkhanal00/kafka/app/config"
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/metadata"
+)
+
+// Config sources, as DescribeConfigs and CreateTopics report them.
+const (
+	configSourceDynamicTopic         int8 = 1
+	configSourceDynamicBroker        int8 = 2
+	configSourceDynamicDefaultBroker int8 = 3
+	configSourceStaticBroker         int8 = 4
+	configSourceDefault              int8 = 5
+)
+
+var (
+	errInvalidConfig  = errors.New("invalid config")
+	errInvalidRequest = errors.New("invalid request")
+)
+
+// configSynonym is one layer setting a config, possibly under the name
+// of a broker property.
+type configSynonym struct {
+	name   string
+	value  string
+	source int8
+}
+
+// configEntry is the effective value of a config. synonyms lists every
+// layer that sets it, the effective one first.
+type configEntry struct {
+	name     string
+	value    *string
+	source   int8
+	readOnly bool
+	typ      config.Type
+	synonyms []configSynonym
+}
+
+// configType is the protocol code DescribeConfigs reports for t.
+func configType(t config.Type) int8 {
+	switch t {
+	case config.Boolean:
+		return 1
+	case config.String:
+		return 2
+	case config.Int:
+		return 3
+	case config.Short:
+		return 4
+	case config.Long:
+		return 5
+	case config.Double:
+		return 6
+	case config.List:
+		return 7
+	}
+	return 0
+}
+
+// parseConfigValue checks value against def. Unlike in server.properties,
+// an empty value cannot stand for "unset" here.
+func parseConfigValue(def config.Def, value string) (any, error) {
+	v, err := config.ParseValue(def, value)
+	if err == nil && v == nil {
+		err = fmt.Errorf("empty value for %s (%s)", def.Name, def.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidConfig, err)
+	}
+	return v, nil
+}
+
+// validateTopicConfigs parses every override against its definition.
+func validateTopicConfigs(configs map[string]string) error {
+	for name, value := range configs {
+		def, ok := config.LookupTopic(name)
+		if !ok {
+			return fmt.Errorf("%w: unknown topic config %s", errInvalidConfig, name)
+		}
+		if _, err := parseConfigValue(def.Def, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBrokerConfigs accepts the broker properties that default a
+// topic config, the only ones that take effect without a restart.
+func validateBrokerConfigs(configs map[string]string) error {
+	for name, value := range configs {
+		def, ok := config.Lookup(name)
+		if !ok {
+			return fmt.Errorf("%w: unknown broker config %s", errInvalidConfig, name)
+		}
+		if !config.Dynamic(name) {
+			return fmt.Errorf("%w: %s cannot be updated dynamically", errInvalidConfig, name)
+		}
+		if _, err := parseConfigValue(def, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func brokerResource(name string) metadata.ConfigResource {
+	return metadata.ConfigResource{Type: metadata.BrokerResource, Name: name}
+}
+
+func topicResource(name string) metadata.ConfigResource {
+	return metadata.ConfigResource{Type: metadata.TopicResource, Name: name}
+}
+
+// brokerSynonyms lists the layers setting any of names in precedence
+// order: this broker's dynamic configs, the cluster-wide dynamic defaults
+// and server.properties.
+func (b *Broker) brokerSynonyms(names []string) []configSynonym {
+	var synonyms []configSynonym
+	layers := []struct {
+		values map[string]string
+		source int8
+	}{
+		{b.Metadata.Configs(brokerResource(strconv.Itoa(int(b.Config.NodeID)))), configSourceDynamicBroker},
+		{b.Metadata.Configs(brokerResource("")), configSourceDynamicDefaultBroker},
+	}
+	for _, layer := range layers {
+		for _, name := range names {
+			if v, ok := layer.values[name]; ok {
+				synonyms = append(synonyms, configSynonym{name: name, value: v, source: layer.source})
+			}
+		}
+	}
+	for _, name := range names {
+		if v, ok := b.Config.Static(name); ok {
+			synonyms = append(synonyms, configSynonym{name: name, value: v, source: configSourceStaticBroker})
+		}
+	}
+	return synonyms
+}
+
+// topicConfigEntries resolves every topic config from the topic's
+// overrides down to the built-in default, which is reported under the
+// first broker synonym having one, as upstream does.
+func (b *Broker) topicConfigEntries(overrides map[string]string) []configEntry {
+	entries := make([]configEntry, 0, len(config.TopicDefs))
+	for _, def := range config.TopicDefs {
+		var synonyms []configSynonym
+		if v, ok := overrides[def.Name]; ok {
+			synonyms = append(synonyms, configSynonym{name: def.Name, value: v, source: configSourceDynamicTopic})
+		}
+		synonyms = append(synonyms, b.brokerSynonyms(def.Synonyms)...)
+		fallback := configSynonym{name: def.Name, value: def.Default, source: configSourceDefault}
+		for _, name := range def.Synonyms {
+			if d, ok := config.Lookup(name); ok && d.Default != "" {
+				fallback = configSynonym{name: name, value: d.Default, source: configSourceDefault}
+				break
+			}
+		}
+		synonyms = append(synonyms, fallback)
+		value := config.TopicValue(synonyms[0].name, synonyms[0].value)
+		entries = append(entries, configEntry{
+			name:     def.Name,
+			value:    &value,
+			source:   synonyms[0].source,
+			typ:      def.Type,
+			synonyms: synonyms,
+		})
+	}
+	return entries
+}
+
+// brokerConfigEntries resolves every broker property. Only those that
+// default a topic config can be altered.
+func (b *Broker) brokerConfigEntries() []configEntry {
+	entries := make([]configEntry, 0, len(config.Defs))
+	for _, def := range config.Defs {
+		synonyms := b.brokerSynonyms([]string{def.Name})
+		if def.Default != "" {
+			synonyms = append(synonyms, configSynonym{name: def.Name, value: def.Default, source: configSourceDefault})
+		}
+		e := configEntry{name: def.Name, source: configSourceDefault, readOnly: !config.Dynamic(def.Name), typ: def.Type, synonyms: synonyms}
+		if len(synonyms) > 0 {
+			e.value, e.source = &synonyms[0].value, synonyms[0].source
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// clusterConfigEntries lists the cluster-wide dynamic broker defaults.
+func (b *Broker) clusterConfigEntries() []configEntry {
+	values := b.Metadata.Configs(brokerResource(""))
+	entries := make([]configEntry, 0, len(values))
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		value := values[name]
+		def, _ := config.Lookup(name)
+		entries = append(entries, configEntry{
+			name:     name,
+			value:    &value,
+			source:   configSourceDynamicDefaultBroker,
+			typ:      def.Type,
+			synonyms: []configSynonym{{name: name, value: value, source: configSourceDynamicDefaultBroker}},
+		})
+	}
+	return entries
+}
+
+// topicConfigCache keeps the parsed configs of every topic. Entries are
+// dropped whenever a config layer changes; generation keeps a resolution
+// that raced with such a change from being cached.
+type topicConfigCache struct {
+	mu         sync.Mutex
+	generation uint64
+	values     map[string]map[string]any
+}
+
+// topicConfigValues returns the effective value of every config of topic,
+// which callers must not modify.
+func (b *Broker) topicConfigValues(topic string) map[string]any {
+	c := &b.topicConfigs
+	c.mu.Lock()
+	values, ok := c.values[topic]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok {
+		return values
+	}
+	values = b.resolveTopicConfigValues(topic)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		if c.values == nil {
+			c.values = make(map[string]map[string]any)
+		}
+		c.values[topic] = values
+	}
+	return values
+}
+
+// invalidateTopicConfigs drops the cached configs of topic, or of every
+// topic when topic is empty.
+func (b *Broker) invalidateTopicConfigs(topic string) {
+	c := &b.topicConfigs
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if topic == "" {
+		clear(c.values)
+	} else {
+		delete(c.values, topic)
+	}
+}
+
+// resolveTopicConfigValues parses the effective value of every config of
+// topic. A value that no longer parses falls back to the built-in default.
+func (b *Broker) resolveTopicConfigValues(topic string) map[string]any {
+	values := make(map[string]any, len(config.TopicDefs))
+	for _, e := range b.topicConfigEntries(b.Metadata.Configs(topicResource(topic))) {
+		def, _ := config.LookupTopic(e.name)
+		v, err := parseConfigValue(def.Def, *e.value)
+		if err != nil {
+			v, _ = config.ParseValue(def.Def, def.Default)
+		}
+		values[e.name] = v
+	}
+	return values
+}
+
+// topicConfig returns the topic config name from values, falling back to
+// its built-in default should the value be missing or of another type.
+func topicConfig[T any](values map[string]any, name string) T {
+	if v, ok := values[name].(T); ok {
+		return v
+	}
+	def, _ := config.LookupTopic(name)
+	v, _ := config.ParseValue(def.Def, def.Default)
+	t, _ := v.(T)
+	return t
+}
+
+// logConfig is the effective log settings of a topic.
+func (b *Broker) logConfig(topic string) log.Config {
+	v := b.topicConfigValues(topic)
+	policy := topicConfig[[]string](v, "cleanup.policy")
+	return log.Config{
+		SegmentBytes:           topicConfig[int32](v, "segment.bytes"),
+		SegmentMs:              topicConfig[int64](v, "segment.ms"),
+		IndexIntervalBytes:     topicConfig[int32](v, "index.interval.bytes"),
+		MaxIndexBytes:          topicConfig[int32](v, "segment.index.bytes"),
+		RetentionMs:            topicConfig[int64](v, "retention.ms"),
+		RetentionBytes:         topicConfig[int64](v, "retention.bytes"),
+		Delete:                 slices.Contains(policy, "delete"),
+		Compact:                slices.Contains(policy, "compact"),
+		DeleteRetentionMs:      topicConfig[int64](v, "delete.retention.ms"),
+		MinCleanableDirtyRatio: topicConfig[float64](v, "min.cleanable.dirty.ratio"),
+		MinCompactionLagMs:     topicConfig[int64](v, "min.compaction.lag.ms"),
+		MaxCompactionLagMs:     topicConfig[int64](v, "max.compaction.lag.ms"),
+		FileDeleteDelayMs:      topicConfig[int64](v, "file.delete.delay.ms"),
+	}
+}
+
+// configResource resolves the resource of a config request: an existing
+// topic, this broker by id or, with an empty name, the cluster-wide broker
+// defaults.
+func (b *Broker) configResource(resourceType int8, name string) (metadata.ConfigResource, error) {
+	switch resourceType {
+	case metadata.TopicResource:
+		if _, ok := b.Metadata.Topic(name); !ok {
+			return metadata.ConfigResource{}, fmt.Errorf("%w: %s", errUnknownTopic, name)
+		}
+		return topicResource(name), nil
+	case metadata.BrokerResource:
+		if name != "" && name != strconv.Itoa(int(b.Config.NodeID)) {
+			return metadata.ConfigResource{}, fmt.Errorf("%w: unexpected broker id %s", errInvalidRequest, name)
+		}
+		return brokerResource(name), nil
+	}
+	return metadata.ConfigResource{}, fmt.Errorf("%w: unsupported resource type %d", errInvalidRequest, resourceType)
+}
+
+// alterConfigs replaces the dynamic configs of a resource with what alter
+// makes of the current ones. Only the difference is written, as
+// ConfigRecords, and open logs pick the new settings up at once.
+func (b *Broker) alterConfigs(resource metadata.ConfigResource, alter func(current map[string]string) (map[string]string, error), validateOnly bool) error {
+	err := b.Metadata.Update(func() ([]metadata.Record, error) {
+		current := b.Metadata.Configs(resource)
+		desired, err := alter(maps.Clone(current))
+		if err != nil {
+			return nil, err
+		}
+		if resource.Type == metadata.TopicResource {
+			err = validateTopicConfigs(desired)
+		} else {
+			err = validateBrokerConfigs(desired)
+		}
+		if err != nil || validateOnly {
+			return nil, err
+		}
+		var records []metadata.Record
+		for _, name := range slices.Sorted(maps.Keys(desired)) {
+			if v, ok := current[name]; !ok || v != desired[name] {
+				value := desired[name]
+				records = append(records, metadata.ConfigRecord{ResourceType: resource.Type, ResourceName: resource.Name, Name: name, Value: &value})
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(current)) {
+			if _, ok := desired[name]; !ok {
+				records = append(records, metadata.ConfigRecord{ResourceType: resource.Type, ResourceName: resource.Name, Name: name})
+			}
+		}
+		return records, nil
+	})
+	if err != nil || validateOnly {
+		return err
+	}
+	if resource.Type == metadata.TopicResource {
+		b.invalidateTopicConfigs(resource.Name)
+		b.Logs.Reconfigure(resource.Name)
+	} else {
+		b.invalidateTopicConfigs("")
+		b.Logs.Reconfigure("")
+	}
+	return nil
+}
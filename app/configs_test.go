@@ -0,0 +1,206 @@
+package app
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"testing"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+func setConfig(t *testing.T, b *Broker, resource metadata.ConfigResource, name, value string) {
+	t.Helper()
+	err := b.Metadata.Update(func() ([]metadata.Record, error) {
+		return []metadata.Record{metadata.ConfigRecord{
+			ResourceType: resource.Type,
+			ResourceName: resource.Name,
+			Name:         name,
+			Value:        types.NullableStringOf(value),
+		}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	b.invalidateTopicConfigs("")
+}
+
+func topicConfigEntry(b *Broker, overrides map[string]string, name string) configEntry {
+	for _, e := range b.topicConfigEntries(overrides) {
+		if e.name == name {
+			return e
+		}
+	}
+	return configEntry{}
+}
+
+func TestTopicConfigEntries(t *testing.T) {
+	topic := configSynonym{"retention.ms", "4000", configSourceDynamicTopic}
+	dynamicBroker := configSynonym{"log.retention.ms", "3000", configSourceDynamicBroker}
+	dynamicDefault := configSynonym{"log.retention.ms", "2000", configSourceDynamicDefaultBroker}
+	static := configSynonym{"log.retention.minutes", "1", configSourceStaticBroker}
+	fallback := configSynonym{"log.retention.hours", "168", configSourceDefault}
+	tests := []struct {
+		name  string
+		props map[string]string
+		// layers set the dynamic broker and dynamic default configs.
+		dynamicBroker, dynamicDefault bool
+		overrides                     map[string]string
+		want                          string
+		synonyms                      []configSynonym
+	}{
+		{"default", nil, false, false, nil, "604800000", []configSynonym{fallback}},
+		{"static", map[string]string{"log.retention.minutes": "1"}, false, false, nil, "60000",
+			[]configSynonym{static, fallback}},
+		{"dynamic default", map[string]string{"log.retention.minutes": "1"}, false, true, nil, "2000",
+			[]configSynonym{dynamicDefault, static, fallback}},
+		{"dynamic broker", map[string]string{"log.retention.minutes": "1"}, true, true, nil, "3000",
+			[]configSynonym{dynamicBroker, dynamicDefault, static, fallback}},
+		{"topic", map[string]string{"log.retention.minutes": "1"}, true, true, map[string]string{"retention.ms": "4000"}, "4000",
+			[]configSynonym{topic, dynamicBroker, dynamicDefault, static, fallback}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := map[string]string{"node.id": "1"}
+			maps.Copy(props, tt.props)
+			b := newTestBroker(t, props)
+			if tt.dynamicBroker {
+				setConfig(t, b, brokerResource("1"), "log.retention.ms", "3000")
+			}
+			if tt.dynamicDefault {
+				setConfig(t, b, brokerResource(""), "log.retention.ms", "2000")
+			}
+			e := topicConfigEntry(b, tt.overrides, "retention.ms")
+			if e.value == nil || *e.value != tt.want || e.source != tt.synonyms[0].source {
+				t.Fatalf("retention.ms = %v from source %d, want %s from source %d", e.value, e.source, tt.want, tt.synonyms[0].source)
+			}
+			if !reflect.DeepEqual(e.synonyms, tt.synonyms) {
+				t.Fatalf("synonyms:\n got  %v\n want %v", e.synonyms, tt.synonyms)
+			}
+		})
+	}
+}
+
+func TestApplyConfigOperation(t *testing.T) {
+	value := types.NullableStringOf
+	tests := []struct {
+		name     string
+		resource metadata.ConfigResource
+		current  map[string]string
+		op       requests.IncrementalAlterableConfig
+		want     map[string]string
+		err      error
+	}{
+		{"append to the effective value", topicResource("t"), map[string]string{},
+			requests.IncrementalAlterableConfig{Name: "cleanup.policy", ConfigOperation: requests.ConfigOperationAppend, Value: value("compact")},
+			map[string]string{"cleanup.policy": "delete,compact"}, nil},
+		{"append present item", topicResource("t"), map[string]string{"cleanup.policy": "compact"},
+			requests.IncrementalAlterableConfig{Name: "cleanup.policy", ConfigOperation: requests.ConfigOperationAppend, Value: value("compact")},
+			map[string]string{"cleanup.policy": "compact"}, nil},
+		{"subtract", topicResource("t"), map[string]string{"cleanup.policy": "delete,compact"},
+			requests.IncrementalAlterableConfig{Name: "cleanup.policy", ConfigOperation: requests.ConfigOperationSubtract, Value: value("delete")},
+			map[string]string{"cleanup.policy": "compact"}, nil},
+		{"subtract from the broker default", brokerResource(""), map[string]string{},
+			requests.IncrementalAlterableConfig{Name: "log.cleanup.policy", ConfigOperation: requests.ConfigOperationSubtract, Value: value("delete")},
+			map[string]string{"log.cleanup.policy": ""}, nil},
+		{"append to a topic non-list", topicResource("t"), map[string]string{},
+			requests.IncrementalAlterableConfig{Name: "retention.ms", ConfigOperation: requests.ConfigOperationAppend, Value: value("1")},
+			nil, errInvalidConfig},
+		{"subtract from a broker non-list", brokerResource(""), map[string]string{},
+			requests.IncrementalAlterableConfig{Name: "log.retention.ms", ConfigOperation: requests.ConfigOperationSubtract, Value: value("1")},
+			nil, errInvalidConfig},
+		{"unknown config", topicResource("t"), map[string]string{},
+			requests.IncrementalAlterableConfig{Name: "no.such.config", ConfigOperation: requests.ConfigOperationAppend, Value: value("1")},
+			nil, errInvalidConfig},
+		{"null value", topicResource("t"), map[string]string{},
+			requests.IncrementalAlterableConfig{Name: "cleanup.policy", ConfigOperation: requests.ConfigOperationAppend},
+			nil, errInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBroker(t, nil)
+			err := b.applyConfigOperation(tt.resource, tt.current, tt.op)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyConfigOperation: %v", err)
+			}
+			if !maps.Equal(tt.current, tt.want) {
+				t.Fatalf("got %v, want %v", tt.current, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlterConfigs(t *testing.T) {
+	b := newTestBroker(t, map[string]string{"node.id": "1"})
+	if _, err := b.createTopic("t", 1, 1); err != nil {
+		t.Fatalf("createTopic: %v", err)
+	}
+	l, ok := b.Logs.Get(log.TopicPartition{Topic: "t", Partition: 0})
+	if !ok {
+		t.Fatal("no log for the new topic")
+	}
+	// Resolve the configs once so that the cache must be dropped.
+	b.topicConfigValues("t")
+
+	alter := func(f func(current map[string]string)) int64 {
+		t.Helper()
+		before := b.Metadata.Offset()
+		err := b.alterConfigs(topicResource("t"), func(current map[string]string) (map[string]string, error) {
+			f(current)
+			return current, nil
+		}, false)
+		if err != nil {
+			t.Fatalf("alterConfigs: %v", err)
+		}
+		return b.Metadata.Offset() - before
+	}
+	if n := alter(func(c map[string]string) { c["retention.ms"] = "1000"; c["segment.ms"] = "10" }); n != 2 {
+		t.Fatalf("wrote %d records for two new configs", n)
+	}
+	if got := l.Config().RetentionMs; got != 1000 {
+		t.Fatalf("log retention %d, want the new 1000", got)
+	}
+	if n := alter(func(c map[string]string) { c["retention.ms"] = "1000"; c["segment.ms"] = "20" }); n != 1 {
+		t.Fatalf("wrote %d records for one changed config", n)
+	}
+	if n := alter(func(c map[string]string) { delete(c, "segment.ms") }); n != 1 {
+		t.Fatalf("wrote %d records for one deleted config", n)
+	}
+	if n := alter(func(c map[string]string) {}); n != 0 {
+		t.Fatalf("wrote %d records without a change", n)
+	}
+	want := map[string]string{"retention.ms": "1000"}
+	if got := b.Metadata.Configs(topicResource("t")); !maps.Equal(got, want) {
+		t.Fatalf("configs %v, want %v", got, want)
+	}
+	if got := topicConfig[int64](b.topicConfigValues("t"), "segment.ms"); got != 604800000 {
+		t.Fatalf("segment.ms %d after deleting the override, want the default", got)
+	}
+
+	err := b.alterConfigs(topicResource("t"), func(current map[string]string) (map[string]string, error) {
+		current["retention.ms"] = "soon"
+		return current, nil
+	}, false)
+	if !errors.Is(err, errInvalidConfig) {
+		t.Fatalf("invalid value: got %v, want errInvalidConfig", err)
+	}
+}
+
+func TestTopicConfigFallsBack(t *testing.T) {
+	values := map[string]any{"segment.bytes": "not an int32"}
+	if got := topicConfig[int32](values, "segment.bytes"); got != 1073741824 {
+		t.Fatalf("segment.bytes of the wrong type = %d, want the default", got)
+	}
+	if got := topicConfig[[]string](values, "cleanup.policy"); !reflect.DeepEqual(got, []string{"delete"}) {
+		t.Fatalf("missing cleanup.policy = %v, want the default", got)
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type CreatePartitionsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewCreatePartitionsHandler(broker *Broker) *CreatePartitionsHandler {
+	return &CreatePartitionsHandler{FlexibleSince: 2, broker: broker}
+}
+
+func (h *CreatePartitionsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseCreatePartitions(r, version)
+}
+
+// Handle grows each topic independently; a topic listed more than once is
+// rejected for every occurrence.
+func (h *CreatePartitionsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.CreatePartitions)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	counts := make(map[string]int, len(rb.Topics))
+	for _, t := range rb.Topics {
+		counts[t.Name]++
+	}
+	resp := &responses.CreatePartitions{Version: rb.Version, Results: []responses.CreatePartitionsTopicResult{}}
+	for _, t := range rb.Topics {
+		result := responses.CreatePartitionsTopicResult{Name: t.Name, ErrorCode: NONE}
+		var err error
+		if counts[t.Name] > 1 {
+			result.ErrorCode = INVALID_REQUEST
+			err = fmt.Errorf("topic %s is listed more than once", t.Name)
+		} else if err = h.broker.createPartitions(t.Name, t.Count, assignmentBrokers(t.Assignments), rb.ValidateOnly); err != nil {
+			result.ErrorCode = topicErrorCode(err)
+		}
+		if err != nil {
+			message := err.Error()
+			result.ErrorMessage = &message
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// assignmentBrokers keeps nil, meaning no manual assignment, apart from
+// an empty list of assignments.
+func assignmentBrokers(assignments []requests.CreatePartitionsAssignment) [][]int32 {
+	if assignments == nil {
+		return nil
+	}
+	brokers := make([][]int32, len(assignments))
+	for i, a := range assignments {
+		brokers[i] = a.BrokerIDs
+	}
+	return brokers
+}
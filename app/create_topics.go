@@ -0,0 +1,121 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type CreateTopicsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewCreateTopicsHandler(broker *Broker) *CreateTopicsHandler {
+	return &CreateTopicsHandler{FlexibleSince: 5, broker: broker}
+}
+
+func (h *CreateTopicsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseCreateTopics(r, version)
+}
+
+// Handle creates each topic independently. A name appearing more than
+// once is rejected for every occurrence, as upstream does.
+func (h *CreateTopicsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.CreateTopics)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	counts := make(map[string]int, len(rb.Topics))
+	for _, t := range rb.Topics {
+		counts[t.Name]++
+	}
+	resp := &responses.CreateTopics{Version: rb.Version, Topics: []responses.CreatableTopicResult{}}
+	for _, t := range rb.Topics {
+		if counts[t.Name] > 1 {
+			message := fmt.Sprintf("topic %s is listed more than once", t.Name)
+			resp.Topics = append(resp.Topics, failedCreatableTopic(t.Name, INVALID_REQUEST, message))
+			continue
+		}
+		resp.Topics = append(resp.Topics, h.broker.createRequestedTopic(t, rb.ValidateOnly))
+	}
+	return resp, nil
+}
+
+func failedCreatableTopic(name string, errorCode int16, message string) responses.CreatableTopicResult {
+	return responses.CreatableTopicResult{
+		Name:              name,
+		ErrorCode:         errorCode,
+		ErrorMessage:      &message,
+		NumPartitions:     -1,
+		ReplicationFactor: -1,
+	}
+}
+
+// createRequestedTopic resolves the replica assignment of a topic, either
+// given explicitly or from num_partitions and replication_factor with -1
+// standing for the broker defaults, and creates it.
+func (b *Broker) createRequestedTopic(t requests.CreatableTopic, validateOnly bool) responses.CreatableTopicResult {
+	var assignments [][]int32
+	if len(t.Assignments) > 0 {
+		if t.NumPartitions != -1 || t.ReplicationFactor != -1 {
+			return failedCreatableTopic(t.Name, INVALID_REQUEST,
+				"num_partitions and replication_factor must be -1 when replica assignments are given")
+		}
+		assignments = make([][]int32, len(t.Assignments))
+		for _, a := range t.Assignments {
+			if a.PartitionIndex < 0 || int(a.PartitionIndex) >= len(assignments) || assignments[a.PartitionIndex] != nil {
+				return failedCreatableTopic(t.Name, INVALID_REPLICA_ASSIGNMENT,
+					"replica assignments must cover partitions 0 to n-1 exactly once")
+			}
+			assignments[a.PartitionIndex] = a.BrokerIDs
+			if a.BrokerIDs == nil {
+				assignments[a.PartitionIndex] = []int32{}
+			}
+		}
+	} else {
+		partitions, replicationFactor := t.NumPartitions, t.ReplicationFactor
+		if partitions == -1 {
+			partitions = b.Config.NumPartitions
+		}
+		if replicationFactor == -1 {
+			replicationFactor = b.Config.DefaultReplicationFactor
+		}
+		var err error
+		if assignments, err = b.assignReplicas(partitions, replicationFactor); err != nil {
+			return failedCreatableTopic(t.Name, topicErrorCode(err), err.Error())
+		}
+	}
+
+	configs := make(map[string]string, len(t.Configs))
+	for _, c := range t.Configs {
+		if c.Value == nil {
+			return failedCreatableTopic(t.Name, INVALID_CONFIG, fmt.Sprintf("null value is not supported for topic config %s", c.Name))
+		}
+		configs[c.Name] = *c.Value
+	}
+
+	topic, err := b.writeTopic(t.Name, assignments, configs, validateOnly)
+	if err != nil {
+		return failedCreatableTopic(t.Name, topicErrorCode(err), err.Error())
+	}
+	result := responses.CreatableTopicResult{
+		Name:              t.Name,
+		TopicID:           topic.ID,
+		ErrorCode:         NONE,
+		NumPartitions:     int32(len(assignments)),
+		ReplicationFactor: int16(len(assignments[0])),
+		Configs:           []responses.CreatableTopicConfigs{},
+	}
+	for _, c := range b.topicConfigEntries(configs) {
+		result.Configs = append(result.Configs, responses.CreatableTopicConfigs{
+			Name:         c.name,
+			Value:        c.value,
+			ConfigSource: c.source,
+		})
+	}
+	return result
+}
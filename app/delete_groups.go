@@ -0,0 +1,38 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type DeleteGroupsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewDeleteGroupsHandler(broker *Broker) *DeleteGroupsHandler {
+	return &DeleteGroupsHandler{FlexibleSince: 2, broker: broker}
+}
+
+func (h *DeleteGroupsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseDeleteGroups(r, version)
+}
+
+// Handle deletes each requested group with its committed offsets. Groups
+// that still have members are refused with NON_EMPTY_GROUP.
+func (h *DeleteGroupsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.DeleteGroups)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.DeleteGroups{Version: rb.Version, Results: []responses.DeletableGroupResult{}}
+	for _, id := range rb.GroupsNames {
+		err := h.broker.Groups.Delete(id)
+		resp.Results = append(resp.Results, responses.DeletableGroupResult{GroupID: id, ErrorCode: groupErrorCode(err)})
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type DeleteRecordsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewDeleteRecordsHandler(broker *Broker) *DeleteRecordsHandler {
+	return &DeleteRecordsHandler{FlexibleSince: 2, broker: broker}
+}
+
+func (h *DeleteRecordsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseDeleteRecords(r, version)
+}
+
+// Handle moves the log start offset of every partition forward. The new
+// start offsets are checkpointed once for the whole request before it is
+// answered, so deleted records never come back after a restart.
+func (h *DeleteRecordsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.DeleteRecords)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.DeleteRecords{Version: rb.Version, Topics: []responses.DeleteRecordsTopicResult{}}
+	for _, topic := range rb.Topics {
+		rt := responses.DeleteRecordsTopicResult{Name: topic.Name, Partitions: []responses.DeleteRecordsPartitionResult{}}
+		for _, p := range topic.Partitions {
+			rt.Partitions = append(rt.Partitions, h.broker.deleteRecords(topic.Name, p))
+		}
+		resp.Topics = append(resp.Topics, rt)
+	}
+	if err := h.broker.Logs.CheckpointLogStartOffsets(); err != nil {
+		for i := range resp.Topics {
+			for j := range resp.Topics[i].Partitions {
+				resp.Topics[i].Partitions[j].ErrorCode = KAFKA_STORAGE_ERROR
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (b *Broker) deleteRecords(topic string, p requests.DeleteRecordsPartition) responses.DeleteRecordsPartitionResult {
+	rp := responses.DeleteRecordsPartitionResult{PartitionIndex: p.PartitionIndex, LowWatermark: -1}
+	l, ok, err := b.partitionLog(topic, p.PartitionIndex)
+	if err != nil {
+		rp.ErrorCode = KAFKA_STORAGE_ERROR
+		return rp
+	}
+	if !ok {
+		rp.ErrorCode = UNKNOWN_TOPIC_OR_PARTITION
+		return rp
+	}
+	lowWatermark, err := l.DeleteRecordsBefore(p.Offset)
+	switch {
+	case errors.Is(err, log.ErrOffsetOutOfRange):
+		rp.ErrorCode = OFFSET_OUT_OF_RANGE
+	case err != nil:
+		rp.ErrorCode = KAFKA_STORAGE_ERROR
+	default:
+		rp.ErrorCode, rp.LowWatermark = NONE, lowWatermark
+	}
+	return rp
+}
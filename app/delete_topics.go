@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+type DeleteTopicsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewDeleteTopicsHandler(broker *Broker) *DeleteTopicsHandler {
+	return &DeleteTopicsHandler{FlexibleSince: 4, broker: broker}
+}
+
+func (h *DeleteTopicsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseDeleteTopics(r, version)
+}
+
+// Handle resolves every topic first so that one named twice, possibly
+// once by name and once by id, is rejected rather than deleted.
+func (h *DeleteTopicsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.DeleteTopics)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	states := rb.States()
+	results := make([]responses.DeletableTopicResult, len(states))
+	counts := make(map[types.UUID]int, len(states))
+	for i, s := range states {
+		results[i] = h.broker.resolveDeletableTopic(s)
+		if results[i].ErrorCode == NONE {
+			counts[results[i].TopicID]++
+		}
+	}
+	for i := range results {
+		r := &results[i]
+		if r.ErrorCode != NONE {
+			continue
+		}
+		if counts[r.TopicID] > 1 {
+			r.ErrorCode = INVALID_REQUEST
+			r.ErrorMessage = types.NullableStringOf("the topic is listed more than once")
+			continue
+		}
+		if err := h.broker.deleteTopic(r.TopicID); err != nil {
+			r.ErrorCode = topicErrorCode(err)
+			r.ErrorMessage = types.NullableStringOf(err.Error())
+		}
+	}
+	return &responses.DeleteTopics{Version: rb.Version, Responses: results}, nil
+}
+
+// resolveDeletableTopic looks a topic up by whichever of name and id the
+// request gave.
+func (b *Broker) resolveDeletableTopic(s requests.DeleteTopicState) responses.DeletableTopicResult {
+	r := responses.DeletableTopicResult{Name: s.Name, TopicID: s.TopicID}
+	fail := func(code int16, message string) responses.DeletableTopicResult {
+		r.ErrorCode, r.ErrorMessage = code, &message
+		return r
+	}
+	switch {
+	case s.Name != nil && !s.TopicID.IsZero():
+		return fail(INVALID_REQUEST, "only one of name and topic id may be given")
+	case s.Name != nil:
+		t, ok := b.Metadata.Topic(*s.Name)
+		if !ok {
+			return fail(UNKNOWN_TOPIC_OR_PARTITION, "this server does not host this topic")
+		}
+		r.TopicID = t.ID
+	case !s.TopicID.IsZero():
+		t, ok := b.Metadata.TopicByID(s.TopicID)
+		if !ok {
+			return fail(UNKNOWN_TOPIC_ID, "this server does not host this topic id")
+		}
+		r.Name = &t.Name
+	default:
+		return fail(INVALID_REQUEST, "neither name nor topic id is given")
+	}
+	return r
+}
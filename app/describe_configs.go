@@ -0,0 +1,86 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type DescribeConfigsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewDescribeConfigsHandler(broker *Broker) *DescribeConfigsHandler {
+	return &DescribeConfigsHandler{FlexibleSince: 4, broker: broker}
+}
+
+func (h *DescribeConfigsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseDescribeConfigs(r, version)
+}
+
+// Handle describes topics, this broker or, under the empty broker name,
+// the cluster-wide dynamic broker defaults. Keys that are not configs of
+// the resource are left out rather than reported as errors.
+func (h *DescribeConfigsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.DescribeConfigs)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.DescribeConfigs{Version: rb.Version, Results: []responses.DescribeConfigsResult{}}
+	for _, res := range rb.Resources {
+		result := responses.DescribeConfigsResult{
+			ResourceType: res.ResourceType,
+			ResourceName: res.ResourceName,
+			Configs:      []responses.DescribeConfigsResourceResult{},
+		}
+		entries, err := h.broker.describeConfigs(res.ResourceType, res.ResourceName)
+		if err != nil {
+			message := err.Error()
+			result.ErrorCode, result.ErrorMessage = topicErrorCode(err), &message
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+		for _, e := range entries {
+			if res.ConfigurationKeys != nil && !slices.Contains(res.ConfigurationKeys, e.name) {
+				continue
+			}
+			c := responses.DescribeConfigsResourceResult{
+				Name:         e.name,
+				Value:        e.value,
+				ReadOnly:     e.readOnly,
+				IsDefault:    e.source == configSourceDefault,
+				ConfigSource: e.source,
+				Synonyms:     []responses.DescribeConfigsSynonym{},
+				ConfigType:   configType(e.typ),
+			}
+			if rb.IncludeSynonyms {
+				for _, s := range e.synonyms {
+					value := s.value
+					c.Synonyms = append(c.Synonyms, responses.DescribeConfigsSynonym{Name: s.name, Value: &value, Source: s.source})
+				}
+			}
+			result.Configs = append(result.Configs, c)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func (b *Broker) describeConfigs(resourceType int8, name string) ([]configEntry, error) {
+	resource, err := b.configResource(resourceType, name)
+	switch {
+	case err != nil:
+		return nil, err
+	case resource.Type == metadata.TopicResource:
+		return b.topicConfigEntries(b.Metadata.Configs(resource)), nil
+	case resource.Name == "":
+		return b.clusterConfigEntries(), nil
+	}
+	return b.brokerConfigEntries(), nil
+}
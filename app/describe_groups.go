@@ -0,0 +1,70 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+// groupAuthorizedOperations is every group operation (READ, DELETE and
+// DESCRIBE): without ACLs the client is allowed everything.
+const groupAuthorizedOperations int32 = 0x0148
+
+type DescribeGroupsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewDescribeGroupsHandler(broker *Broker) *DescribeGroupsHandler {
+	return &DescribeGroupsHandler{FlexibleSince: 5, broker: broker}
+}
+
+func (h *DescribeGroupsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseDescribeGroups(r, version)
+}
+
+// Handle describes each requested group. Unknown groups are reported in
+// state Dead without an error, as upstream does; member metadata and
+// assignments are only known while a group is stable.
+func (h *DescribeGroupsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.DescribeGroups)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	operations := authorizedOperationsOmitted
+	if rb.IncludeAuthorizedOperations {
+		operations = groupAuthorizedOperations
+	}
+	resp := &responses.DescribeGroups{Version: rb.Version, Groups: []responses.DescribedGroup{}}
+	for _, id := range rb.Groups {
+		rg := responses.DescribedGroup{
+			GroupID:              id,
+			Members:              []responses.DescribedGroupMember{},
+			AuthorizedOperations: operations,
+		}
+		d, err := h.broker.Groups.Describe(id)
+		if err != nil {
+			rg.ErrorCode = groupErrorCode(err)
+			resp.Groups = append(resp.Groups, rg)
+			continue
+		}
+		rg.GroupState = d.State.String()
+		rg.ProtocolType = d.ProtocolType
+		rg.ProtocolData = d.Protocol
+		for _, m := range d.Members {
+			rg.Members = append(rg.Members, responses.DescribedGroupMember{
+				MemberID:         m.ID,
+				GroupInstanceID:  m.InstanceID,
+				ClientID:         m.ClientID,
+				ClientHost:       m.ClientHost,
+				MemberMetadata:   m.Metadata,
+				MemberAssignment: m.Assignment,
+			})
+		}
+		resp.Groups = append(resp.Groups, rg)
+	}
+	return resp, nil
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// topicAuthorizedOperations is every topic operation: without ACLs the
+// client is allowed everything.
+const topicAuthorizedOperations int32 = 0x0df8
+
+type DescribeTopicPartitionsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewDescribeTopicPartitionsHandler(broker *Broker) *DescribeTopicPartitionsHandler {
+	return &DescribeTopicPartitionsHandler{FlexibleSince: 0, broker: broker}
+}
+
+func (h *DescribeTopicPartitionsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseDescribeTopicPartitionsV0(r)
+}
+
+// Handle describes the requested topics, or every topic when none is
+// named, in name order. At most ResponsePartitionLimit partitions are
+// returned; NextCursor then points at the first partition left out.
+func (h *DescribeTopicPartitionsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.DescribeTopicPartitionsV0)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	if rb.Invalid != "" {
+		return invalidDescribeTopicPartitions(rb), nil
+	}
+	limit := h.broker.Config.MaxRequestPartitionSize
+	if rb.ResponsePartitionLimit > 0 && rb.ResponsePartitionLimit < limit {
+		limit = rb.ResponsePartitionLimit
+	}
+	var names []string
+	for _, topic := range rb.Topics {
+		names = append(names, string(topic.Name))
+	}
+	if len(names) == 0 {
+		for _, t := range h.broker.Metadata.Topics() {
+			names = append(names, t.Name)
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	resp := &responses.DescribeTopicPartitionsV0{Topics: []responses.Topic{}}
+	for _, name := range names {
+		first := int32(0)
+		if rb.Cursor != nil {
+			if name < string(rb.Cursor.TopicName) {
+				continue
+			}
+			if name == string(rb.Cursor.TopicName) {
+				first = rb.Cursor.PartitionIndex
+			}
+		}
+		t, ok := h.broker.Metadata.Topic(name)
+		if !ok {
+			resp.Topics = append(resp.Topics, responses.Topic{
+				ErrorCode:                 UNKNOWN_TOPIC_OR_PARTITION,
+				TopicName:                 types.CompactString(name),
+				TopicAuthorizedOperations: topicAuthorizedOperations,
+			})
+			continue
+		}
+		partitions := t.Partitions
+		for len(partitions) > 0 && partitions[0].Index < first {
+			partitions = partitions[1:]
+		}
+		if limit == 0 {
+			resp.NextCursor = &responses.Cursor{TopicName: types.CompactString(name), PartitionIndex: first}
+			break
+		}
+		if len(partitions) > int(limit) {
+			resp.NextCursor = &responses.Cursor{TopicName: types.CompactString(name), PartitionIndex: partitions[limit].Index}
+			partitions = partitions[:limit]
+		}
+		limit -= int32(len(partitions))
+		resp.Topics = append(resp.Topics, describeTopic(t, partitions))
+		if resp.NextCursor != nil {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// invalidDescribeTopicPartitions answers every requested topic with
+// INVALID_REQUEST. The response has no top-level error code, so a request
+// for all topics is answered with a single unnamed topic.
+func invalidDescribeTopicPartitions(rb *requests.DescribeTopicPartitionsV0) *responses.DescribeTopicPartitionsV0 {
+	resp := &responses.DescribeTopicPartitionsV0{Topics: []responses.Topic{}}
+	topics := rb.Topics
+	if len(topics) == 0 {
+		topics = []requests.Topic{{}}
+	}
+	for _, topic := range topics {
+		resp.Topics = append(resp.Topics, responses.Topic{
+			ErrorCode:                 INVALID_REQUEST,
+			TopicName:                 topic.Name,
+			TopicAuthorizedOperations: topicAuthorizedOperations,
+		})
+	}
+	return resp
+}
+
+func describeTopic(t metadata.Topic, partitions []metadata.Partition) responses.Topic {
+	rt := responses.Topic{
+		ErrorCode:                 NONE,
+		TopicName:                 types.CompactString(t.Name),
+		TopicID:                   t.ID,
+		TopicAuthorizedOperations: topicAuthorizedOperations,
+	}
+	if t.Internal() {
+		rt.IsInternal = 1
+	}
+	for _, p := range partitions {
+		rt.Partitions.Partitions = append(rt.Partitions.Partitions, responses.Partition{
+			ErrorCode:              NONE,
+			PartitionIndex:         p.Index,
+			LeaderID:               p.Leader,
+			LeaderEpoch:            p.LeaderEpoch,
+			ReplicaNodes:           nodes(p.Replicas),
+			ISRNodes:               nodes(p.ISR),
+			EligibleLeaderReplicas: nodes(p.EligibleLeaderReplicas),
+			LastKnownELRs:          nodes(p.LastKnownELR),
+			OfflineReplicas:        responses.Nodes{},
+		})
+	}
+	return rt
+}
+
+func nodes(ids []int32) responses.Nodes {
+	n := make(responses.Nodes, 0, len(ids))
+	for _, id := range ids {
+		n = append(n, responses.Node{NodeID: id})
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+// Feature names. metadata.version gates the metadata record versions and,
+// through them, the broker's behaviour; kraft.version gates the format of
+// the KRaft quorum state.
+const (
+	MetadataVersionFeature = "metadata.version"
+	KRaftVersionFeature    = "kraft.version"
+)
+
+// metadataVersionDirectoryAssignment is 3.7-IV2, from which partition
+// records carry the log directory of every replica.
+const metadataVersionDirectoryAssignment int16 = 17
+
+// FeatureRange is the span of levels of a feature this broker can run at.
+type FeatureRange struct {
+	Min int16
+	Max int16
+}
+
+// supportedFeatures lists what this broker implements. metadata.version
+// 21 is 3.9-IV0, the newest level whose records the image understands;
+// only the static quorum of kraft.version 0 is implemented.
+var supportedFeatures = map[string]FeatureRange{
+	MetadataVersionFeature: {Min: 1, Max: 21},
+	KRaftVersionFeature:    {Min: 0, Max: 0},
+}
+
+// featureLevel is the finalized level of a feature, 0 when unset.
+func (b *Broker) featureLevel(name string) int16 {
+	return b.Metadata.FeatureLevel(name)
+}
+
+func supportedFeatureList() []responses.SupportedFeature {
+	features := make([]responses.SupportedFeature, 0, len(supportedFeatures))
+	for name, r := range supportedFeatures {
+		features = append(features, responses.SupportedFeature{Name: name, MinVersion: r.Min, MaxVersion: r.Max})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return features
+}
+
+// finalizedFeatureList reports the levels finalized in the metadata log.
+// Like upstream, minimum and maximum level are the same.
+func (b *Broker) finalizedFeatureList() []responses.FinalizedFeature {
+	levels := b.Metadata.Features()
+	features := make([]responses.FinalizedFeature, 0, len(levels))
+	for name, level := range levels {
+		features = append(features, responses.FinalizedFeature{Name: name, MinVersionLevel: level, MaxVersionLevel: level})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return features
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/compression"
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+const readCommitted = 1
+
+type FetchHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewFetchHandler(broker *Broker) *FetchHandler {
+	return &FetchHandler{FlexibleSince: 12, broker: broker}
+}
+
+func (h *FetchHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseFetch(r, version)
+}
+
+// Parks reports whether req may wait for appends, which connections then
+// handle on their own goroutine.
+func (h *FetchHandler) Parks(req *Request) bool {
+	rb, ok := req.Body.(*requests.Fetch)
+	return ok && rb.MaxWaitMs > 0 && rb.MinBytes > 0
+}
+
+// Handle reads the requested partitions and, while fewer than min_bytes
+// are available, parks until one of them receives an append or max_wait_ms
+// elapses.
+func (h *FetchHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.Fetch)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	// Fetch sessions are not implemented; answering with session id 0
+	// tells clients to keep sending full fetch requests.
+	if rb.SessionID != 0 {
+		return &responses.Fetch{Version: rb.Version, ErrorCode: FETCH_SESSION_ID_NOT_FOUND}, nil
+	}
+	if rb.SessionEpoch > 0 {
+		return &responses.Fetch{Version: rb.Version, ErrorCode: INVALID_FETCH_SESSION_EPOCH}, nil
+	}
+
+	deadline := time.Now().Add(time.Duration(rb.MaxWaitMs) * time.Millisecond)
+	for {
+		resp, result := h.broker.fetch(rb)
+		if result.bytes >= int(rb.MinBytes) || result.failed || len(result.appended) == 0 {
+			return resp, nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return resp, nil
+		}
+		if !waitForAppend(ctx, result.appended, remaining) {
+			return resp, nil
+		}
+	}
+}
+
+// waitForAppend blocks until one of appended fires, returning false if the
+// timeout or the context ended the wait instead.
+func waitForAppend(ctx context.Context, appended []<-chan struct{}, timeout time.Duration) bool {
+	wake := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	for _, ch := range appended {
+		go func() {
+			select {
+			case <-ch:
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			case <-stop:
+			}
+		}()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-wake:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type fetchResult struct {
+	bytes    int
+	failed   bool
+	appended []<-chan struct{}
+}
+
+// fetch performs a single non-blocking read of every requested partition.
+func (b *Broker) fetch(rb *requests.Fetch) (*responses.Fetch, fetchResult) {
+	var result fetchResult
+	resp := &responses.Fetch{Version: rb.Version}
+	remaining := int(rb.MaxBytes)
+	for _, topic := range rb.Topics {
+		name := topic.Topic
+		topicErr := NONE
+		if rb.Version >= 13 {
+			var ok bool
+			if name, ok = b.topicName(topic.TopicID); !ok {
+				topicErr = UNKNOWN_TOPIC_ID
+			}
+		}
+		rt := responses.FetchTopic{Topic: topic.Topic, TopicID: topic.TopicID}
+		for _, p := range topic.Partitions {
+			rp := responses.FetchPartition{
+				PartitionIndex:       p.Partition,
+				HighWatermark:        -1,
+				LastStableOffset:     -1,
+				LogStartOffset:       -1,
+				PreferredReadReplica: -1,
+				Records:              []byte{},
+			}
+			l, ok, err := b.partitionLog(name, p.Partition)
+			switch {
+			case topicErr != NONE:
+				rp.ErrorCode = topicErr
+			case err != nil:
+				rp.ErrorCode = KAFKA_STORAGE_ERROR
+			case !ok:
+				rp.ErrorCode = UNKNOWN_TOPIC_OR_PARTITION
+			default:
+				result.appended = append(result.appended, l.Appended())
+				limit := min(int(p.PartitionMaxBytes), remaining)
+				rp.ErrorCode = readPartition(l, &rp, p.FetchOffset, limit, result.bytes == 0)
+				if rp.ErrorCode == NONE && rb.Version < 10 && containsZstd(rp.Records) {
+					// zstd arrived with Fetch v10; older clients cannot read it.
+					rp.ErrorCode = UNSUPPORTED_COMPRESSION_TYPE
+					rp.Records = []byte{}
+				}
+				if rb.IsolationLevel == readCommitted {
+					rp.AbortedTransactions = []responses.FetchAbortedTransaction{}
+				}
+				result.bytes += len(rp.Records)
+				remaining -= len(rp.Records)
+			}
+			if rp.ErrorCode != NONE {
+				result.failed = true
+			}
+			rt.Partitions = append(rt.Partitions, rp)
+		}
+		resp.Responses = append(resp.Responses, rt)
+	}
+	return resp, result
+}
+
+func readPartition(l *log.Log, rp *responses.FetchPartition, offset int64, maxBytes int, minOneBatch bool) int16 {
+	// The high watermark is read first: everything below it is already
+	// on disk by the time the read below runs.
+	rp.HighWatermark = l.HighWatermark()
+	rp.LastStableOffset = rp.HighWatermark
+	rp.LogStartOffset = l.LogStartOffset()
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+	records, err := l.Read(offset, maxBytes, minOneBatch)
+	if err != nil {
+		if errors.Is(err, log.ErrOffsetOutOfRange) {
+			return OFFSET_OUT_OF_RANGE
+		}
+		return KAFKA_STORAGE_ERROR
+	}
+	if records != nil {
+		rp.Records = records
+	}
+	return NONE
+}
+
+// containsZstd reports whether any batch of records is zstd compressed.
+func containsZstd(records []byte) bool {
+	batches, err := log.SplitBatches(records)
+	if err != nil {
+		return false
+	}
+	for _, batch := range batches {
+		h, err := log.ParseBatchHeader(batch)
+		if err == nil && compression.Codec(h.Attributes&types.CompressionCodecMask) == compression.Zstd {
+			return true
+		}
+	}
+	return false
+}
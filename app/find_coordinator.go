@@ -0,0 +1,73 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+// Coordinator key types of FindCoordinator.
+const (
+	coordinatorKeyGroup       int8 = 0
+	coordinatorKeyTransaction int8 = 1
+)
+
+type FindCoordinatorHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewFindCoordinatorHandler(broker *Broker) *FindCoordinatorHandler {
+	return &FindCoordinatorHandler{FlexibleSince: 3, broker: broker}
+}
+
+func (h *FindCoordinatorHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseFindCoordinator(r, version)
+}
+
+// Handle points every group at this broker, reachable through the
+// listener the request arrived on. Transaction coordinators are not
+// available.
+func (h *FindCoordinatorHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.FindCoordinator)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.FindCoordinator{Version: rb.Version, Coordinators: []responses.Coordinator{}}
+	for _, key := range rb.Keys() {
+		resp.Coordinators = append(resp.Coordinators, h.broker.findCoordinator(req.Listener, rb.KeyType, key))
+	}
+	if rb.Version < 4 {
+		c := resp.Coordinators[0]
+		resp.ErrorCode, resp.ErrorMessage = c.ErrorCode, c.ErrorMessage
+		resp.NodeID, resp.Host, resp.Port = c.NodeID, c.Host, c.Port
+	}
+	return resp, nil
+}
+
+func (b *Broker) findCoordinator(listener string, keyType int8, key string) responses.Coordinator {
+	c := responses.Coordinator{Key: key, NodeID: -1}
+	fail := func(code int16, message string) responses.Coordinator {
+		c.ErrorCode, c.ErrorMessage = code, &message
+		return c
+	}
+	switch keyType {
+	case coordinatorKeyGroup:
+	case coordinatorKeyTransaction:
+		return fail(COORDINATOR_NOT_AVAILABLE, "transactions are not supported")
+	default:
+		return fail(INVALID_REQUEST, fmt.Sprintf("unknown coordinator key type %d", keyType))
+	}
+	if key == "" {
+		return fail(INVALID_REQUEST, "group id is empty")
+	}
+	if err := b.ensureOffsetsTopic(); err != nil {
+		return fail(COORDINATOR_NOT_AVAILABLE, err.Error())
+	}
+	endpoint := b.advertisedEndpoint(listener)
+	c.NodeID, c.Host, c.Port = b.Config.NodeID, endpoint.Host, endpoint.Port
+	return c
+}
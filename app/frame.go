@@ -0,0 +1,55 @@
+package app
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultSocketRequestMaxBytes mirrors the upstream default for
+// socket.request.max.bytes (100 MiB).
+const DefaultSocketRequestMaxBytes int32 = 100 * 1024 * 1024
+
+var ErrFrameTooLarge = errors.New("frame exceeds socket.request.max.bytes")
+
+// FrameReader splits a byte stream into length-prefixed Kafka frames.
+// Every frame starts with an int32 message_size followed by exactly that
+// many bytes, so several pipelined requests can share a single read from
+// the socket without being mixed up.
+type FrameReader struct {
+	r        io.Reader
+	maxBytes int32
+}
+
+func NewFrameReader(r io.Reader, maxBytes int32) *FrameReader {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSocketRequestMaxBytes
+	}
+	return &FrameReader{r: r, maxBytes: maxBytes}
+}
+
+// ReadFrame returns the next complete frame including its message_size
+// prefix, which is the layout UnmarshallRequest expects.
+func (fr *FrameReader) ReadFrame() ([]byte, error) {
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(fr.r, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(sizeBuf[:]))
+	if size < 0 {
+		return nil, fmt.Errorf("invalid message size %d", size)
+	}
+	if size > fr.maxBytes {
+		return nil, fmt.Errorf("%w: %d > %d", ErrFrameTooLarge, size, fr.maxBytes)
+	}
+	frame := make([]byte, 4+int(size))
+	copy(frame, sizeBuf[:])
+	if _, err := io.ReadFull(fr.r, frame[4:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("cannot read frame body: %w", err)
+	}
+	return frame, nil
+}
@@ -0,0 +1,234 @@
+package group
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+var (
+	ErrGroupIDNotFound        = errors.New("group id not found")
+	ErrNonEmptyGroup          = errors.New("group is not empty")
+	ErrGroupSubscribedToTopic = errors.New("group is subscribed to topic")
+)
+
+// ClassicType is the type of every group this coordinator manages.
+const ClassicType = "classic"
+
+// consumerProtocolType is the protocol type of consumers, whose member
+// metadata lists the topics they subscribe to.
+const consumerProtocolType = "consumer"
+
+// Listing summarizes a group for ListGroups.
+type Listing struct {
+	ID           string
+	ProtocolType string
+	State        State
+}
+
+// List returns every group. Groups still being loaded, or whose partition
+// failed to load, are missing, which the error reports.
+func (c *Coordinator) List() ([]Listing, error) {
+	c.mu.Lock()
+	groups := make([]*Group, 0, len(c.groups))
+	for _, g := range c.groups {
+		groups = append(groups, g)
+	}
+	var err error
+	switch {
+	case len(c.loading) > 0:
+		err = ErrCoordinatorLoadInProgress
+	case len(c.failed) > 0:
+		err = ErrCoordinatorNotAvailable
+	}
+	c.mu.Unlock()
+
+	listings := make([]Listing, 0, len(groups))
+	for _, g := range groups {
+		g.mu.Lock()
+		l := Listing{ID: g.ID, State: g.state}
+		if g.protocolType != nil {
+			l.ProtocolType = *g.protocolType
+		}
+		g.mu.Unlock()
+		listings = append(listings, l)
+	}
+	slices.SortFunc(listings, func(a, b Listing) int { return strings.Compare(a.ID, b.ID) })
+	return listings, err
+}
+
+// Description describes a group for DescribeGroups.
+type Description struct {
+	State        State
+	ProtocolType string
+	// Protocol is the selected protocol, only set while the group is
+	// stable.
+	Protocol string
+	Members  []MemberDescription
+}
+
+// MemberDescription describes a member. Metadata and Assignment are only
+// set while the group is stable.
+type MemberDescription struct {
+	ID         string
+	InstanceID *string
+	ClientID   string
+	ClientHost string
+	Metadata   []byte
+	Assignment []byte
+}
+
+// Describe returns the state and members of a group. Unknown groups are
+// described as Dead, as upstream does.
+func (c *Coordinator) Describe(groupID string) (Description, error) {
+	g, err := c.group(groupID, false)
+	if err != nil {
+		return Description{}, err
+	}
+	if g == nil {
+		return Description{State: Dead}, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	d := Description{State: g.state, Members: []MemberDescription{}}
+	if g.protocolType != nil {
+		d.ProtocolType = *g.protocolType
+	}
+	stable := g.state == Stable
+	if stable {
+		d.Protocol = *g.protocol
+	}
+	for _, m := range g.sortedMembers() {
+		md := MemberDescription{
+			ID:         m.ID,
+			InstanceID: m.InstanceID,
+			ClientID:   m.ClientID,
+			ClientHost: m.ClientHost,
+			Metadata:   []byte{},
+			Assignment: []byte{},
+		}
+		if stable {
+			md.Metadata, md.Assignment = m.metadata(d.Protocol), m.Assignment
+		}
+		d.Members = append(d.Members, md)
+	}
+	return d, nil
+}
+
+// Delete removes an empty group together with its committed offsets.
+func (c *Coordinator) Delete(groupID string) error {
+	g, err := c.group(groupID, false)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return ErrGroupIDNotFound
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch g.state {
+	case Dead:
+		return ErrGroupIDNotFound
+	case Empty:
+	default:
+		return ErrNonEmptyGroup
+	}
+	records := []types.Record{{Key: groupMetadataKey(g.ID)}}
+	for tp := range g.offsets {
+		records = append(records, types.Record{Key: offsetCommitKey(g.ID, tp)})
+	}
+	if err := c.store(g.ID, records); err != nil {
+		return err
+	}
+	g.state = Dead
+	g.offsets = make(map[log.TopicPartition]Offset)
+	c.mu.Lock()
+	if c.groups[g.ID] == g {
+		delete(c.groups, g.ID)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// DeleteOffsets removes committed offsets of a group. While the group has
+// consumers, the offsets of topics they subscribe to are kept; groups
+// with members of another protocol type keep all of them.
+func (c *Coordinator) DeleteOffsets(groupID string, partitions []log.TopicPartition) (map[log.TopicPartition]error, error) {
+	g, err := c.group(groupID, false)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrGroupIDNotFound
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var subscribed func(topic string) bool
+	switch {
+	case g.state == Dead:
+		return nil, ErrGroupIDNotFound
+	case g.state == Empty:
+		subscribed = func(string) bool { return false }
+	case g.protocolType != nil && *g.protocolType == consumerProtocolType:
+		subscribed = g.subscribedTo()
+	default:
+		return nil, ErrNonEmptyGroup
+	}
+
+	refused := make(map[log.TopicPartition]error)
+	var records []types.Record
+	for _, tp := range partitions {
+		if subscribed(tp.Topic) {
+			refused[tp] = ErrGroupSubscribedToTopic
+			continue
+		}
+		if _, ok := g.offsets[tp]; ok {
+			records = append(records, types.Record{Key: offsetCommitKey(g.ID, tp)})
+		}
+	}
+	if len(records) > 0 {
+		if err := c.store(g.ID, records); err != nil {
+			return nil, err
+		}
+	}
+	for _, tp := range partitions {
+		if refused[tp] == nil {
+			delete(g.offsets, tp)
+		}
+	}
+	return refused, nil
+}
+
+// subscribedTo reports whether a consumer of the group subscribes to a
+// topic. Until a protocol is selected, or when a subscription cannot be
+// decoded, every topic counts as subscribed.
+func (g *Group) subscribedTo() func(topic string) bool {
+	all := func(string) bool { return true }
+	if g.protocol == nil {
+		return all
+	}
+	topics := make(map[string]bool)
+	for _, m := range g.members {
+		subscription, err := subscriptionTopics(m.metadata(*g.protocol))
+		if err != nil {
+			return all
+		}
+		for _, t := range subscription {
+			topics[t] = true
+		}
+	}
+	return func(topic string) bool { return topics[topic] }
+}
+
+// subscriptionTopics decodes the topics of a ConsumerProtocolSubscription,
+// which every version starts with.
+func subscriptionTopics(metadata []byte) ([]string, error) {
+	d := types.NewDecoder(bytes.NewReader(metadata), false)
+	d.Int16()
+	topics := types.DecodeArray(d, (*types.Decoder).String)
+	return topics, d.Err()
+}
@@ -0,0 +1,205 @@
+package group
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// subscription encodes a v0 ConsumerProtocolSubscription of topics.
+func subscription(topics ...string) []byte {
+	var buf bytes.Buffer
+	e := types.NewEncoder(&buf, false)
+	e.Int16(0)
+	types.EncodeArray(e, topics, (*types.Encoder).String)
+	e.Bytes(nil)
+	return buf.Bytes()
+}
+
+// stableMember brings a group of one member with metadata to Stable.
+func stableMember(t *testing.T, c *Coordinator, protocolType string, metadata []byte) JoinResult {
+	t.Helper()
+	req := joinRequest("", time.Minute)
+	req.ProtocolType = protocolType
+	req.Protocols = []Protocol{{Name: "range", Metadata: metadata}}
+	r := await(t, c.Join(req))
+	if r.Err != nil {
+		t.Fatalf("Join: %v", r.Err)
+	}
+	sync := c.Sync(SyncRequest{GroupID: "g", MemberID: r.MemberID, Generation: r.Generation, Assignments: map[string][]byte{r.MemberID: []byte("a")}})
+	if s := await(t, sync); s.Err != nil {
+		t.Fatalf("Sync: %v", s.Err)
+	}
+	return r
+}
+
+// commitOffsets commits offset 1 of tps with the sender of req.
+func commitOffsets(t *testing.T, c *Coordinator, req CommitRequest, tps ...log.TopicPartition) {
+	t.Helper()
+	req.Offsets = make(map[log.TopicPartition]Offset, len(tps))
+	for _, tp := range tps {
+		req.Offsets[tp] = Offset{Offset: 1, LeaderEpoch: -1}
+	}
+	if _, err := c.Commit(req); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+}
+
+// commitEmpty commits offsets for a group without members.
+func commitEmpty(t *testing.T, c *Coordinator, groupID string, tps ...log.TopicPartition) {
+	t.Helper()
+	commitOffsets(t, c, CommitRequest{GroupID: groupID, Generation: -1}, tps...)
+}
+
+func TestList(t *testing.T) {
+	c := newTestCoordinator(0)
+	stableMember(t, c, consumerProtocolType, subscription("t"))
+	tp := log.TopicPartition{Topic: "t", Partition: 0}
+	for _, id := range []string{"c", "a", "b"} {
+		commitEmpty(t, c, id, tp)
+	}
+	listings, err := c.List()
+	want := []Listing{
+		{ID: "a", State: Empty},
+		{ID: "b", State: Empty},
+		{ID: "c", State: Empty},
+		{ID: "g", ProtocolType: consumerProtocolType, State: Stable},
+	}
+	if err != nil || !reflect.DeepEqual(listings, want) {
+		t.Fatalf("List = %+v, %v; want %+v", listings, err, want)
+	}
+
+	c.StartLoading([]int32{0})
+	if _, err := c.List(); !errors.Is(err, ErrCoordinatorLoadInProgress) {
+		t.Fatalf("List while loading = %v, want ErrCoordinatorLoadInProgress", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	c := newTestCoordinator(0)
+	if d, err := c.Describe("unknown"); err != nil || d.State != Dead || len(d.Members) != 0 {
+		t.Fatalf("Describe of an unknown group = %+v, %v; want Dead", d, err)
+	}
+	a, b := stableGroup(t, c, time.Minute)
+	d, err := c.Describe("g")
+	if err != nil || d.State != Stable || d.ProtocolType != consumerProtocolType || d.Protocol != "range" || len(d.Members) != 2 {
+		t.Fatalf("Describe = %+v, %v", d, err)
+	}
+	assignments := map[string]string{a.MemberID: "a", b.MemberID: "b"}
+	for _, m := range d.Members {
+		if string(m.Assignment) != assignments[m.ID] || !bytes.Equal(m.Metadata, []byte{1}) || m.ClientID != "client" {
+			t.Fatalf("member %+v", m)
+		}
+	}
+
+	// A rebalance hides the protocol, metadata and assignments.
+	c.Join(joinRequest("", time.Minute))
+	d, err = c.Describe("g")
+	if err != nil || d.State != PreparingRebalance || d.Protocol != "" || len(d.Members[0].Assignment) != 0 {
+		t.Fatalf("Describe during a rebalance = %+v, %v", d, err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := newTestCoordinator(0)
+	written := recordStore(c)
+	if err := c.Delete("unknown"); !errors.Is(err, ErrGroupIDNotFound) {
+		t.Fatalf("Delete of an unknown group = %v, want ErrGroupIDNotFound", err)
+	}
+	stableGroup(t, c, time.Minute)
+	if err := c.Delete("g"); !errors.Is(err, ErrNonEmptyGroup) {
+		t.Fatalf("Delete of a group with members = %v, want ErrNonEmptyGroup", err)
+	}
+
+	tp0, tp1 := log.TopicPartition{Topic: "t", Partition: 0}, log.TopicPartition{Topic: "t", Partition: 1}
+	commitEmpty(t, c, "empty", tp0, tp1)
+	if err := c.Delete("empty"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	tombstones := (*written)[2:]
+	if len(tombstones) != 3 {
+		t.Fatalf("wrote %d records, want the group and two offset tombstones", len(tombstones))
+	}
+	for _, r := range tombstones {
+		if r.Value != nil {
+			t.Fatalf("record %x is not a tombstone", r.Key)
+		}
+	}
+	// Loading the partition back does not bring the group back either.
+	replayed := newTestCoordinator(0)
+	for _, r := range *written {
+		if err := replayed.Replay(r.Key, r.Value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g, _ := replayed.group("empty", false); g != nil {
+		t.Fatal("the deleted group was replayed")
+	}
+	if g, _ := c.group("empty", false); g != nil {
+		t.Fatal("the deleted group is still there")
+	}
+	if err := c.Delete("empty"); !errors.Is(err, ErrGroupIDNotFound) {
+		t.Fatalf("second Delete = %v, want ErrGroupIDNotFound", err)
+	}
+}
+
+func TestDeleteOffsets(t *testing.T) {
+	subscribed, other := log.TopicPartition{Topic: "t", Partition: 0}, log.TopicPartition{Topic: "other", Partition: 0}
+	tests := []struct {
+		name string
+		// setup leaves group "g" with offsets of both partitions.
+		setup   func(t *testing.T, c *Coordinator)
+		refused map[log.TopicPartition]error
+		err     error
+	}{
+		{"empty group", func(t *testing.T, c *Coordinator) {
+			commitEmpty(t, c, "g", subscribed, other)
+		}, map[log.TopicPartition]error{}, nil},
+		{"consumers", func(t *testing.T, c *Coordinator) {
+			r := stableMember(t, c, consumerProtocolType, subscription("t"))
+			commitOffsets(t, c, CommitRequest{GroupID: "g", MemberID: r.MemberID, Generation: r.Generation}, subscribed, other)
+		}, map[log.TopicPartition]error{subscribed: ErrGroupSubscribedToTopic}, nil},
+		{"undecodable subscription", func(t *testing.T, c *Coordinator) {
+			r := stableMember(t, c, consumerProtocolType, []byte{1})
+			commitOffsets(t, c, CommitRequest{GroupID: "g", MemberID: r.MemberID, Generation: r.Generation}, subscribed, other)
+		}, map[log.TopicPartition]error{subscribed: ErrGroupSubscribedToTopic, other: ErrGroupSubscribedToTopic}, nil},
+		{"other protocol type", func(t *testing.T, c *Coordinator) {
+			r := stableMember(t, c, "connect", nil)
+			commitOffsets(t, c, CommitRequest{GroupID: "g", MemberID: r.MemberID, Generation: r.Generation}, subscribed, other)
+		}, nil, ErrNonEmptyGroup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCoordinator(0)
+			tt.setup(t, c)
+			written := recordStore(c)
+			refused, err := c.DeleteOffsets("g", []log.TopicPartition{subscribed, other})
+			if !errors.Is(err, tt.err) || !reflect.DeepEqual(refused, tt.refused) {
+				t.Fatalf("DeleteOffsets = %v, %v; want %v, %v", refused, err, tt.refused, tt.err)
+			}
+			offsets, _ := c.Fetch("g", nil)
+			for _, tp := range []log.TopicPartition{subscribed, other} {
+				_, kept := offsets[tp]
+				if deleted := err == nil && tt.refused[tp] == nil; kept == deleted {
+					t.Fatalf("offset of %s kept %v, deleted %v", tp, kept, deleted)
+				}
+			}
+			if tombstones := 2 - len(offsets); len(*written) != tombstones {
+				t.Fatalf("wrote %d records, want %d tombstones", len(*written), tombstones)
+			}
+			for _, r := range *written {
+				if r.Value != nil {
+					t.Fatalf("record %x is not a tombstone", r.Key)
+				}
+			}
+		})
+	}
+	if _, err := newTestCoordinator(0).DeleteOffsets("unknown", []log.TopicPartition{other}); !errors.Is(err, ErrGroupIDNotFound) {
+		t.Fatalf("DeleteOffsets of an unknown group = %v, want ErrGroupIDNotFound", err)
+	}
+}
@@ -0,0 +1,488 @@
+package group
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+var (
+	ErrInvalidGroupID            = errors.New("invalid group id")
+	ErrInvalidSessionTimeout     = errors.New("session timeout out of range")
+	ErrInconsistentGroupProtocol = errors.New("inconsistent group protocol")
+	ErrUnknownMemberID           = errors.New("unknown member id")
+	ErrMemberIDRequired          = errors.New("member id required")
+	ErrFencedInstanceID          = errors.New("fenced instance id")
+	ErrIllegalGeneration         = errors.New("illegal generation")
+	ErrRebalanceInProgress       = errors.New("rebalance in progress")
+	ErrGroupMaxSizeReached       = errors.New("group max size reached")
+	ErrCoordinatorNotAvailable   = errors.New("coordinator not available")
+)
+
+// Config holds the broker settings the coordinator enforces.
+type Config struct {
+	MinSessionTimeout     time.Duration
+	MaxSessionTimeout     time.Duration
+	InitialRebalanceDelay time.Duration
+	MaxSize               int
+	// OffsetsPartitions is the partition count of __consumer_offsets.
+	OffsetsPartitions int32
+	MaxMetadataSize   int
+}
+
+// Coordinator tracks the classic groups this broker coordinates.
+type Coordinator struct {
+	config  Config
+	mu      sync.Mutex
+	groups  map[string]*Group
+	loading map[int32]bool
+	// failed holds the partitions of __consumer_offsets that could not be
+	// loaded; their groups stay unavailable.
+	failed        map[int32]bool
+	appendRecords AppendFunc
+}
+
+func NewCoordinator(config Config) *Coordinator {
+	return &Coordinator{
+		config:  config,
+		groups:  make(map[string]*Group),
+		loading: make(map[int32]bool),
+		failed:  make(map[int32]bool),
+	}
+}
+
+// group returns the group with the given id, creating it when asked to.
+// Groups whose partition of __consumer_offsets is still loading are not
+// available yet, and those of a partition that failed to load never are.
+func (c *Coordinator) group(id string, create bool) (*Group, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	partition := PartitionFor(id, c.config.OffsetsPartitions)
+	if c.loading[partition] {
+		return nil, ErrCoordinatorLoadInProgress
+	}
+	if c.failed[partition] {
+		return nil, ErrCoordinatorNotAvailable
+	}
+	g, ok := c.groups[id]
+	if !ok && create {
+		g = newGroup(c, id)
+		c.groups[id] = g
+	}
+	return g, nil
+}
+
+// JoinRequest is a JoinGroup request of one member. An empty MemberID
+// joins a new member.
+type JoinRequest struct {
+	GroupID          string
+	MemberID         string
+	InstanceID       *string
+	ClientID         string
+	ClientHost       string
+	SessionTimeout   time.Duration
+	RebalanceTimeout time.Duration
+	ProtocolType     string
+	Protocols        []Protocol
+	// RequireKnownMemberID makes new dynamic members rejoin with the id
+	// they are given, as JoinGroup v4+ does.
+	RequireKnownMemberID bool
+	// SkipAssignment tells a static leader rejoining a stable group can be
+	// answered without a rebalance, as JoinGroup v9+ does.
+	SkipAssignment bool
+}
+
+// JoinMember describes a member to the group leader.
+type JoinMember struct {
+	ID         string
+	InstanceID *string
+	Metadata   []byte
+}
+
+// JoinResult answers a JoinRequest. Only the leader gets Members.
+type JoinResult struct {
+	Err            error
+	Generation     int32
+	ProtocolType   *string
+	ProtocolName   *string
+	Leader         string
+	MemberID       string
+	SkipAssignment bool
+	Members        []JoinMember
+}
+
+func newMember(id string, req JoinRequest) *Member {
+	m := &Member{ID: id, InstanceID: req.InstanceID, ProtocolType: req.ProtocolType}
+	m.update(req)
+	return m
+}
+
+func (m *Member) update(req JoinRequest) {
+	m.ClientID = req.ClientID
+	m.ClientHost = req.ClientHost
+	m.SessionTimeout = req.SessionTimeout
+	m.RebalanceTimeout = req.RebalanceTimeout
+	m.Protocols = req.Protocols
+}
+
+// Join adds a member to a group or rejoins it. The result is delivered on
+// the returned channel once the rebalance the join takes part in
+// completes, which can take up to the rebalance timeout.
+func (c *Coordinator) Join(req JoinRequest) <-chan JoinResult {
+	result := make(chan JoinResult, 1)
+	fail := func(err error) <-chan JoinResult {
+		result <- JoinResult{Err: err, MemberID: req.MemberID, Generation: -1}
+		return result
+	}
+	switch {
+	case req.GroupID == "":
+		return fail(ErrInvalidGroupID)
+	case req.SessionTimeout < c.config.MinSessionTimeout || req.SessionTimeout > c.config.MaxSessionTimeout:
+		return fail(ErrInvalidSessionTimeout)
+	case req.ProtocolType == "" || len(req.Protocols) == 0:
+		return fail(ErrInconsistentGroupProtocol)
+	}
+	g, err := c.group(req.GroupID, req.MemberID == "")
+	if err != nil {
+		return fail(err)
+	}
+	if g == nil {
+		return fail(ErrUnknownMemberID)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case g.state == Dead:
+		return fail(ErrCoordinatorNotAvailable)
+	case !g.supports(req.ProtocolType, req.Protocols):
+		return fail(ErrInconsistentGroupProtocol)
+	}
+	if req.MemberID == "" {
+		g.joinNew(req, result)
+	} else {
+		g.joinKnown(req, result)
+	}
+	return result
+}
+
+func (g *Group) full() bool {
+	return len(g.members)+len(g.pending) >= g.coordinator.config.MaxSize
+}
+
+func (g *Group) joinNew(req JoinRequest, result chan JoinResult) {
+	id := req.ClientID + "-" + types.NewUUID().String()
+	if req.InstanceID != nil {
+		if current, ok := g.static[*req.InstanceID]; ok {
+			g.replaceStatic(g.members[current], id, req, result)
+			return
+		}
+	}
+	if g.full() {
+		result <- JoinResult{Err: ErrGroupMaxSizeReached, Generation: -1}
+		return
+	}
+	if req.InstanceID == nil && req.RequireKnownMemberID {
+		g.pending[id] = time.AfterFunc(req.SessionTimeout, func() { g.expirePending(id) })
+		result <- JoinResult{Err: ErrMemberIDRequired, MemberID: id, Generation: -1}
+		return
+	}
+	g.addAndRebalance(newMember(id, req), result)
+}
+
+func (g *Group) joinKnown(req JoinRequest, result chan JoinResult) {
+	if timer, ok := g.pending[req.MemberID]; ok {
+		timer.Stop()
+		delete(g.pending, req.MemberID)
+		g.addAndRebalance(newMember(req.MemberID, req), result)
+		return
+	}
+	m, err := g.member(req.MemberID, req.InstanceID)
+	if err != nil {
+		result <- JoinResult{Err: err, MemberID: req.MemberID, Generation: -1}
+		return
+	}
+	switch g.state {
+	case PreparingRebalance:
+		g.updateAndRebalance(m, req, result)
+	case CompletingRebalance:
+		// The member did not see its join response: repeat it unless its
+		// protocols changed.
+		if m.sameProtocols(req.Protocols) {
+			g.heartbeat(m)
+			result <- g.joinResult(m)
+			return
+		}
+		g.updateAndRebalance(m, req, result)
+	case Stable:
+		if m.ID == g.leader || !m.sameProtocols(req.Protocols) {
+			g.updateAndRebalance(m, req, result)
+			return
+		}
+		g.heartbeat(m)
+		result <- g.joinResult(m)
+	default:
+		result <- JoinResult{Err: ErrUnknownMemberID, MemberID: req.MemberID, Generation: -1}
+	}
+}
+
+func (g *Group) addAndRebalance(m *Member, result chan JoinResult) {
+	m.join = result
+	g.add(m)
+	g.heartbeat(m)
+	g.extendInitialDelay()
+	g.prepareRebalance()
+}
+
+func (g *Group) updateAndRebalance(m *Member, req JoinRequest, result chan JoinResult) {
+	if m.join != nil {
+		m.join <- JoinResult{Err: ErrRebalanceInProgress, MemberID: m.ID, Generation: -1}
+	}
+	m.update(req)
+	m.join = result
+	g.heartbeat(m)
+	g.prepareRebalance()
+}
+
+// replaceStatic hands the instance id of m to a new member id, fencing any
+// request still waiting under the old one. A follower rejoining a stable
+// group with the same protocols gets the current generation back without
+// a rebalance; so does the leader when it can skip the assignment.
+func (g *Group) replaceStatic(m *Member, id string, req JoinRequest, result chan JoinResult) {
+	if m.join != nil {
+		m.join <- JoinResult{Err: ErrFencedInstanceID, MemberID: m.ID, Generation: -1}
+		m.join = nil
+	}
+	if m.sync != nil {
+		m.sync <- SyncResult{Err: ErrFencedInstanceID}
+		m.sync = nil
+	}
+	delete(g.members, m.ID)
+	if g.leader == m.ID {
+		g.leader = id
+	}
+	m.ID = id
+	g.members[id] = m
+	g.static[*req.InstanceID] = id
+
+	unchanged := m.sameProtocols(req.Protocols)
+	if g.state == Stable && unchanged && (id != g.leader || req.SkipAssignment) {
+		m.update(req)
+		g.heartbeat(m)
+		r := g.joinResult(m)
+		r.SkipAssignment = id == g.leader
+		result <- r
+		return
+	}
+	g.updateAndRebalance(m, req, result)
+}
+
+// expirePending forgets a member id handed out with MEMBER_ID_REQUIRED
+// that was not used within the session timeout.
+func (g *Group) expirePending(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.pending[id]; ok {
+		delete(g.pending, id)
+		g.maybeCompleteJoin()
+	}
+}
+
+// SyncRequest is a SyncGroup request. Only the leader's carries
+// assignments.
+type SyncRequest struct {
+	GroupID      string
+	MemberID     string
+	InstanceID   *string
+	Generation   int32
+	ProtocolType *string
+	ProtocolName *string
+	Assignments  map[string][]byte
+}
+
+// SyncResult answers a SyncRequest with the member's assignment.
+type SyncResult struct {
+	Err          error
+	ProtocolType *string
+	ProtocolName *string
+	Assignment   []byte
+}
+
+// Sync returns the assignment of a member. While the group completes a
+// rebalance the result waits for the leader to send the assignments.
+func (c *Coordinator) Sync(req SyncRequest) <-chan SyncResult {
+	result := make(chan SyncResult, 1)
+	g, err := c.group(req.GroupID, false)
+	if err == nil && g == nil {
+		err = ErrUnknownMemberID
+	}
+	if err != nil {
+		result <- SyncResult{Err: err}
+		return result
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m, err := g.current(req.MemberID, req.InstanceID, req.Generation)
+	if err == nil && (!sameName(req.ProtocolType, g.protocolType) || !sameName(req.ProtocolName, g.protocol)) {
+		err = ErrInconsistentGroupProtocol
+	}
+	if err != nil {
+		result <- SyncResult{Err: err}
+		return result
+	}
+	switch g.state {
+	case Empty:
+		result <- SyncResult{Err: ErrUnknownMemberID}
+	case PreparingRebalance:
+		result <- SyncResult{Err: ErrRebalanceInProgress}
+	case CompletingRebalance:
+		if m.sync != nil {
+			m.sync <- SyncResult{Err: ErrRebalanceInProgress}
+		}
+		m.sync = result
+		g.heartbeat(m)
+		if m.ID == g.leader {
+			g.completeSync(req.Assignments)
+		}
+	case Stable:
+		g.heartbeat(m)
+		result <- g.syncResult(m)
+	}
+	return result
+}
+
+// completeSync stores the leader's assignments, stabilizes the group and
+// answers the members waiting in SyncGroup. Members the leader left out
+// get an empty assignment.
+func (g *Group) completeSync(assignments map[string][]byte) {
+	for id, m := range g.members {
+		m.Assignment = assignments[id]
+		if m.Assignment == nil {
+			m.Assignment = []byte{}
+		}
+	}
+	g.state = Stable
+	for _, m := range g.members {
+		if m.sync != nil {
+			m.sync <- g.syncResult(m)
+			m.sync = nil
+		}
+	}
+}
+
+func (g *Group) syncResult(m *Member) SyncResult {
+	return SyncResult{ProtocolType: g.protocolType, ProtocolName: g.protocol, Assignment: m.Assignment}
+}
+
+// sameName compares an optional protocol type or name of a request with
+// the group's; a request without one matches anything.
+func sameName(requested, current *string) bool {
+	return requested == nil || (current != nil && *requested == *current)
+}
+
+// current resolves a member of the current generation.
+func (g *Group) current(id string, instanceID *string, generation int32) (*Member, error) {
+	if g.state == Dead {
+		return nil, ErrCoordinatorNotAvailable
+	}
+	m, err := g.member(id, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if generation != g.generation {
+		return nil, ErrIllegalGeneration
+	}
+	return m, nil
+}
+
+// Heartbeat keeps the session of a member alive. It returns
+// ErrRebalanceInProgress when the member must rejoin.
+func (c *Coordinator) Heartbeat(groupID, memberID string, instanceID *string, generation int32) error {
+	g, err := c.group(groupID, false)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return ErrUnknownMemberID
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m, err := g.current(memberID, instanceID, generation)
+	if err != nil {
+		return err
+	}
+	switch g.state {
+	case Empty:
+		return ErrUnknownMemberID
+	case PreparingRebalance:
+		g.heartbeat(m)
+		return ErrRebalanceInProgress
+	}
+	g.heartbeat(m)
+	return nil
+}
+
+// LeaveMember identifies a member leaving its group. A static member may
+// leave by instance id alone.
+type LeaveMember struct {
+	ID         string
+	InstanceID *string
+}
+
+// Leave removes members from a group and returns the outcome for each,
+// or an error when the group cannot be looked at.
+func (c *Coordinator) Leave(groupID string, members []LeaveMember) ([]error, error) {
+	g, err := c.group(groupID, false)
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(members))
+	if g == nil {
+		for i := range errs {
+			errs[i] = ErrUnknownMemberID
+		}
+		return errs, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	removed, pending := false, false
+	for i, lm := range members {
+		if g.state == Dead {
+			errs[i] = ErrCoordinatorNotAvailable
+			continue
+		}
+		if timer, ok := g.pending[lm.ID]; ok && lm.InstanceID == nil {
+			timer.Stop()
+			delete(g.pending, lm.ID)
+			pending = true
+			continue
+		}
+		id := lm.ID
+		if lm.InstanceID != nil {
+			current, ok := g.static[*lm.InstanceID]
+			switch {
+			case !ok:
+				errs[i] = ErrUnknownMemberID
+				continue
+			case id != "" && id != current:
+				errs[i] = ErrFencedInstanceID
+				continue
+			}
+			id = current
+		}
+		m, ok := g.members[id]
+		if !ok {
+			errs[i] = ErrUnknownMemberID
+			continue
+		}
+		g.remove(m)
+		removed = true
+	}
+	if removed {
+		g.membersChanged()
+	} else if pending {
+		g.maybeCompleteJoin()
+	}
+	return errs, nil
+}
@@ -0,0 +1,436 @@
+// Package group implements the coordinator of classic consumer groups:
+// the JoinGroup / SyncGroup rebalance protocol with its group state
+// machine, member sessions and static membership.
+package group
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+)
+
+// State is the state of a group, named as upstream.
+type State int8
+
+const (
+	Empty State = iota
+	PreparingRebalance
+	CompletingRebalance
+	Stable
+	Dead
+)
+
+var stateNames = [...]string{"Empty", "PreparingRebalance", "CompletingRebalance", "Stable", "Dead"}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// Protocol is one assignment protocol a member supports, with the
+// metadata it passes to the group leader.
+type Protocol struct {
+	Name     string
+	Metadata []byte
+}
+
+// Member is a group member as the coordinator sees it.
+type Member struct {
+	ID               string
+	InstanceID       *string
+	ClientID         string
+	ClientHost       string
+	SessionTimeout   time.Duration
+	RebalanceTimeout time.Duration
+	ProtocolType     string
+	Protocols        []Protocol
+	Assignment       []byte
+
+	// joined orders members by the time they first joined; the oldest
+	// becomes leader when the leader leaves.
+	joined uint64
+	// join and sync are set while a JoinGroup or SyncGroup of the member
+	// is waiting for the rebalance to progress.
+	join    chan JoinResult
+	sync    chan SyncResult
+	session *time.Timer
+	// sessions counts the session timers started; a timer only expires
+	// the member if no heartbeat restarted it since.
+	sessions uint64
+}
+
+func (m *Member) metadata(protocol string) []byte {
+	for _, p := range m.Protocols {
+		if p.Name == protocol {
+			return p.Metadata
+		}
+	}
+	return nil
+}
+
+func (m *Member) sameProtocols(protocols []Protocol) bool {
+	return slices.EqualFunc(m.Protocols, protocols, func(a, b Protocol) bool {
+		return a.Name == b.Name && bytes.Equal(a.Metadata, b.Metadata)
+	})
+}
+
+// Group is a classic group. Every field is guarded by mu.
+type Group struct {
+	ID string
+
+	coordinator  *Coordinator
+	mu           sync.Mutex
+	state        State
+	generation   int32
+	protocolType *string
+	protocol     *string
+	leader       string
+	members      map[string]*Member
+	// static maps group.instance.id to the member currently using it.
+	static map[string]string
+	// pending holds the member ids handed out with MEMBER_ID_REQUIRED that
+	// have not joined with them yet.
+	pending map[string]*time.Timer
+	joins   uint64
+	// rebalance fires when the rebalance timeout is reached. While
+	// delaying, it is the initial rebalance delay of a group that was
+	// empty, which is extended as members keep joining until
+	// delayDeadline. rebalances counts the timers started so that one
+	// stopped too late to keep its callback from running does nothing.
+	rebalance     *time.Timer
+	rebalances    uint64
+	delaying      bool
+	delayDeadline time.Time
+	offsets       map[log.TopicPartition]Offset
+}
+
+func newGroup(c *Coordinator, id string) *Group {
+	return &Group{
+		ID:          id,
+		coordinator: c,
+		members:     make(map[string]*Member),
+		static:      make(map[string]string),
+		pending:     make(map[string]*time.Timer),
+		offsets:     make(map[log.TopicPartition]Offset),
+	}
+}
+
+// add makes m a member. The first member fixes the protocol type and
+// becomes leader.
+func (g *Group) add(m *Member) {
+	if len(g.members) == 0 {
+		protocolType := m.ProtocolType
+		g.protocolType = &protocolType
+	}
+	g.joins++
+	m.joined = g.joins
+	g.members[m.ID] = m
+	if m.InstanceID != nil {
+		g.static[*m.InstanceID] = m.ID
+	}
+	if g.leader == "" {
+		g.leader = m.ID
+	}
+}
+
+// remove drops m, failing whatever request of it is still waiting.
+func (g *Group) remove(m *Member) {
+	if m.session != nil {
+		m.session.Stop()
+	}
+	if m.join != nil {
+		m.join <- JoinResult{Err: ErrUnknownMemberID, MemberID: m.ID, Generation: -1}
+		m.join = nil
+	}
+	if m.sync != nil {
+		m.sync <- SyncResult{Err: ErrUnknownMemberID}
+		m.sync = nil
+	}
+	delete(g.members, m.ID)
+	if m.InstanceID != nil && g.static[*m.InstanceID] == m.ID {
+		delete(g.static, *m.InstanceID)
+	}
+	if g.leader == m.ID {
+		g.leader = ""
+	}
+}
+
+// member resolves the sender of a request. A static member must use the
+// member id currently registered for its instance id.
+func (g *Group) member(id string, instanceID *string) (*Member, error) {
+	if instanceID != nil {
+		if current, ok := g.static[*instanceID]; ok && current != id {
+			return nil, ErrFencedInstanceID
+		}
+	}
+	m, ok := g.members[id]
+	if !ok {
+		return nil, ErrUnknownMemberID
+	}
+	return m, nil
+}
+
+// heartbeat restarts the session timer of m.
+func (g *Group) heartbeat(m *Member) {
+	if m.session != nil {
+		m.session.Stop()
+	}
+	m.sessions++
+	session := m.sessions
+	m.session = time.AfterFunc(m.SessionTimeout, func() { g.expire(m, session) })
+}
+
+// expire removes a member whose session timed out. Members with a
+// request waiting on the rebalance are alive by definition.
+func (g *Group) expire(m *Member, session uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.members[m.ID] != m || m.sessions != session {
+		return
+	}
+	if m.join != nil || m.sync != nil {
+		g.heartbeat(m)
+		return
+	}
+	g.remove(m)
+	g.membersChanged()
+}
+
+// membersChanged reacts to members leaving outside of a JoinGroup.
+func (g *Group) membersChanged() {
+	switch g.state {
+	case Stable, CompletingRebalance:
+		g.prepareRebalance()
+	case PreparingRebalance:
+		g.maybeCompleteJoin()
+	}
+}
+
+// supports reports whether a member with these protocols may join: the
+// protocol type must match and the group must keep at least one protocol
+// every member supports.
+func (g *Group) supports(protocolType string, protocols []Protocol) bool {
+	if len(g.members) == 0 {
+		return true
+	}
+	if g.protocolType == nil || *g.protocolType != protocolType {
+		return false
+	}
+	for _, candidate := range g.candidates() {
+		if slices.ContainsFunc(protocols, func(p Protocol) bool { return p.Name == candidate }) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates lists the protocols every member supports, in the order of
+// preference of the oldest member.
+func (g *Group) candidates() []string {
+	var candidates []string
+	for i, m := range g.sortedMembers() {
+		var names []string
+		for _, p := range m.Protocols {
+			if i == 0 || slices.Contains(candidates, p.Name) {
+				names = append(names, p.Name)
+			}
+		}
+		candidates = names
+	}
+	return candidates
+}
+
+// selectProtocol lets every member vote for its most preferred candidate.
+// Ties go to the protocol the oldest member prefers.
+func (g *Group) selectProtocol() string {
+	candidates := g.candidates()
+	votes := make(map[string]int, len(candidates))
+	for _, m := range g.members {
+		for _, p := range m.Protocols {
+			if slices.Contains(candidates, p.Name) {
+				votes[p.Name]++
+				break
+			}
+		}
+	}
+	best := candidates[0]
+	for _, name := range candidates[1:] {
+		if votes[name] > votes[best] {
+			best = name
+		}
+	}
+	return best
+}
+
+func (g *Group) sortedMembers() []*Member {
+	members := make([]*Member, 0, len(g.members))
+	for _, m := range g.members {
+		members = append(members, m)
+	}
+	slices.SortFunc(members, func(a, b *Member) int { return int(a.joined) - int(b.joined) })
+	return members
+}
+
+// prepareRebalance asks every member to rejoin. Members still waiting for
+// their assignment learn about it at once.
+func (g *Group) prepareRebalance() {
+	if g.state == CompletingRebalance {
+		for _, m := range g.members {
+			if m.sync != nil {
+				m.sync <- SyncResult{Err: ErrRebalanceInProgress}
+				m.sync = nil
+			}
+		}
+	}
+	for _, m := range g.members {
+		m.Assignment = nil
+	}
+	if g.state == PreparingRebalance {
+		g.maybeCompleteJoin()
+		return
+	}
+	wasEmpty := g.state == Empty
+	g.state = PreparingRebalance
+	timeout := g.rebalanceTimeout()
+	if delay := g.coordinator.config.InitialRebalanceDelay; wasEmpty && delay > 0 {
+		g.delaying = true
+		g.delayDeadline = time.Now().Add(timeout)
+		g.startRebalanceTimer(min(delay, timeout), g.endInitialDelay)
+		return
+	}
+	g.startRebalanceTimer(timeout, g.onRebalanceTimeout)
+	g.maybeCompleteJoin()
+}
+
+// startRebalanceTimer replaces the rebalance timer with one running f,
+// with the group locked, after d.
+func (g *Group) startRebalanceTimer(d time.Duration, f func()) {
+	g.stopRebalanceTimer()
+	rebalance := g.rebalances
+	g.rebalance = time.AfterFunc(d, func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.rebalances == rebalance {
+			g.rebalance = nil
+			f()
+		}
+	})
+}
+
+// stopRebalanceTimer cancels the rebalance timer, including a callback
+// that already fired and waits for the lock.
+func (g *Group) stopRebalanceTimer() {
+	if g.rebalance != nil {
+		g.rebalance.Stop()
+		g.rebalance = nil
+	}
+	g.rebalances++
+}
+
+// extendInitialDelay gives other members of a starting group time to join
+// as well, so the group does not rebalance once per member.
+func (g *Group) extendInitialDelay() {
+	if !g.delaying {
+		return
+	}
+	remaining := time.Until(g.delayDeadline)
+	if remaining <= 0 {
+		return
+	}
+	g.startRebalanceTimer(min(g.coordinator.config.InitialRebalanceDelay, remaining), g.endInitialDelay)
+}
+
+func (g *Group) endInitialDelay() {
+	if !g.delaying {
+		return
+	}
+	g.delaying = false
+	if remaining := time.Until(g.delayDeadline); remaining > 0 {
+		g.startRebalanceTimer(remaining, g.onRebalanceTimeout)
+	}
+	g.maybeCompleteJoin()
+	if g.state == PreparingRebalance && time.Until(g.delayDeadline) <= 0 {
+		g.completeJoin()
+	}
+}
+
+func (g *Group) rebalanceTimeout() time.Duration {
+	var timeout time.Duration
+	for _, m := range g.members {
+		timeout = max(timeout, m.RebalanceTimeout)
+	}
+	return timeout
+}
+
+func (g *Group) onRebalanceTimeout() {
+	if g.state == PreparingRebalance && !g.delaying {
+		g.completeJoin()
+	}
+}
+
+// maybeCompleteJoin completes the rebalance once every member, including
+// those told to rejoin with their new member id, is waiting in JoinGroup.
+func (g *Group) maybeCompleteJoin() {
+	if g.state != PreparingRebalance || g.delaying || len(g.pending) > 0 {
+		return
+	}
+	for _, m := range g.members {
+		if m.join == nil {
+			return
+		}
+	}
+	g.completeJoin()
+}
+
+// completeJoin starts a new generation with the members that rejoined and
+// answers their JoinGroup requests; only the leader learns about the
+// other members.
+func (g *Group) completeJoin() {
+	g.stopRebalanceTimer()
+	g.delaying = false
+	for _, m := range g.members {
+		if m.join == nil {
+			g.remove(m)
+		}
+	}
+	g.generation++
+	if len(g.members) == 0 {
+		g.state = Empty
+		g.protocol = nil
+		g.leader = ""
+		return
+	}
+	protocol := g.selectProtocol()
+	g.protocol = &protocol
+	if g.leader == "" {
+		g.leader = g.sortedMembers()[0].ID
+	}
+	g.state = CompletingRebalance
+	for _, m := range g.members {
+		m.join <- g.joinResult(m)
+		m.join = nil
+		g.heartbeat(m)
+	}
+}
+
+func (g *Group) joinResult(m *Member) JoinResult {
+	r := JoinResult{
+		Generation:   g.generation,
+		ProtocolType: g.protocolType,
+		ProtocolName: g.protocol,
+		Leader:       g.leader,
+		MemberID:     m.ID,
+	}
+	if m.ID == g.leader {
+		for _, other := range g.sortedMembers() {
+			r.Members = append(r.Members, JoinMember{
+				ID:         other.ID,
+				InstanceID: other.InstanceID,
+				Metadata:   other.metadata(*g.protocol),
+			})
+		}
+	}
+	return r
+}
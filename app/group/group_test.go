@@ -0,0 +1,218 @@
+package group
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+func newTestCoordinator(initialDelay time.Duration) *Coordinator {
+	c := NewCoordinator(Config{
+		MinSessionTimeout:     time.Millisecond,
+		MaxSessionTimeout:     time.Minute,
+		InitialRebalanceDelay: initialDelay,
+		MaxSize:               10,
+		OffsetsPartitions:     1,
+		MaxMetadataSize:       4096,
+	})
+	c.SetStore(func(int32, []types.Record) error { return nil })
+	return c
+}
+
+func joinRequest(memberID string, rebalanceTimeout time.Duration) JoinRequest {
+	return JoinRequest{
+		GroupID:          "g",
+		MemberID:         memberID,
+		ClientID:         "client",
+		SessionTimeout:   time.Minute,
+		RebalanceTimeout: rebalanceTimeout,
+		ProtocolType:     consumerProtocolType,
+		Protocols:        []Protocol{{Name: "range", Metadata: []byte{1}}},
+	}
+}
+
+func await[T any](t *testing.T, result <-chan T) T {
+	t.Helper()
+	select {
+	case r := <-result:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result")
+		panic("unreachable")
+	}
+}
+
+func pending[T any](t *testing.T, result <-chan T) {
+	t.Helper()
+	select {
+	case r := <-result:
+		t.Fatalf("unexpected result %+v", r)
+	default:
+	}
+}
+
+func testGroup(t *testing.T, c *Coordinator) *Group {
+	t.Helper()
+	g, err := c.group("g", false)
+	if err != nil || g == nil {
+		t.Fatalf("group: %v, %v", g, err)
+	}
+	return g
+}
+
+func state(g *Group) (State, int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.state, len(g.members)
+}
+
+// stableGroup brings two members to a stable generation and returns their
+// join results, the leader's first.
+func stableGroup(t *testing.T, c *Coordinator, rebalanceTimeout time.Duration) (JoinResult, JoinResult) {
+	t.Helper()
+	a := await(t, c.Join(joinRequest("", rebalanceTimeout)))
+	bJoin := c.Join(joinRequest("", rebalanceTimeout))
+	pending(t, bJoin)
+	if err := c.Heartbeat("g", a.MemberID, nil, a.Generation); !errors.Is(err, ErrRebalanceInProgress) {
+		t.Fatalf("Heartbeat during the rebalance = %v, want ErrRebalanceInProgress", err)
+	}
+	aJoin := c.Join(joinRequest(a.MemberID, rebalanceTimeout))
+	a, b := await(t, aJoin), await(t, bJoin)
+	if a.Err != nil || b.Err != nil || a.Generation != b.Generation || a.Leader != a.MemberID || b.Leader != a.MemberID {
+		t.Fatalf("join results %+v and %+v", a, b)
+	}
+	if len(a.Members) != 2 || len(b.Members) != 0 {
+		t.Fatalf("leader sees %d members and follower %d, want 2 and 0", len(a.Members), len(b.Members))
+	}
+	bSync := c.Sync(SyncRequest{GroupID: "g", MemberID: b.MemberID, Generation: b.Generation})
+	pending(t, bSync)
+	aSync := c.Sync(SyncRequest{GroupID: "g", MemberID: a.MemberID, Generation: a.Generation, Assignments: map[string][]byte{
+		a.MemberID: []byte("a"),
+		b.MemberID: []byte("b"),
+	}})
+	if r := await(t, aSync); r.Err != nil || string(r.Assignment) != "a" {
+		t.Fatalf("leader sync = %+v", r)
+	}
+	if r := await(t, bSync); r.Err != nil || string(r.Assignment) != "b" {
+		t.Fatalf("follower sync = %+v", r)
+	}
+	return a, b
+}
+
+func TestJoinSync(t *testing.T) {
+	c := newTestCoordinator(0)
+	a, b := stableGroup(t, c, time.Minute)
+	g := testGroup(t, c)
+	if s, n := state(g); s != Stable || n != 2 {
+		t.Fatalf("group is %v with %d members, want Stable with 2", s, n)
+	}
+	if err := c.Heartbeat("g", b.MemberID, nil, b.Generation); err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if err := c.Heartbeat("g", b.MemberID, nil, b.Generation-1); !errors.Is(err, ErrIllegalGeneration) {
+		t.Fatalf("Heartbeat of an old generation = %v, want ErrIllegalGeneration", err)
+	}
+
+	// The leader leaving makes the follower rejoin as the new leader.
+	if errs, err := c.Leave("g", []LeaveMember{{ID: a.MemberID}}); err != nil || errs[0] != nil {
+		t.Fatalf("Leave = %v, %v", errs, err)
+	}
+	r := await(t, c.Join(joinRequest(b.MemberID, time.Minute)))
+	if r.Err != nil || r.Generation != b.Generation+1 || r.Leader != b.MemberID {
+		t.Fatalf("rejoin after the leader left = %+v", r)
+	}
+}
+
+func TestMemberIDRequired(t *testing.T) {
+	c := newTestCoordinator(0)
+	req := joinRequest("", time.Minute)
+	req.RequireKnownMemberID = true
+	r := await(t, c.Join(req))
+	if !errors.Is(r.Err, ErrMemberIDRequired) || r.MemberID == "" {
+		t.Fatalf("first join = %+v, want ErrMemberIDRequired with an id", r)
+	}
+	req.MemberID = r.MemberID
+	if r := await(t, c.Join(req)); r.Err != nil || r.Generation != 1 || r.Leader != req.MemberID {
+		t.Fatalf("join with the given id = %+v", r)
+	}
+}
+
+func TestInitialRebalanceDelay(t *testing.T) {
+	c := newTestCoordinator(50 * time.Millisecond)
+	aJoin := c.Join(joinRequest("", time.Minute))
+	bJoin := c.Join(joinRequest("", time.Minute))
+	pending(t, aJoin)
+	a, b := await(t, aJoin), await(t, bJoin)
+	if a.Err != nil || b.Err != nil || a.Generation != 1 || b.Generation != 1 {
+		t.Fatalf("join results %+v and %+v, want both in generation 1", a, b)
+	}
+}
+
+func TestRebalanceTimeoutRemovesMembers(t *testing.T) {
+	c := newTestCoordinator(0)
+	a, b := stableGroup(t, c, 50*time.Millisecond)
+	r := await(t, c.Join(joinRequest(a.MemberID, 50*time.Millisecond)))
+	if r.Err != nil || r.Generation != a.Generation+1 || len(r.Members) != 1 {
+		t.Fatalf("join after the timeout = %+v, want the leader alone", r)
+	}
+	if err := c.Heartbeat("g", b.MemberID, nil, b.Generation); !errors.Is(err, ErrUnknownMemberID) {
+		t.Fatalf("Heartbeat of the removed member = %v, want ErrUnknownMemberID", err)
+	}
+}
+
+// A rebalance timer that fires while the group is locked must not act
+// once the rebalance it was started for has completed.
+func TestStaleRebalanceTimer(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	c := newTestCoordinator(0)
+	a, b := stableGroup(t, c, timeout)
+	g := testGroup(t, c)
+	aJoin := c.Join(joinRequest(a.MemberID, timeout))
+
+	g.mu.Lock()
+	time.Sleep(2 * timeout)
+	// b rejoins and completes the rebalance, then a new member starts the
+	// next one, all before the first timer's callback gets the lock.
+	bJoin := make(chan JoinResult, 1)
+	g.joinKnown(joinRequest(b.MemberID, timeout), bJoin)
+	cJoin := make(chan JoinResult, 1)
+	g.joinNew(joinRequest("", timeout), cJoin)
+	g.mu.Unlock()
+
+	if r := await(t, aJoin); r.Err != nil || r.Generation != a.Generation+1 {
+		t.Fatalf("join of a = %+v", r)
+	}
+	await(t, bJoin)
+	time.Sleep(timeout / 5)
+	if s, n := state(g); s != PreparingRebalance || n != 3 {
+		t.Fatalf("group is %v with %d members, want PreparingRebalance with 3", s, n)
+	}
+	pending(t, cJoin)
+}
+
+// A session timer that fires while the group is locked must not expire a
+// member that heartbeated in the meantime.
+func TestStaleSessionTimer(t *testing.T) {
+	c := newTestCoordinator(0)
+	req := joinRequest("", time.Minute)
+	req.SessionTimeout = 20 * time.Millisecond
+	r := await(t, c.Join(req))
+	if r.Err != nil {
+		t.Fatalf("Join: %v", r.Err)
+	}
+	g := testGroup(t, c)
+
+	g.mu.Lock()
+	time.Sleep(2 * req.SessionTimeout)
+	m := g.members[r.MemberID]
+	m.SessionTimeout = time.Minute
+	g.heartbeat(m)
+	g.mu.Unlock()
+
+	time.Sleep(req.SessionTimeout)
+	if _, n := state(g); n != 1 {
+		t.Fatal("the member expired right after a heartbeat")
+	}
+}
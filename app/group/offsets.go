@@ -0,0 +1,221 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+var (
+	ErrCoordinatorLoadInProgress = errors.New("coordinator load in progress")
+	ErrOffsetMetadataTooLarge    = errors.New("offset metadata too large")
+	ErrInvalidCommitOffsetSize   = errors.New("invalid commit offset size")
+)
+
+// Offset is the committed position of a group on a partition.
+type Offset struct {
+	Offset          int64
+	LeaderEpoch     int32
+	Metadata        string
+	CommitTimestamp int64
+}
+
+// AppendFunc writes records to a partition of __consumer_offsets.
+type AppendFunc func(partition int32, records []types.Record) error
+
+// SetStore makes append persist the offsets committed from now on.
+func (c *Coordinator) SetStore(f AppendFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.appendRecords = f
+}
+
+// StartLoading makes the groups stored in partitions answer
+// ErrCoordinatorLoadInProgress until FinishLoading.
+func (c *Coordinator) StartLoading(partitions []int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range partitions {
+		c.loading[p] = true
+	}
+}
+
+func (c *Coordinator) FinishLoading(partition int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.loading, partition)
+}
+
+// FailLoading finishes loading a partition that could not be read. The
+// groups replayed from it so far are dropped and its groups answer
+// ErrCoordinatorNotAvailable from then on.
+func (c *Coordinator) FailLoading(partition int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.loading, partition)
+	c.failed[partition] = true
+	for id := range c.groups {
+		if PartitionFor(id, c.config.OffsetsPartitions) == partition {
+			delete(c.groups, id)
+		}
+	}
+}
+
+// Replay applies a record read back from __consumer_offsets while loading.
+func (c *Coordinator) Replay(key, value []byte) error {
+	groupID, tp, offset, ok, err := parseOffsetCommit(key, value)
+	if err != nil || !ok {
+		return err
+	}
+	c.mu.Lock()
+	g, exists := c.groups[groupID]
+	if !exists && offset != nil {
+		g = newGroup(c, groupID)
+		c.groups[groupID] = g
+	}
+	c.mu.Unlock()
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if offset != nil {
+		g.offsets[tp] = *offset
+		return nil
+	}
+	// A group whose last offset was deleted is gone with it, as is one
+	// deleted by DeleteGroups.
+	delete(g.offsets, tp)
+	if len(g.offsets) == 0 && len(g.members) == 0 {
+		c.mu.Lock()
+		if c.groups[groupID] == g {
+			delete(c.groups, groupID)
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// CommitRequest is an OffsetCommit request of one group. A negative
+// Generation without a member commits for a group that does not use the
+// coordinator for membership, which is only allowed while it is empty.
+type CommitRequest struct {
+	GroupID    string
+	MemberID   string
+	InstanceID *string
+	Generation int32
+	Offsets    map[log.TopicPartition]Offset
+}
+
+// Commit persists the offsets of a group. It returns the partitions whose
+// offset was refused, and an error when the whole commit was.
+func (c *Coordinator) Commit(req CommitRequest) (map[log.TopicPartition]error, error) {
+	if req.GroupID == "" {
+		return nil, ErrInvalidGroupID
+	}
+	g, err := c.group(req.GroupID, req.Generation < 0)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrIllegalGeneration
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.validateCommit(req.MemberID, req.InstanceID, req.Generation); err != nil {
+		return nil, err
+	}
+
+	refused := make(map[log.TopicPartition]error)
+	var records []types.Record
+	now := time.Now().UnixMilli()
+	for tp, o := range req.Offsets {
+		if len(o.Metadata) > c.config.MaxMetadataSize {
+			refused[tp] = ErrOffsetMetadataTooLarge
+			continue
+		}
+		o.CommitTimestamp = now
+		req.Offsets[tp] = o
+		records = append(records, types.Record{Key: offsetCommitKey(g.ID, tp), Value: offsetCommitValue(o)})
+	}
+	if len(records) > 0 {
+		if err := c.store(g.ID, records); err != nil {
+			return nil, err
+		}
+	}
+	for tp, o := range req.Offsets {
+		if refused[tp] == nil {
+			g.offsets[tp] = o
+		}
+	}
+	return refused, nil
+}
+
+// store writes records to the partition of __consumer_offsets of a group.
+func (c *Coordinator) store(groupID string, records []types.Record) error {
+	c.mu.Lock()
+	write := c.appendRecords
+	c.mu.Unlock()
+	if write == nil {
+		return nil
+	}
+	err := write(PartitionFor(groupID, c.config.OffsetsPartitions), records)
+	switch {
+	case errors.Is(err, log.ErrRecordTooLarge):
+		return fmt.Errorf("%w: %v", ErrInvalidCommitOffsetSize, err)
+	case err != nil:
+		return fmt.Errorf("%w: %v", ErrCoordinatorNotAvailable, err)
+	}
+	return nil
+}
+
+// validateCommit checks the sender of a commit. Members may commit while
+// the group prepares a rebalance, but not while it waits for the new
+// assignment.
+func (g *Group) validateCommit(memberID string, instanceID *string, generation int32) error {
+	switch {
+	case g.state == Dead:
+		return ErrCoordinatorNotAvailable
+	case generation < 0 && g.state == Empty:
+		return nil
+	case generation < 0 && memberID == "" && instanceID == nil:
+		return ErrUnknownMemberID
+	}
+	m, err := g.current(memberID, instanceID, generation)
+	if err != nil {
+		return err
+	}
+	if g.state == CompletingRebalance {
+		return ErrRebalanceInProgress
+	}
+	g.heartbeat(m)
+	return nil
+}
+
+// Fetch returns the committed offsets of a group: those of partitions, or
+// all of them when partitions is nil. Partitions without a committed
+// offset are left out.
+func (c *Coordinator) Fetch(groupID string, partitions []log.TopicPartition) (map[log.TopicPartition]Offset, error) {
+	g, err := c.group(groupID, false)
+	if err != nil || g == nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	offsets := make(map[log.TopicPartition]Offset)
+	if partitions == nil {
+		for tp, o := range g.offsets {
+			offsets[tp] = o
+		}
+		return offsets, nil
+	}
+	for _, tp := range partitions {
+		if o, ok := g.offsets[tp]; ok {
+			offsets[tp] = o
+		}
+	}
+	return offsets, nil
+}
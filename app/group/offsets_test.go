@@ -0,0 +1,208 @@
+package group
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+func TestFailLoading(t *testing.T) {
+	c := newTestCoordinator(0)
+	c.config.OffsetsPartitions = 2
+	// One group stored in each partition.
+	var ids [2]string
+	for i := 0; ids[0] == "" || ids[1] == ""; i++ {
+		id := fmt.Sprint("group-", i)
+		ids[PartitionFor(id, 2)] = id
+	}
+	c.StartLoading([]int32{0, 1})
+	tp := log.TopicPartition{Topic: "t", Partition: 0}
+	for _, id := range ids {
+		if err := c.Replay(offsetCommitKey(id, tp), offsetCommitValue(Offset{Offset: 5, LeaderEpoch: -1})); err != nil {
+			t.Fatalf("Replay: %v", err)
+		}
+	}
+	if _, err := c.Fetch(ids[0], nil); !errors.Is(err, ErrCoordinatorLoadInProgress) {
+		t.Fatalf("Fetch while loading = %v, want ErrCoordinatorLoadInProgress", err)
+	}
+
+	c.FinishLoading(0)
+	c.FailLoading(1)
+	offsets, err := c.Fetch(ids[0], nil)
+	if err != nil || offsets[tp].Offset != 5 {
+		t.Fatalf("Fetch of the loaded partition = %v, %v", offsets, err)
+	}
+	if _, err := c.Fetch(ids[1], nil); !errors.Is(err, ErrCoordinatorNotAvailable) {
+		t.Fatalf("Fetch of the failed partition = %v, want ErrCoordinatorNotAvailable", err)
+	}
+	req := joinRequest("", time.Minute)
+	req.GroupID = ids[1]
+	if r := await(t, c.Join(req)); !errors.Is(r.Err, ErrCoordinatorNotAvailable) {
+		t.Fatalf("Join in the failed partition = %v, want ErrCoordinatorNotAvailable", r.Err)
+	}
+	listings, err := c.List()
+	if !errors.Is(err, ErrCoordinatorNotAvailable) || len(listings) != 1 || listings[0].ID != ids[0] {
+		t.Fatalf("List = %v, %v; want only %s and ErrCoordinatorNotAvailable", listings, err, ids[0])
+	}
+}
+
+// recordStore captures the records a coordinator writes.
+func recordStore(c *Coordinator) *[]types.Record {
+	var written []types.Record
+	c.SetStore(func(_ int32, records []types.Record) error {
+		written = append(written, records...)
+		return nil
+	})
+	return &written
+}
+
+func TestCommitMetadataTooLarge(t *testing.T) {
+	c := newTestCoordinator(0)
+	written := recordStore(c)
+	small, large := log.TopicPartition{Topic: "t", Partition: 0}, log.TopicPartition{Topic: "t", Partition: 1}
+	refused, err := c.Commit(CommitRequest{GroupID: "g", Generation: -1, Offsets: map[log.TopicPartition]Offset{
+		small: {Offset: 1, LeaderEpoch: -1, Metadata: strings.Repeat("m", c.config.MaxMetadataSize)},
+		large: {Offset: 2, LeaderEpoch: -1, Metadata: strings.Repeat("m", c.config.MaxMetadataSize+1)},
+	}})
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if refused[small] != nil || !errors.Is(refused[large], ErrOffsetMetadataTooLarge) {
+		t.Fatalf("refused = %v, want only %s with ErrOffsetMetadataTooLarge", refused, large)
+	}
+	if len(*written) != 1 {
+		t.Fatalf("wrote %d records, want the one accepted offset", len(*written))
+	}
+	offsets, err := c.Fetch("g", nil)
+	if _, ok := offsets[large]; err != nil || ok || offsets[small].Offset != 1 {
+		t.Fatalf("Fetch = %v, %v; want only %s", offsets, err, small)
+	}
+}
+
+func TestCommitFetch(t *testing.T) {
+	c := newTestCoordinator(0)
+	a, _ := stableGroup(t, c, time.Minute)
+	tp0, tp1 := log.TopicPartition{Topic: "t", Partition: 0}, log.TopicPartition{Topic: "t", Partition: 1}
+	commit := func(generation int32, offsets map[log.TopicPartition]Offset) error {
+		_, err := c.Commit(CommitRequest{GroupID: "g", MemberID: a.MemberID, Generation: generation, Offsets: offsets})
+		return err
+	}
+	before := time.Now().UnixMilli()
+	if err := commit(a.Generation, map[log.TopicPartition]Offset{
+		tp0: {Offset: 10, LeaderEpoch: 3, Metadata: "m"},
+		tp1: {Offset: 20, LeaderEpoch: -1},
+	}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := commit(a.Generation-1, map[log.TopicPartition]Offset{tp0: {Offset: 99}}); !errors.Is(err, ErrIllegalGeneration) {
+		t.Fatalf("Commit of an old generation = %v, want ErrIllegalGeneration", err)
+	}
+	c.SetStore(func(int32, []types.Record) error { return errors.New("disk full") })
+	if err := commit(a.Generation, map[log.TopicPartition]Offset{tp0: {Offset: 99}}); !errors.Is(err, ErrCoordinatorNotAvailable) {
+		t.Fatalf("Commit that fails to store = %v, want ErrCoordinatorNotAvailable", err)
+	}
+
+	offsets, err := c.Fetch("g", nil)
+	if err != nil || len(offsets) != 2 {
+		t.Fatalf("Fetch all = %v, %v", offsets, err)
+	}
+	if o := offsets[tp0]; o.Offset != 10 || o.LeaderEpoch != 3 || o.Metadata != "m" || o.CommitTimestamp < before {
+		t.Fatalf("offset of %s = %+v", tp0, o)
+	}
+	missing := log.TopicPartition{Topic: "t", Partition: 2}
+	offsets, err = c.Fetch("g", []log.TopicPartition{tp1, missing})
+	if err != nil || len(offsets) != 1 || offsets[tp1].Offset != 20 {
+		t.Fatalf("Fetch of %s and %s = %v, %v", tp1, missing, offsets, err)
+	}
+	if offsets, err := c.Fetch("unknown", nil); err != nil || len(offsets) != 0 {
+		t.Fatalf("Fetch of an unknown group = %v, %v", offsets, err)
+	}
+}
+
+func TestReplayTombstone(t *testing.T) {
+	c := newTestCoordinator(0)
+	tp0, tp1 := log.TopicPartition{Topic: "t", Partition: 0}, log.TopicPartition{Topic: "t", Partition: 1}
+	replay := func(tp log.TopicPartition, value []byte) {
+		t.Helper()
+		if err := c.Replay(offsetCommitKey("g", tp), value); err != nil {
+			t.Fatalf("Replay: %v", err)
+		}
+	}
+	replay(tp0, offsetCommitValue(Offset{Offset: 1, LeaderEpoch: -1}))
+	replay(tp1, offsetCommitValue(Offset{Offset: 2, LeaderEpoch: -1}))
+	replay(tp0, nil)
+	if offsets, err := c.Fetch("g", nil); err != nil || len(offsets) != 1 || offsets[tp1].Offset != 2 {
+		t.Fatalf("Fetch after deleting %s = %v, %v", tp0, offsets, err)
+	}
+	replay(tp1, nil)
+	if g, err := c.group("g", false); err != nil || g != nil {
+		t.Fatalf("group after deleting its last offset = %v, %v; want none", g, err)
+	}
+	if err := c.Replay(offsetCommitKey("other", tp0), nil); err != nil {
+		t.Fatal(err)
+	}
+	if g, _ := c.group("other", false); g != nil {
+		t.Fatal("the tombstone of an unknown group created it")
+	}
+}
+
+func TestParseOffsetCommit(t *testing.T) {
+	tp := log.TopicPartition{Topic: "t", Partition: 4}
+	encode := func(f func(e *types.Encoder)) []byte {
+		var buf bytes.Buffer
+		f(types.NewEncoder(&buf, false))
+		return buf.Bytes()
+	}
+	keyV0 := encode(func(e *types.Encoder) {
+		e.Int16(0)
+		e.String("g")
+		e.String(tp.Topic)
+		e.Int32(tp.Partition)
+	})
+	// Version 1 has no leader epoch, but an expire timestamp after the
+	// commit timestamp.
+	valueV1 := encode(func(e *types.Encoder) {
+		e.Int16(1)
+		e.Int64(42)
+		e.String("m")
+		e.Int64(1000)
+		e.Int64(2000)
+	})
+	groupKey := encode(func(e *types.Encoder) {
+		e.Int16(groupMetadataKeyVersion)
+		e.String("g")
+	})
+	tests := []struct {
+		name       string
+		key, value []byte
+		offset     *Offset
+		ok         bool
+	}{
+		{"v3", offsetCommitKey("g", tp), offsetCommitValue(Offset{Offset: 42, LeaderEpoch: 7, Metadata: "m", CommitTimestamp: 1000}),
+			&Offset{Offset: 42, LeaderEpoch: 7, Metadata: "m", CommitTimestamp: 1000}, true},
+		{"v1", keyV0, valueV1, &Offset{Offset: 42, LeaderEpoch: -1, Metadata: "m", CommitTimestamp: 1000}, true},
+		{"tombstone", offsetCommitKey("g", tp), nil, nil, true},
+		{"group metadata", groupKey, []byte{0, 3}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupID, gotTP, offset, ok, err := parseOffsetCommit(tt.key, tt.value)
+			if err != nil || ok != tt.ok || !reflect.DeepEqual(offset, tt.offset) {
+				t.Fatalf("parseOffsetCommit = %+v, %v, %v; want %+v, %v", offset, ok, err, tt.offset, tt.ok)
+			}
+			if ok && (groupID != "g" || gotTP != tp) {
+				t.Fatalf("key = %s %s, want g %s", groupID, gotTP, tp)
+			}
+		})
+	}
+	if _, _, _, _, err := parseOffsetCommit(offsetCommitKey("g", tp), valueV1[:10]); err == nil {
+		t.Fatal("parseOffsetCommit accepted a truncated value")
+	}
+}
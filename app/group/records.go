@@ -0,0 +1,92 @@
+package group
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Key versions of the records in __consumer_offsets, as upstream writes
+// them. Versions 0 and 1 key committed offsets, version 2 group metadata.
+const (
+	offsetCommitKeyVersion   int16 = 1
+	groupMetadataKeyVersion  int16 = 2
+	offsetCommitValueVersion int16 = 3
+)
+
+// PartitionFor returns the partition of __consumer_offsets that stores a
+// group, as upstream computes it from the Java hash code of the group id.
+func PartitionFor(groupID string, partitions int32) int32 {
+	var hash int32
+	for _, u := range utf16.Encode([]rune(groupID)) {
+		hash = 31*hash + int32(u)
+	}
+	return (hash & 0x7fffffff) % partitions
+}
+
+func offsetCommitKey(groupID string, tp log.TopicPartition) []byte {
+	var buf bytes.Buffer
+	e := types.NewEncoder(&buf, false)
+	e.Int16(offsetCommitKeyVersion)
+	e.String(groupID)
+	e.String(tp.Topic)
+	e.Int32(tp.Partition)
+	return buf.Bytes()
+}
+
+// groupMetadataKey keys the metadata of a group. Only its tombstone is
+// written, when the group is deleted.
+func groupMetadataKey(groupID string) []byte {
+	var buf bytes.Buffer
+	e := types.NewEncoder(&buf, false)
+	e.Int16(groupMetadataKeyVersion)
+	e.String(groupID)
+	return buf.Bytes()
+}
+
+func offsetCommitValue(o Offset) []byte {
+	var buf bytes.Buffer
+	e := types.NewEncoder(&buf, false)
+	e.Int16(offsetCommitValueVersion)
+	e.Int64(o.Offset)
+	e.Int32(o.LeaderEpoch)
+	e.String(o.Metadata)
+	e.Int64(o.CommitTimestamp)
+	return buf.Bytes()
+}
+
+// parseOffsetCommit decodes a record of __consumer_offsets. ok is false
+// for records that do not hold an offset, and offset is nil for the
+// tombstone of a deleted offset.
+func parseOffsetCommit(key, value []byte) (groupID string, tp log.TopicPartition, offset *Offset, ok bool, err error) {
+	d := types.NewDecoder(bytes.NewReader(key), false)
+	version := d.Int16()
+	if d.Err() == nil && version >= groupMetadataKeyVersion {
+		return "", tp, nil, false, nil
+	}
+	groupID = d.String()
+	tp.Topic = d.String()
+	tp.Partition = d.Int32()
+	if err := d.Err(); err != nil {
+		return "", tp, nil, false, fmt.Errorf("offset commit key: %w", err)
+	}
+	if value == nil {
+		return groupID, tp, nil, true, nil
+	}
+	d = types.NewDecoder(bytes.NewReader(value), false)
+	o := Offset{LeaderEpoch: -1}
+	version = d.Int16()
+	o.Offset = d.Int64()
+	if version >= 3 {
+		o.LeaderEpoch = d.Int32()
+	}
+	o.Metadata = d.String()
+	o.CommitTimestamp = d.Int64()
+	if err := d.Err(); err != nil {
+		return "", tp, nil, false, fmt.Errorf("offset commit value: %w", err)
+	}
+	return groupID, tp, &o, true, nil
+}
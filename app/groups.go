@@ -0,0 +1,157 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetsTopic stores the committed offsets and metadata of groups.
+const OffsetsTopic = "__consumer_offsets"
+
+// groupErrorCode maps the errors of the group coordinator to protocol error codes.
+func groupErrorCode(err error) int16 {
+	switch {
+	case err == nil:
+		return NONE
+	case errors.Is(err, group.ErrInvalidGroupID):
+		return INVALID_GROUP_ID
+	case errors.Is(err, group.ErrInvalidSessionTimeout):
+		return INVALID_SESSION_TIMEOUT
+	case errors.Is(err, group.ErrInconsistentGroupProtocol):
+		return INCONSISTENT_GROUP_PROTOCOL
+	case errors.Is(err, group.ErrUnknownMemberID):
+		return UNKNOWN_MEMBER_ID
+	case errors.Is(err, group.ErrMemberIDRequired):
+		return MEMBER_ID_REQUIRED
+	case errors.Is(err, group.ErrFencedInstanceID):
+		return FENCED_INSTANCE_ID
+	case errors.Is(err, group.ErrIllegalGeneration):
+		return ILLEGAL_GENERATION
+	case errors.Is(err, group.ErrRebalanceInProgress):
+		return REBALANCE_IN_PROGRESS
+	case errors.Is(err, group.ErrGroupMaxSizeReached):
+		return GROUP_MAX_SIZE_REACHED
+	case errors.Is(err, group.ErrCoordinatorNotAvailable):
+		return COORDINATOR_NOT_AVAILABLE
+	case errors.Is(err, group.ErrCoordinatorLoadInProgress):
+		return COORDINATOR_LOAD_IN_PROGRESS
+	case errors.Is(err, group.ErrOffsetMetadataTooLarge):
+		return OFFSET_METADATA_TOO_LARGE
+	case errors.Is(err, group.ErrInvalidCommitOffsetSize):
+		return INVALID_COMMIT_OFFSET_SIZE
+	case errors.Is(err, group.ErrGroupIDNotFound):
+		return GROUP_ID_NOT_FOUND
+	case errors.Is(err, group.ErrNonEmptyGroup):
+		return NON_EMPTY_GROUP
+	case errors.Is(err, group.ErrGroupSubscribedToTopic):
+		return GROUP_SUBSCRIBED_TO_TOPIC
+	}
+	return UNKNOWN_SERVER_ERROR
+}
+
+// ensureOffsetsTopic creates __consumer_offsets the first time a client
+// looks for a group coordinator, as upstream does.
+func (b *Broker) ensureOffsetsTopic() error {
+	if _, ok := b.Metadata.Topic(OffsetsTopic); ok {
+		return nil
+	}
+	assignments, err := b.assignReplicas(b.Config.OffsetsTopicNumPartitions, b.Config.OffsetsTopicReplication)
+	if err != nil {
+		return err
+	}
+	configs := map[string]string{
+		"cleanup.policy":   "compact",
+		"compression.type": "producer",
+		"segment.bytes":    fmt.Sprint(b.Config.OffsetsTopicSegmentBytes),
+	}
+	_, err = b.writeTopic(OffsetsTopic, assignments, configs, false)
+	if errors.Is(err, errTopicExists) {
+		return nil
+	}
+	return err
+}
+
+// appendGroupRecords writes records of the group coordinator to a
+// partition of __consumer_offsets, creating the topic if need be.
+func (b *Broker) appendGroupRecords(partition int32, records []types.Record) error {
+	if err := b.ensureOffsetsTopic(); err != nil {
+		return err
+	}
+	l, ok, err := b.partitionLog(OffsetsTopic, partition)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s-%d", errUnknownTopic, OffsetsTopic, partition)
+	}
+	now := time.Now().UnixMilli()
+	timestamps := make([]int64, len(records))
+	for i := range timestamps {
+		timestamps[i] = now
+	}
+	batch, err := types.NewRecordBatch(0, timestamps, records).Encode()
+	if err != nil {
+		return err
+	}
+	_, err = l.Append(batch)
+	return err
+}
+
+// LoadGroups replays every partition of __consumer_offsets into the group
+// coordinator. The groups of a partition become available once it has
+// been read; those of a partition that cannot be read stay unavailable.
+func (b *Broker) LoadGroups() error {
+	t, ok := b.Metadata.Topic(OffsetsTopic)
+	if !ok {
+		return nil
+	}
+	var err error
+	for _, p := range t.Partitions {
+		if loadErr := b.loadGroupPartition(p.Index); loadErr != nil {
+			err = errors.Join(err, fmt.Errorf("%s-%d: %w", OffsetsTopic, p.Index, loadErr))
+			b.Groups.FailLoading(p.Index)
+			continue
+		}
+		b.Groups.FinishLoading(p.Index)
+	}
+	return err
+}
+
+func (b *Broker) loadGroupPartition(partition int32) error {
+	l, ok, err := b.partitionLog(OffsetsTopic, partition)
+	if err != nil || !ok {
+		return err
+	}
+	for offset, end := l.LogStartOffset(), l.HighWatermark(); offset < end; {
+		data, err := l.Read(offset, 1<<20, true)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			break
+		}
+		batches, err := types.ParseRecordBatches(data)
+		if err != nil {
+			return err
+		}
+		for _, batch := range batches {
+			offset = batch.LastOffset() + 1
+			if batch.IsControl() {
+				continue
+			}
+			for _, r := range batch.Records {
+				if batch.Offset(r) < l.LogStartOffset() {
+					continue
+				}
+				if err := b.Groups.Replay(r.Key, r.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type HeartbeatHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewHeartbeatHandler(broker *Broker) *HeartbeatHandler {
+	return &HeartbeatHandler{FlexibleSince: 4, broker: broker}
+}
+
+func (h *HeartbeatHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseHeartbeat(r, version)
+}
+
+// Handle keeps the session of a member alive; REBALANCE_IN_PROGRESS tells
+// it to rejoin.
+func (h *HeartbeatHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.Heartbeat)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	err := h.broker.Groups.Heartbeat(rb.GroupID, rb.MemberID, rb.GroupInstanceID, rb.GenerationID)
+	return &responses.Heartbeat{Version: rb.Version, ErrorCode: groupErrorCode(err)}, nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nabinkhanal00/kafka/app/config"
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type IncrementalAlterConfigsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewIncrementalAlterConfigsHandler(broker *Broker) *IncrementalAlterConfigsHandler {
+	return &IncrementalAlterConfigsHandler{FlexibleSince: 1, broker: broker}
+}
+
+func (h *IncrementalAlterConfigsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseIncrementalAlterConfigs(r, version)
+}
+
+func (h *IncrementalAlterConfigsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.IncrementalAlterConfigs)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.IncrementalAlterConfigs{Version: rb.Version, Responses: []responses.AlterConfigsResourceResponse{}}
+	for _, res := range rb.Resources {
+		err := h.broker.incrementalAlterConfigs(res, rb.ValidateOnly)
+		resp.Responses = append(resp.Responses, alterConfigsResponse(res.ResourceType, res.ResourceName, err))
+	}
+	return resp, nil
+}
+
+// incrementalAlterConfigs applies the operations of a resource in order.
+// APPEND and SUBTRACT start from the effective value when the resource
+// has no override, as upstream does.
+func (b *Broker) incrementalAlterConfigs(res requests.IncrementalAlterConfigsResource, validateOnly bool) error {
+	seen := make(map[string]bool, len(res.Configs))
+	for _, c := range res.Configs {
+		if seen[c.Name] {
+			return fmt.Errorf("%w: config %s is listed more than once", errInvalidRequest, c.Name)
+		}
+		seen[c.Name] = true
+	}
+	resource, err := b.configResource(res.ResourceType, res.ResourceName)
+	if err != nil {
+		return err
+	}
+	return b.alterConfigs(resource, func(current map[string]string) (map[string]string, error) {
+		for _, c := range res.Configs {
+			if err := b.applyConfigOperation(resource, current, c); err != nil {
+				return nil, err
+			}
+		}
+		return current, nil
+	}, validateOnly)
+}
+
+func (b *Broker) applyConfigOperation(resource metadata.ConfigResource, current map[string]string, c requests.IncrementalAlterableConfig) error {
+	switch c.ConfigOperation {
+	case requests.ConfigOperationDelete:
+		delete(current, c.Name)
+		return nil
+	case requests.ConfigOperationSet, requests.ConfigOperationAppend, requests.ConfigOperationSubtract:
+	default:
+		return fmt.Errorf("%w: unknown operation %d for config %s", errInvalidRequest, c.ConfigOperation, c.Name)
+	}
+	if c.Value == nil {
+		return fmt.Errorf("%w: null value for config %s", errInvalidRequest, c.Name)
+	}
+	if c.ConfigOperation == requests.ConfigOperationSet {
+		current[c.Name] = *c.Value
+		return nil
+	}
+
+	def, ok := config.Lookup(c.Name)
+	base, hasBase := current[c.Name]
+	if resource.Type == metadata.TopicResource {
+		var topicDef config.TopicDef
+		topicDef, ok = config.LookupTopic(c.Name)
+		def = topicDef.Def
+		if !hasBase && ok {
+			for _, e := range b.topicConfigEntries(current) {
+				if e.name == c.Name {
+					base = *e.value
+				}
+			}
+		}
+	} else if !hasBase && ok {
+		if base, hasBase = b.Config.Static(c.Name); !hasBase {
+			base = def.Default
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%w: unknown config %s", errInvalidConfig, c.Name)
+	}
+	if def.Type != config.List {
+		return fmt.Errorf("%w: %s is not a list and cannot be appended to or subtracted from", errInvalidConfig, c.Name)
+	}
+	items := splitConfigList(base)
+	for _, item := range splitConfigList(*c.Value) {
+		if c.ConfigOperation == requests.ConfigOperationAppend && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+		if c.ConfigOperation == requests.ConfigOperationSubtract {
+			items = slices.DeleteFunc(items, func(s string) bool { return s == item })
+		}
+	}
+	current[c.Name] = strings.Join(items, ",")
+	return nil
+}
+
+func splitConfigList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
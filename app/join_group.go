@@ -0,0 +1,75 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type JoinGroupHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewJoinGroupHandler(broker *Broker) *JoinGroupHandler {
+	return &JoinGroupHandler{FlexibleSince: 6, broker: broker}
+}
+
+func (h *JoinGroupHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseJoinGroup(r, version)
+}
+
+// Handle waits for the rebalance the member takes part in to complete,
+// which can take up to the rebalance timeout of the group.
+func (h *JoinGroupHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.JoinGroup)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	jr := group.JoinRequest{
+		GroupID:              rb.GroupID,
+		MemberID:             rb.MemberID,
+		InstanceID:           rb.GroupInstanceID,
+		ClientID:             req.Header.GetClientID(),
+		ClientHost:           req.ClientHost,
+		SessionTimeout:       time.Duration(rb.SessionTimeoutMs) * time.Millisecond,
+		RebalanceTimeout:     time.Duration(rb.RebalanceTimeoutMs) * time.Millisecond,
+		ProtocolType:         rb.ProtocolType,
+		RequireKnownMemberID: rb.Version >= 4,
+		SkipAssignment:       rb.Version >= 9,
+	}
+	for _, p := range rb.Protocols {
+		jr.Protocols = append(jr.Protocols, group.Protocol{Name: p.Name, Metadata: p.Metadata})
+	}
+	var result group.JoinResult
+	select {
+	case result = <-h.broker.Groups.Join(jr):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	resp := &responses.JoinGroup{
+		Version:        rb.Version,
+		ErrorCode:      groupErrorCode(result.Err),
+		GenerationID:   result.Generation,
+		ProtocolType:   result.ProtocolType,
+		ProtocolName:   result.ProtocolName,
+		Leader:         result.Leader,
+		SkipAssignment: result.SkipAssignment,
+		MemberID:       result.MemberID,
+		Members:        []responses.JoinGroupMember{},
+	}
+	for _, m := range result.Members {
+		resp.Members = append(resp.Members, responses.JoinGroupMember{
+			MemberID:        m.ID,
+			GroupInstanceID: m.InstanceID,
+			Metadata:        m.Metadata,
+		})
+	}
+	return resp, nil
+}
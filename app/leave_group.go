@@ -0,0 +1,59 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type LeaveGroupHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewLeaveGroupHandler(broker *Broker) *LeaveGroupHandler {
+	return &LeaveGroupHandler{FlexibleSince: 4, broker: broker}
+}
+
+func (h *LeaveGroupHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseLeaveGroup(r, version)
+}
+
+// Handle removes members from their group, which rebalances the members
+// that remain. Versions before 3 remove a single member and report its
+// error at the top level.
+func (h *LeaveGroupHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.LeaveGroup)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	members := rb.Members
+	if rb.Version < 3 {
+		members = []requests.LeaveGroupMember{{MemberID: rb.MemberID}}
+	}
+	leaving := make([]group.LeaveMember, len(members))
+	for i, m := range members {
+		leaving[i] = group.LeaveMember{ID: m.MemberID, InstanceID: m.GroupInstanceID}
+	}
+	resp := &responses.LeaveGroup{Version: rb.Version, Members: []responses.LeaveGroupMemberResult{}}
+	errs, err := h.broker.Groups.Leave(rb.GroupID, leaving)
+	if err != nil {
+		resp.ErrorCode = groupErrorCode(err)
+		return resp, nil
+	}
+	for i, m := range members {
+		resp.Members = append(resp.Members, responses.LeaveGroupMemberResult{
+			MemberID:        m.MemberID,
+			GroupInstanceID: m.GroupInstanceID,
+			ErrorCode:       groupErrorCode(errs[i]),
+		})
+	}
+	if rb.Version < 3 {
+		resp.ErrorCode = resp.Members[0].ErrorCode
+	}
+	return resp, nil
+}
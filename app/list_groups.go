@@ -0,0 +1,53 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type ListGroupsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewListGroupsHandler(broker *Broker) *ListGroupsHandler {
+	return &ListGroupsHandler{FlexibleSince: 3, broker: broker}
+}
+
+func (h *ListGroupsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseListGroups(r, version)
+}
+
+// Handle lists the groups matching the state and type filters, which
+// compare case-insensitively. An empty filter matches every group.
+func (h *ListGroupsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.ListGroups)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	listings, err := h.broker.Groups.List()
+	resp := &responses.ListGroups{Version: rb.Version, ErrorCode: groupErrorCode(err), Groups: []responses.ListedGroup{}}
+	for _, l := range listings {
+		if !matchesFilter(rb.StatesFilter, l.State.String()) || !matchesFilter(rb.TypesFilter, group.ClassicType) {
+			continue
+		}
+		resp.Groups = append(resp.Groups, responses.ListedGroup{
+			GroupID:      l.ID,
+			ProtocolType: l.ProtocolType,
+			GroupState:   l.State.String(),
+			GroupType:    group.ClassicType,
+		})
+	}
+	return resp, nil
+}
+
+func matchesFilter(filter []string, value string) bool {
+	return len(filter) == 0 || slices.ContainsFunc(filter, func(f string) bool { return strings.EqualFold(f, value) })
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+// Special timestamps of ListOffsets, with the version introducing them.
+const (
+	latestTimestamp        int64 = -1
+	earliestTimestamp      int64 = -2
+	maxTimestamp           int64 = -3 // v7
+	earliestLocalTimestamp int64 = -4 // v8
+	latestTieredTimestamp  int64 = -5 // v9
+)
+
+type ListOffsetsHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewListOffsetsHandler(broker *Broker) *ListOffsetsHandler {
+	return &ListOffsetsHandler{FlexibleSince: 6, broker: broker}
+}
+
+func (h *ListOffsetsHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseListOffsets(r, version)
+}
+
+func (h *ListOffsetsHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.ListOffsets)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.ListOffsets{Version: rb.Version, Topics: []responses.ListOffsetsTopic{}}
+	for _, topic := range rb.Topics {
+		rt := responses.ListOffsetsTopic{Name: topic.Name, Partitions: []responses.ListOffsetsPartition{}}
+		for _, p := range topic.Partitions {
+			rt.Partitions = append(rt.Partitions, h.broker.listOffset(topic.Name, p, rb.Version, rb.IsolationLevel))
+		}
+		resp.Topics = append(resp.Topics, rt)
+	}
+	return resp, nil
+}
+
+// listOffset resolves the timestamp of one partition. Lookups only see
+// records below the high watermark, or below the last stable offset for
+// read_committed callers. Without tiered storage the local log is the
+// whole log, so earliest-local is earliest and nothing is ever tiered.
+func (b *Broker) listOffset(topic string, p requests.ListOffsetsPartition, version int16, isolationLevel int8) responses.ListOffsetsPartition {
+	rp := responses.ListOffsetsPartition{
+		PartitionIndex: p.PartitionIndex,
+		Timestamp:      -1,
+		Offset:         -1,
+		LeaderEpoch:    -1,
+	}
+	l, ok, err := b.partitionLog(topic, p.PartitionIndex)
+	if err != nil {
+		rp.ErrorCode = KAFKA_STORAGE_ERROR
+		return rp
+	}
+	if !ok {
+		rp.ErrorCode = UNKNOWN_TOPIC_OR_PARTITION
+		return rp
+	}
+	epoch := b.leaderEpoch(topic, p.PartitionIndex)
+	if p.CurrentLeaderEpoch >= 0 && epoch >= 0 {
+		switch {
+		case p.CurrentLeaderEpoch < epoch:
+			rp.ErrorCode = FENCED_LEADER_EPOCH
+			return rp
+		case p.CurrentLeaderEpoch > epoch:
+			rp.ErrorCode = UNKNOWN_LEADER_EPOCH
+			return rp
+		}
+	}
+
+	fetchable := l.HighWatermark()
+	if isolationLevel == readCommitted {
+		fetchable = l.LastStableOffset()
+	}
+	var found log.TimestampOffset
+	switch {
+	case p.Timestamp == latestTimestamp:
+		found, ok = log.TimestampOffset{Timestamp: -1, Offset: fetchable}, true
+	case p.Timestamp == earliestTimestamp,
+		p.Timestamp == earliestLocalTimestamp && version >= 8:
+		found, ok = log.TimestampOffset{Timestamp: -1, Offset: l.LogStartOffset()}, true
+	case p.Timestamp == maxTimestamp && version >= 7:
+		found, ok, err = l.MaxTimestampOffset()
+	case p.Timestamp == latestTieredTimestamp && version >= 9:
+		ok = false
+	case p.Timestamp < 0:
+		rp.ErrorCode = UNSUPPORTED_VERSION
+		return rp
+	default:
+		found, ok, err = l.FindOffsetByTimestamp(p.Timestamp)
+	}
+	if err != nil {
+		rp.ErrorCode = KAFKA_STORAGE_ERROR
+		return rp
+	}
+	rp.ErrorCode = NONE
+	if ok && (found.Offset < fetchable || found.Timestamp < 0) {
+		rp.Timestamp, rp.Offset, rp.LeaderEpoch = found.Timestamp, found.Offset, epoch
+	}
+	return rp
+}
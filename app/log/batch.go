@@ -0,0 +1,164 @@
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Byte offsets of the record batch (magic v2) header fields. Only the
+// header is needed to store and index batches; records stay opaque.
+const (
+	baseOffsetOffset           = 0
+	batchLengthOffset          = 8
+	partitionLeaderEpochOffset = 12
+	magicOffset                = 16
+	crcOffset                  = 17
+	attributesOffset           = 21
+	lastOffsetDeltaOffset      = 23
+	baseTimestampOffset        = 27
+	maxTimestampOffset         = 35
+	producerIDOffset           = 43
+	producerEpochOffset        = 51
+	baseSequenceOffset         = 53
+	recordsCountOffset         = 57
+
+	// LogOverhead is the base offset plus the batch length prefix.
+	LogOverhead = 12
+	// BatchHeaderSize is the size of a v2 batch without any record.
+	BatchHeaderSize = 61
+)
+
+var (
+	ErrCorruptBatch     = errors.New("corrupt record batch")
+	ErrUnsupportedMagic = errors.New("unsupported record batch magic")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// BatchHeader is a read-only view of the fixed size batch header.
+type BatchHeader struct {
+	BaseOffset           int64
+	BatchLength          int32
+	PartitionLeaderEpoch int32
+	Magic                int8
+	CRC                  uint32
+	Attributes           int16
+	LastOffsetDelta      int32
+	BaseTimestamp        int64
+	MaxTimestamp         int64
+	ProducerID           int64
+	ProducerEpoch        int16
+	BaseSequence         int32
+	RecordsCount         int32
+}
+
+// Size is the number of bytes the batch occupies in the log.
+func (h BatchHeader) Size() int {
+	return LogOverhead + int(h.BatchLength)
+}
+
+func (h BatchHeader) LastOffset() int64 {
+	return h.BaseOffset + int64(h.LastOffsetDelta)
+}
+
+func (h BatchHeader) IsTransactional() bool {
+	return h.Attributes&0x10 != 0
+}
+
+func (h BatchHeader) IsControl() bool {
+	return h.Attributes&0x20 != 0
+}
+
+// ParseBatchHeader decodes the header at the start of b. It does not
+// require the whole batch to be present.
+func ParseBatchHeader(b []byte) (BatchHeader, error) {
+	if len(b) < LogOverhead {
+		return BatchHeader{}, fmt.Errorf("%w: %d bytes is shorter than the log overhead", ErrCorruptBatch, len(b))
+	}
+	h := BatchHeader{
+		BaseOffset:  int64(binary.BigEndian.Uint64(b[baseOffsetOffset:])),
+		BatchLength: int32(binary.BigEndian.Uint32(b[batchLengthOffset:])),
+	}
+	if h.BatchLength < BatchHeaderSize-LogOverhead {
+		return h, fmt.Errorf("%w: batch length %d is below the minimum", ErrCorruptBatch, h.BatchLength)
+	}
+	if len(b) < BatchHeaderSize {
+		return h, fmt.Errorf("%w: truncated batch header", ErrCorruptBatch)
+	}
+	h.PartitionLeaderEpoch = int32(binary.BigEndian.Uint32(b[partitionLeaderEpochOffset:]))
+	h.Magic = int8(b[magicOffset])
+	h.CRC = binary.BigEndian.Uint32(b[crcOffset:])
+	h.Attributes = int16(binary.BigEndian.Uint16(b[attributesOffset:]))
+	h.LastOffsetDelta = int32(binary.BigEndian.Uint32(b[lastOffsetDeltaOffset:]))
+	h.BaseTimestamp = int64(binary.BigEndian.Uint64(b[baseTimestampOffset:]))
+	h.MaxTimestamp = int64(binary.BigEndian.Uint64(b[maxTimestampOffset:]))
+	h.ProducerID = int64(binary.BigEndian.Uint64(b[producerIDOffset:]))
+	h.ProducerEpoch = int16(binary.BigEndian.Uint16(b[producerEpochOffset:]))
+	h.BaseSequence = int32(binary.BigEndian.Uint32(b[baseSequenceOffset:]))
+	h.RecordsCount = int32(binary.BigEndian.Uint32(b[recordsCountOffset:]))
+	if h.Magic != 2 {
+		return h, fmt.Errorf("%w: %d", ErrUnsupportedMagic, h.Magic)
+	}
+	return h, nil
+}
+
+// ValidateBatch checks that b holds exactly one complete batch whose
+// CRC-32C, computed from the attributes to the end, matches the header.
+func ValidateBatch(b []byte) (BatchHeader, error) {
+	h, err := ParseBatchHeader(b)
+	if err != nil {
+		return h, err
+	}
+	if h.Size() != len(b) {
+		return h, fmt.Errorf("%w: batch length %d does not match %d available bytes", ErrCorruptBatch, h.Size(), len(b))
+	}
+	if crc := crc32.Checksum(b[attributesOffset:], castagnoli); crc != h.CRC {
+		return h, fmt.Errorf("%w: crc mismatch, expected %08x got %08x", ErrCorruptBatch, h.CRC, crc)
+	}
+	if h.LastOffsetDelta < 0 || h.RecordsCount < 0 {
+		return h, fmt.Errorf("%w: negative record count", ErrCorruptBatch)
+	}
+	return h, nil
+}
+
+// SplitBatches walks a record set and returns every batch in it. The
+// returned slices alias records.
+func SplitBatches(records []byte) ([][]byte, error) {
+	var batches [][]byte
+	for len(records) > 0 {
+		h, err := ParseBatchHeader(records)
+		if err != nil {
+			return nil, err
+		}
+		if h.Size() > len(records) {
+			return nil, fmt.Errorf("%w: batch of %d bytes truncated to %d", ErrCorruptBatch, h.Size(), len(records))
+		}
+		batches = append(batches, records[:h.Size()])
+		records = records[h.Size():]
+	}
+	return batches, nil
+}
+
+// setBaseOffset rewrites the base offset of a batch in place. The base
+// offset is not covered by the CRC.
+func setBaseOffset(b []byte, offset int64) {
+	binary.BigEndian.PutUint64(b[baseOffsetOffset:], uint64(offset))
+}
+
+// SetPartitionLeaderEpoch rewrites the leader epoch in place, which is also
+// outside the CRC.
+func SetPartitionLeaderEpoch(b []byte, epoch int32) {
+	binary.BigEndian.PutUint32(b[partitionLeaderEpochOffset:], uint32(epoch))
+}
+
+// SetLogAppendTime stamps the batch with the broker's append time: the
+// timestamp type attribute is set, max timestamp overwritten and the CRC
+// recomputed.
+func SetLogAppendTime(b []byte, timestamp int64) {
+	attrs := binary.BigEndian.Uint16(b[attributesOffset:]) | 0x08
+	binary.BigEndian.PutUint16(b[attributesOffset:], attrs)
+	binary.BigEndian.PutUint64(b[maxTimestampOffset:], uint64(timestamp))
+	binary.BigEndian.PutUint32(b[crcOffset:], crc32.Checksum(b[attributesOffset:], castagnoli))
+}
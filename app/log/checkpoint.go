@@ -0,0 +1,96 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogStartOffsetCheckpointFile records, per log directory, the log start
+// offsets that DeleteRecords moved past the first segment, since those
+// cannot be derived from the segment files after a restart.
+const LogStartOffsetCheckpointFile = "log-start-offset-checkpoint"
+
+// readOffsetCheckpoint parses the upstream checkpoint format: a version
+// line, an entry count and one "topic partition offset" line per entry.
+// A missing file is empty.
+func readOffsetCheckpoint(path string) (map[TopicPartition]int64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[0]) != "0" {
+		return nil, fmt.Errorf("%s: unsupported checkpoint", path)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(lines[1]))
+	if err != nil || count != len(lines)-2 {
+		return nil, fmt.Errorf("%s: expected %s entries, found %d", path, lines[1], len(lines)-2)
+	}
+	offsets := make(map[TopicPartition]int64, count)
+	for _, line := range lines[2:] {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s: malformed entry %q", path, line)
+		}
+		partition, err := strconv.ParseInt(fields[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s: malformed entry %q", path, line)
+		}
+		offset, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: malformed entry %q", path, line)
+		}
+		offsets[TopicPartition{Topic: fields[0], Partition: int32(partition)}] = offset
+	}
+	return offsets, nil
+}
+
+func writeOffsetCheckpoint(path string, offsets map[TopicPartition]int64) error {
+	tps := make([]TopicPartition, 0, len(offsets))
+	for tp := range offsets {
+		tps = append(tps, tp)
+	}
+	sort.Slice(tps, func(i, j int) bool { return tps[i].String() < tps[j].String() })
+	var b strings.Builder
+	fmt.Fprintf(&b, "0\n%d\n", len(tps))
+	for _, tp := range tps {
+		fmt.Fprintf(&b, "%s %d %d\n", tp.Topic, tp.Partition, offsets[tp])
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// CheckpointLogStartOffsets writes the log start offsets that lie past the
+// first segment of their log to every log directory.
+func (m *Manager) CheckpointLogStartOffsets() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	byDir := make(map[string]map[TopicPartition]int64, len(m.dirs))
+	for _, dir := range m.dirs {
+		byDir[dir] = make(map[TopicPartition]int64)
+	}
+	for tp, l := range m.logs {
+		l.mu.RLock()
+		offset, first := l.logStartOffset, l.segments[0].BaseOffset
+		l.mu.RUnlock()
+		if offset > first {
+			byDir[filepath.Dir(l.Dir)][tp] = offset
+		}
+	}
+	var err error
+	for dir, offsets := range byDir {
+		err = errors.Join(err, writeOffsetCheckpoint(filepath.Join(dir, LogStartOffsetCheckpointFile), offsets))
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package log
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOffsetCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogStartOffsetCheckpointFile)
+	if got, err := readOffsetCheckpoint(path); err != nil || got != nil {
+		t.Fatalf("missing checkpoint = %v, %v; want empty", got, err)
+	}
+	want := map[TopicPartition]int64{{"orders", 0}: 7, {"orders", 1}: 3, {"audit", 12}: 100}
+	if err := writeOffsetCheckpoint(path, want); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "0\n3\naudit 12 100\norders 0 7\norders 1 3\n" {
+		t.Fatalf("checkpoint file:\n%s", got)
+	}
+	got, err := readOffsetCheckpoint(path)
+	if err != nil || !maps.Equal(got, want) {
+		t.Fatalf("readOffsetCheckpoint = %v, %v; want %v", got, err, want)
+	}
+
+	for _, bad := range []string{"1\n0\n", "0\n2\norders 0 7\n", "0\n1\norders 0\n", "0\n1\norders x 7\n"} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readOffsetCheckpoint(path); err == nil {
+			t.Fatalf("readOffsetCheckpoint accepted %q", bad)
+		}
+	}
+}
+
+func openManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+	m := NewManager([]string{dir}, DefaultConfig)
+	if err := m.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestRestoreLogStartOffset(t *testing.T) {
+	dir := t.TempDir()
+	m := openManager(t, dir)
+	orders, kept := TopicPartition{"orders", 0}, TopicPartition{"orders", 1}
+	for _, tp := range []TopicPartition{orders, kept} {
+		l, err := m.GetOrCreate(tp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appendBatches(t, l, batch(t, 1, 2, 3), batch(t, 4))
+	}
+	l, _ := m.Get(orders)
+	if _, err := l.DeleteRecordsBefore(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CheckpointLogStartOffsets(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m = openManager(t, dir)
+	for tp, want := range map[TopicPartition]int64{orders: 2, kept: 0} {
+		l, ok := m.Get(tp)
+		if !ok {
+			t.Fatalf("%s not loaded", tp)
+		}
+		if got := l.LogStartOffset(); got != want {
+			t.Fatalf("%s: LogStartOffset after reopening = %d, want %d", tp, got, want)
+		}
+	}
+
+	// A checkpointed offset past the end of the log is ignored.
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, LogStartOffsetCheckpointFile)
+	if err := writeOffsetCheckpoint(path, map[TopicPartition]int64{orders: 100}); err != nil {
+		t.Fatal(err)
+	}
+	m = openManager(t, dir)
+	if l, _ := m.Get(orders); l.LogStartOffset() != 0 {
+		t.Fatalf("LogStartOffset = %d from a checkpoint past the end, want 0", l.LogStartOffset())
+	}
+}
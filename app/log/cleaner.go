@@ -0,0 +1,346 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// cleanable returns the segments a cleaning pass covers, from the start of
+// the log to the first segment that cannot be cleaned yet, and the offset
+// the dirty part starts at. The active segment is never cleaned. It
+// reports false when the log does not need cleaning: not enough of it is
+// dirty and no tombstone or marker is due for removal.
+func (l *Log) cleanable(now int64) ([]*Segment, int64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	c := l.config
+	if !c.Compact {
+		return nil, 0, false
+	}
+	firstDirty := max(l.firstDirtyOffset, l.logStartOffset)
+	var segments []*Segment
+	var cleanBytes, dirtyBytes int64
+	overdue := false
+	for _, s := range l.segments[:len(l.segments)-1] {
+		if s.nextOffset <= firstDirty {
+			cleanBytes += s.size
+			segments = append(segments, s)
+			continue
+		}
+		if now-s.largestTimestamp() < c.MinCompactionLagMs {
+			break
+		}
+		if s.firstTimestamp >= 0 && now-s.firstTimestamp > c.MaxCompactionLagMs {
+			overdue = true
+		}
+		dirtyBytes += s.size
+		segments = append(segments, s)
+	}
+	if dirtyBytes == 0 {
+		due := l.latestDeleteHorizon > 0 && l.latestDeleteHorizon <= now
+		return segments, firstDirty, due && len(segments) > 0
+	}
+	ratio := float64(dirtyBytes) / float64(cleanBytes+dirtyBytes)
+	return segments, firstDirty, overdue || ratio >= c.MinCleanableDirtyRatio
+}
+
+// Clean compacts the log when cleanup.policy includes compact: only the
+// latest record of every key survives, tombstones and transaction markers
+// are dropped delete.retention.ms after the first cleaning that kept them,
+// and aborted transactional data is removed. Offsets are preserved, so
+// cleaned segments simply have gaps. It returns the number of bytes
+// reclaimed.
+func (l *Log) Clean(now time.Time) (int64, error) {
+	segments, firstDirty, ok := l.cleanable(now.UnixMilli())
+	if !ok {
+		return 0, nil
+	}
+	c := newCleaner(l.Config(), now.UnixMilli())
+	for _, s := range segments {
+		if err := s.forEachBatch(c.scanTransactions); err != nil {
+			return 0, err
+		}
+	}
+	for _, s := range segments {
+		if s.nextOffset <= firstDirty {
+			continue
+		}
+		err := s.forEachBatch(func(data []byte, h BatchHeader) error {
+			return c.mapOffsets(data, h, firstDirty)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var reclaimed int64
+	for _, s := range segments {
+		cleaned, err := c.cleanSegment(s)
+		if err != nil {
+			return reclaimed, err
+		}
+		if err := l.replaceSegment(s, cleaned); err != nil {
+			return reclaimed, err
+		}
+		reclaimed += s.size - cleaned.size
+	}
+	l.mu.Lock()
+	l.firstDirtyOffset = max(l.firstDirtyOffset, segments[len(segments)-1].nextOffset)
+	l.latestDeleteHorizon = c.latestDeleteHorizon
+	l.mu.Unlock()
+	return reclaimed, nil
+}
+
+// replaceSegment swaps old for its cleaned copy. The cleaned files are
+// renamed to *.swap before the old ones are marked deleted, so a restart
+// at any point ends up with exactly one of them.
+func (l *Log) replaceSegment(old, cleaned *Segment) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := slices.Index(l.segments, old)
+	if i < 0 {
+		// Deleted by retention while it was being cleaned.
+		return cleaned.delete()
+	}
+	if cleaned.size == 0 {
+		l.segments = slices.Delete(l.segments, i, i+1)
+		if err := errors.Join(cleaned.delete(), old.markDeleted()); err != nil {
+			return err
+		}
+	} else {
+		if err := cleaned.rename(SwapFileSuffix); err != nil {
+			return err
+		}
+		if err := old.markDeleted(); err != nil {
+			return err
+		}
+		if err := cleaned.rename(""); err != nil {
+			return err
+		}
+		l.segments[i] = cleaned
+	}
+	time.AfterFunc(time.Duration(l.config.FileDeleteDelayMs)*time.Millisecond, func() { old.delete() })
+	return nil
+}
+
+type offsetRange struct {
+	first, last int64
+}
+
+// cleaner holds the state of one cleaning pass over a log.
+type cleaner struct {
+	config Config
+	now    int64
+	// offsets maps every key of the dirty part to its latest offset.
+	offsets map[string]int64
+	// aborted lists the offset ranges of aborted transactions by producer
+	// and open the first offset of transactions without a marker yet.
+	aborted map[int64][]offsetRange
+	open    map[int64]int64
+	// retained tells whether the current transaction of a producer still
+	// has data, in which case its marker must stay.
+	retained map[int64]bool
+	// latestDeleteHorizon is the largest delete horizon of the batches
+	// kept.
+	latestDeleteHorizon int64
+}
+
+func newCleaner(config Config, now int64) *cleaner {
+	return &cleaner{
+		config:   config,
+		now:      now,
+		offsets:  make(map[string]int64),
+		aborted:  make(map[int64][]offsetRange),
+		open:     make(map[int64]int64),
+		retained: make(map[int64]bool),
+	}
+}
+
+// scanTransactions records which transactions were aborted. Transactions
+// still open at the end of the pass are left untouched.
+func (c *cleaner) scanTransactions(data []byte, h BatchHeader) error {
+	if !h.IsTransactional() {
+		return nil
+	}
+	if !h.IsControl() {
+		if _, ok := c.open[h.ProducerID]; !ok {
+			c.open[h.ProducerID] = h.BaseOffset
+		}
+		return nil
+	}
+	batch, err := types.DecodeRecordBatch(data)
+	if err != nil || len(batch.Records) == 0 {
+		return err
+	}
+	typ, err := types.ControlType(batch.Records[0])
+	if err != nil {
+		return err
+	}
+	if first, ok := c.open[h.ProducerID]; ok && typ == types.ControlTypeAbort {
+		c.aborted[h.ProducerID] = append(c.aborted[h.ProducerID], offsetRange{first, h.LastOffset()})
+	}
+	delete(c.open, h.ProducerID)
+	return nil
+}
+
+func (c *cleaner) isAborted(h BatchHeader) bool {
+	for _, r := range c.aborted[h.ProducerID] {
+		if h.BaseOffset >= r.first && h.BaseOffset <= r.last {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpen reports whether h belongs to a transaction without a marker.
+func (c *cleaner) isOpen(h BatchHeader) bool {
+	first, ok := c.open[h.ProducerID]
+	return h.IsTransactional() && ok && h.BaseOffset >= first
+}
+
+func (c *cleaner) mapOffsets(data []byte, h BatchHeader, firstDirty int64) error {
+	if h.IsControl() || h.LastOffset() < firstDirty || (h.IsTransactional() && (c.isAborted(h) || c.isOpen(h))) {
+		return nil
+	}
+	batch, err := types.DecodeRecordBatch(data)
+	if err != nil {
+		return err
+	}
+	for _, r := range batch.Records {
+		if offset := batch.Offset(r); r.Key != nil && offset >= firstDirty {
+			c.offsets[string(r.Key)] = offset
+		}
+	}
+	return nil
+}
+
+// cleanSegment writes the records of s that survive to a new segment with
+// the same base offset.
+func (c *cleaner) cleanSegment(s *Segment) (*Segment, error) {
+	prefix := filepath.Join(s.dir, SegmentName(s.BaseOffset))
+	for _, name := range segmentFileSuffixes {
+		os.Remove(prefix + name + CleanedFileSuffix)
+	}
+	cleaned, err := openSegmentFiles(s.dir, s.BaseOffset, CleanedFileSuffix, s.indexIntervalBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = s.forEachBatch(func(data []byte, h BatchHeader) error {
+		out, err := c.filterBatch(data, h)
+		if err != nil || out == nil {
+			return err
+		}
+		oh, err := ParseBatchHeader(out)
+		if err != nil {
+			return err
+		}
+		return cleaned.append(out, oh)
+	})
+	if err == nil {
+		err = cleaned.onBecomeInactive()
+	}
+	if err != nil {
+		return nil, errors.Join(err, cleaned.delete())
+	}
+	cleaned.nextOffset = s.nextOffset
+	return cleaned, nil
+}
+
+// filterBatch returns what remains of a batch, nil when nothing does. The
+// batch is copied as is unless records were removed or a delete horizon
+// had to be set; re-encoding keeps the base offset and last offset delta.
+func (c *cleaner) filterBatch(data []byte, h BatchHeader) ([]byte, error) {
+	horizon, hasHorizon := deleteHorizon(h)
+	if h.IsControl() {
+		if c.retained[h.ProducerID] {
+			delete(c.retained, h.ProducerID)
+			return data, nil
+		}
+		if hasHorizon {
+			if c.now >= horizon {
+				return nil, nil
+			}
+			c.latestDeleteHorizon = max(c.latestDeleteHorizon, horizon)
+			return data, nil
+		}
+		return c.withDeleteHorizon(data, nil)
+	}
+	if h.IsTransactional() && c.isAborted(h) {
+		return nil, nil
+	}
+	if h.IsTransactional() && c.isOpen(h) {
+		return data, nil
+	}
+
+	batch, err := types.DecodeRecordBatch(data)
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]types.Record, 0, len(batch.Records))
+	tombstones := false
+	for _, r := range batch.Records {
+		if r.Key != nil {
+			if latest, ok := c.offsets[string(r.Key)]; ok && batch.Offset(r) < latest {
+				continue
+			}
+			if r.Value == nil {
+				if hasHorizon && c.now >= horizon {
+					continue
+				}
+				tombstones = true
+			}
+		}
+		kept = append(kept, r)
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+	if h.IsTransactional() {
+		c.retained[h.ProducerID] = true
+	}
+	if tombstones && !hasHorizon {
+		return c.withDeleteHorizon(data, kept)
+	}
+	if tombstones {
+		c.latestDeleteHorizon = max(c.latestDeleteHorizon, horizon)
+	}
+	if len(kept) == len(batch.Records) {
+		return data, nil
+	}
+	batch.Records = kept
+	return batch.Encode()
+}
+
+// withDeleteHorizon stamps the batch with the time after which its
+// tombstones or marker may be removed. The horizon replaces the base
+// timestamp, so record timestamp deltas are rebased onto it. A nil records
+// keeps every record.
+func (c *cleaner) withDeleteHorizon(data []byte, records []types.Record) ([]byte, error) {
+	batch, err := types.DecodeRecordBatch(data)
+	if err != nil {
+		return nil, err
+	}
+	if records != nil {
+		batch.Records = records
+	}
+	horizon := c.now + c.config.DeleteRetentionMs
+	c.latestDeleteHorizon = max(c.latestDeleteHorizon, horizon)
+	for i, r := range batch.Records {
+		batch.Records[i].TimestampDelta = batch.BaseTimestamp + r.TimestampDelta - horizon
+	}
+	batch.BaseTimestamp = horizon
+	batch.Attributes |= types.DeleteHorizonFlagMask
+	return batch.Encode()
+}
+
+func deleteHorizon(h BatchHeader) (int64, bool) {
+	if h.Attributes&types.DeleteHorizonFlagMask == 0 {
+		return 0, false
+	}
+	return h.BaseTimestamp, true
+}
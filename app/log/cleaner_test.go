@@ -0,0 +1,196 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// compactConfig keeps every batch in a segment of its own, so that all
+// but the last one appended can be cleaned.
+var compactConfig = func() Config {
+	c := DefaultConfig
+	c.SegmentBytes = 120
+	c.Delete, c.Compact = false, true
+	c.MinCleanableDirtyRatio = 0
+	c.MinCompactionLagMs = 0
+	c.DeleteRetentionMs = 1000
+	c.FileDeleteDelayMs = time.Hour.Milliseconds()
+	return c
+}()
+
+// cleanerStart is the time records are written at.
+const cleanerStart = 10_000
+
+// keyed is a record as the tests see it: a nil value is a tombstone and a
+// control record reports its marker type as the value.
+type keyed struct {
+	offset int64
+	key    string
+	value  string
+}
+
+func record(key, value string) types.Record {
+	r := types.Record{Key: []byte(key)}
+	if value != "" {
+		r.Value = []byte(value)
+	}
+	return r
+}
+
+func appendRecords(t *testing.T, l *Log, producerID int64, attributes int16, records ...types.Record) {
+	t.Helper()
+	timestamps := make([]int64, len(records))
+	for i := range timestamps {
+		timestamps[i] = cleanerStart
+	}
+	b := types.NewRecordBatch(0, timestamps, records)
+	b.Attributes = attributes
+	if producerID >= 0 {
+		b.ProducerID, b.ProducerEpoch, b.BaseSequence = producerID, 0, 0
+	}
+	data, err := b.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	appendBatches(t, l, data)
+}
+
+func appendMarker(t *testing.T, l *Log, producerID int64, controlType int16) {
+	t.Helper()
+	appendRecords(t, l, producerID, types.TransactionalFlagMask|types.ControlFlagMask, types.Record{
+		Key:   []byte{0, 0, 0, byte(controlType)},
+		Value: []byte{0, 0, 0, 0, 0, 0},
+	})
+}
+
+func cleanAt(t *testing.T, l *Log, now int64) {
+	t.Helper()
+	if _, err := l.Clean(time.UnixMilli(now)); err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+}
+
+func keyedRecords(t *testing.T, l *Log) []keyed {
+	t.Helper()
+	var out []keyed
+	for _, data := range readAll(t, l) {
+		batch, err := types.DecodeRecordBatch(data)
+		if err != nil {
+			t.Fatalf("DecodeRecordBatch: %v", err)
+		}
+		for _, r := range batch.Records {
+			k := keyed{offset: batch.Offset(r), key: string(r.Key), value: string(r.Value)}
+			if batch.IsControl() {
+				typ, err := types.ControlType(r)
+				if err != nil {
+					t.Fatalf("ControlType: %v", err)
+				}
+				k.key, k.value = "marker", map[int16]string{types.ControlTypeAbort: "abort", types.ControlTypeCommit: "commit"}[typ]
+			}
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func TestCleanKeepsLatestRecords(t *testing.T) {
+	l := openLog(t, compactConfig)
+	appendRecords(t, l, -1, 0, record("a", "1"))
+	appendRecords(t, l, -1, 0, record("b", "1"))
+	appendRecords(t, l, -1, 0, record("a", "2"), types.Record{Value: []byte("unkeyed")})
+	appendRecords(t, l, -1, 0, record("b", ""))
+	appendRecords(t, l, -1, 0, record("c", "1"))
+
+	cleanAt(t, l, cleanerStart)
+	want := []keyed{{2, "a", "2"}, {3, "", "unkeyed"}, {4, "b", ""}, {5, "c", "1"}}
+	if got := keyedRecords(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after cleaning:\n got  %v\n want %v", got, want)
+	}
+	if got := l.LogEndOffset(); got != 6 {
+		t.Fatalf("LogEndOffset = %d, want 6", got)
+	}
+
+	// The tombstone stays until delete.retention.ms after that cleaning.
+	cleanAt(t, l, cleanerStart+compactConfig.DeleteRetentionMs-1)
+	if got := keyedRecords(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("before the delete horizon:\n got  %v\n want %v", got, want)
+	}
+	cleanAt(t, l, cleanerStart+compactConfig.DeleteRetentionMs)
+	want = []keyed{{2, "a", "2"}, {3, "", "unkeyed"}, {5, "c", "1"}}
+	if got := keyedRecords(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after the delete horizon:\n got  %v\n want %v", got, want)
+	}
+}
+
+func TestCleanTransactions(t *testing.T) {
+	l := openLog(t, compactConfig)
+	const aborted, committed, open = 7, 8, 9
+	txn := types.TransactionalFlagMask
+	appendRecords(t, l, aborted, txn, record("a", "aborted"))
+	appendRecords(t, l, committed, txn, record("b", "committed"))
+	appendMarker(t, l, aborted, types.ControlTypeAbort)
+	appendMarker(t, l, committed, types.ControlTypeCommit)
+	appendRecords(t, l, open, txn, record("c", "open"))
+	// Does not shadow the open transaction's record, which may still be
+	// aborted.
+	appendRecords(t, l, -1, 0, record("c", "plain"))
+	appendRecords(t, l, -1, 0, record("d", "1"))
+
+	cleanAt(t, l, cleanerStart)
+	want := []keyed{
+		{1, "b", "committed"},
+		{2, "marker", "abort"},
+		{3, "marker", "commit"},
+		{4, "c", "open"},
+		{5, "c", "plain"},
+		{6, "d", "1"},
+	}
+	if got := keyedRecords(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after cleaning:\n got  %v\n want %v", got, want)
+	}
+
+	// The abort marker has nothing left to cover and goes once its delete
+	// horizon passes; the commit marker stays with its data.
+	cleanAt(t, l, cleanerStart+compactConfig.DeleteRetentionMs)
+	want = append(want[:1:1], want[2:]...)
+	if got := keyedRecords(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after the delete horizon:\n got  %v\n want %v", got, want)
+	}
+}
+
+func TestCleanRequiresCompaction(t *testing.T) {
+	config := compactConfig
+	config.Compact = false
+	l := openLog(t, config)
+	appendRecords(t, l, -1, 0, record("a", "1"))
+	appendRecords(t, l, -1, 0, record("a", "2"))
+	appendRecords(t, l, -1, 0, record("b", "1"))
+	if reclaimed, err := l.Clean(time.UnixMilli(cleanerStart)); reclaimed != 0 || err != nil {
+		t.Fatalf("Clean = %d, %v; want nothing cleaned", reclaimed, err)
+	}
+	if got := len(keyedRecords(t, l)); got != 3 {
+		t.Fatalf("%d records left, want 3", got)
+	}
+}
+
+func TestCleanMinCompactionLag(t *testing.T) {
+	config := compactConfig
+	config.MinCompactionLagMs = 5000
+	l := openLog(t, config)
+	appendRecords(t, l, -1, 0, record("a", "1"))
+	appendRecords(t, l, -1, 0, record("a", "2"))
+	appendRecords(t, l, -1, 0, record("b", "1"))
+
+	cleanAt(t, l, cleanerStart+config.MinCompactionLagMs-1)
+	if got := len(keyedRecords(t, l)); got != 3 {
+		t.Fatalf("%d records left within the compaction lag, want 3", got)
+	}
+	cleanAt(t, l, cleanerStart+config.MinCompactionLagMs)
+	want := []keyed{{1, "a", "2"}, {2, "b", "1"}}
+	if got := keyedRecords(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after the compaction lag:\n got  %v\n want %v", got, want)
+	}
+}
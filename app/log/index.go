@@ -0,0 +1,167 @@
+package log
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	offsetIndexEntrySize = 8
+	timeIndexEntrySize   = 12
+)
+
+type offsetEntry struct {
+	RelativeOffset int32
+	Position       int32
+}
+
+type timeEntry struct {
+	Timestamp      int64
+	RelativeOffset int32
+}
+
+// offsetIndex is the sparse offset -> file position index of a segment.
+// Entries are kept in memory and appended to the .index file.
+type offsetIndex struct {
+	file    *os.File
+	entries []offsetEntry
+}
+
+func openOffsetIndex(path string) (*offsetIndex, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	idx := &offsetIndex{file: f}
+	for i := 0; i+offsetIndexEntrySize <= len(data); i += offsetIndexEntrySize {
+		e := offsetEntry{
+			RelativeOffset: int32(binary.BigEndian.Uint32(data[i:])),
+			Position:       int32(binary.BigEndian.Uint32(data[i+4:])),
+		}
+		if n := len(idx.entries); n > 0 && (e.RelativeOffset <= idx.entries[n-1].RelativeOffset || e.Position <= idx.entries[n-1].Position) {
+			break
+		}
+		idx.entries = append(idx.entries, e)
+	}
+	if len(idx.entries)*offsetIndexEntrySize != len(data) {
+		if err := idx.truncateEntries(len(idx.entries)); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+func (idx *offsetIndex) append(e offsetEntry) error {
+	var buf [offsetIndexEntrySize]byte
+	binary.BigEndian.PutUint32(buf[0:], uint32(e.RelativeOffset))
+	binary.BigEndian.PutUint32(buf[4:], uint32(e.Position))
+	if _, err := idx.file.WriteAt(buf[:], int64(len(idx.entries)*offsetIndexEntrySize)); err != nil {
+		return err
+	}
+	idx.entries = append(idx.entries, e)
+	return nil
+}
+
+// lookup returns the position of the last entry whose offset is not above
+// relativeOffset, or 0 when there is none.
+func (idx *offsetIndex) lookup(relativeOffset int32) int32 {
+	i := sort.Search(len(idx.entries), func(i int) bool {
+		return idx.entries[i].RelativeOffset > relativeOffset
+	})
+	if i == 0 {
+		return 0
+	}
+	return idx.entries[i-1].Position
+}
+
+func (idx *offsetIndex) truncateEntries(n int) error {
+	idx.entries = idx.entries[:n]
+	return idx.file.Truncate(int64(n * offsetIndexEntrySize))
+}
+
+func (idx *offsetIndex) len() int {
+	return len(idx.entries)
+}
+
+// timeIndex maps timestamps to the offset of the batch first reaching them.
+type timeIndex struct {
+	file    *os.File
+	entries []timeEntry
+}
+
+func openTimeIndex(path string) (*timeIndex, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	idx := &timeIndex{file: f}
+	for i := 0; i+timeIndexEntrySize <= len(data); i += timeIndexEntrySize {
+		e := timeEntry{
+			Timestamp:      int64(binary.BigEndian.Uint64(data[i:])),
+			RelativeOffset: int32(binary.BigEndian.Uint32(data[i+8:])),
+		}
+		if n := len(idx.entries); n > 0 && (e.Timestamp <= idx.entries[n-1].Timestamp || e.RelativeOffset < idx.entries[n-1].RelativeOffset) {
+			break
+		}
+		idx.entries = append(idx.entries, e)
+	}
+	if len(idx.entries)*timeIndexEntrySize != len(data) {
+		if err := idx.truncateEntries(len(idx.entries)); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+// maybeAppend adds e unless its timestamp does not move the index forward.
+func (idx *timeIndex) maybeAppend(e timeEntry) error {
+	if n := len(idx.entries); n > 0 && e.Timestamp <= idx.entries[n-1].Timestamp {
+		return nil
+	}
+	var buf [timeIndexEntrySize]byte
+	binary.BigEndian.PutUint64(buf[0:], uint64(e.Timestamp))
+	binary.BigEndian.PutUint32(buf[8:], uint32(e.RelativeOffset))
+	if _, err := idx.file.WriteAt(buf[:], int64(len(idx.entries)*timeIndexEntrySize)); err != nil {
+		return err
+	}
+	idx.entries = append(idx.entries, e)
+	return nil
+}
+
+// lookup returns the relative offset of the last entry whose timestamp is
+// below timestamp, from which a scan for timestamp can start.
+func (idx *timeIndex) lookup(timestamp int64) int32 {
+	i := sort.Search(len(idx.entries), func(i int) bool {
+		return idx.entries[i].Timestamp >= timestamp
+	})
+	if i == 0 {
+		return 0
+	}
+	return idx.entries[i-1].RelativeOffset
+}
+
+func (idx *timeIndex) last() (timeEntry, bool) {
+	if len(idx.entries) == 0 {
+		return timeEntry{}, false
+	}
+	return idx.entries[len(idx.entries)-1], true
+}
+
+func (idx *timeIndex) truncateEntries(n int) error {
+	idx.entries = idx.entries[:n]
+	return idx.file.Truncate(int64(n * timeIndexEntrySize))
+}
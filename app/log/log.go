@@ -0,0 +1,577 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+var (
+	ErrOffsetOutOfRange = errors.New("offset out of range")
+	ErrRecordTooLarge   = errors.New("record batch larger than the segment size")
+)
+
+// Config holds the per-log settings.
+type Config struct {
+	SegmentBytes       int32
+	SegmentMs          int64
+	IndexIntervalBytes int32
+	MaxIndexBytes      int32
+	// RetentionMs and RetentionBytes bound the log when Delete is set;
+	// -1 means unlimited.
+	RetentionMs    int64
+	RetentionBytes int64
+	// Delete and Compact mirror cleanup.policy; both may be set.
+	Delete  bool
+	Compact bool
+	// DeleteRetentionMs is how long the cleaner keeps tombstones and
+	// transaction markers after it first saw them.
+	DeleteRetentionMs int64
+	// A log is cleaned once the dirty part is at least
+	// MinCleanableDirtyRatio of it, or when dirty records are older than
+	// MaxCompactionLagMs. Records younger than MinCompactionLagMs are
+	// never cleaned.
+	MinCleanableDirtyRatio float64
+	MinCompactionLagMs     int64
+	MaxCompactionLagMs     int64
+	// FileDeleteDelayMs is how long the files of a deleted segment are
+	// kept, renamed to *.deleted, before they are removed.
+	FileDeleteDelayMs int64
+}
+
+func (c Config) maxIndexEntries() int {
+	return int(c.MaxIndexBytes) / offsetIndexEntrySize
+}
+
+// DefaultConfig matches the upstream broker defaults.
+var DefaultConfig = Config{
+	SegmentBytes:           1024 * 1024 * 1024,
+	SegmentMs:              7 * 24 * 60 * 60 * 1000,
+	IndexIntervalBytes:     4096,
+	MaxIndexBytes:          10 * 1024 * 1024,
+	RetentionMs:            7 * 24 * 60 * 60 * 1000,
+	RetentionBytes:         -1,
+	Delete:                 true,
+	DeleteRetentionMs:      24 * 60 * 60 * 1000,
+	MinCleanableDirtyRatio: 0.5,
+	MaxCompactionLagMs:     math.MaxInt64,
+	FileDeleteDelayMs:      60 * 1000,
+}
+
+// TimestampOffset locates a record found by timestamp.
+type TimestampOffset struct {
+	Timestamp int64
+	Offset    int64
+}
+
+// AppendInfo describes the offsets assigned to an append.
+type AppendInfo struct {
+	FirstOffset  int64
+	LastOffset   int64
+	MaxTimestamp int64
+}
+
+// Log is the append-only log of a single partition, stored as a sequence
+// of segments under <dir>/<topic>-<partition>.
+type Log struct {
+	Dir       string
+	Topic     string
+	Partition int32
+
+	mu             sync.RWMutex
+	config         Config
+	segments       []*Segment
+	logStartOffset int64
+	// firstDirtyOffset is where the last cleaning stopped; records below
+	// it are already compacted. It is not persisted, so the first
+	// cleaning after a restart covers the whole log again.
+	firstDirtyOffset int64
+	// latestDeleteHorizon is the last delete horizon the cleaner stamped
+	// or kept, 0 if none; once it passes the log is cleaned again even
+	// without dirty data.
+	latestDeleteHorizon int64
+	topicID             types.UUID
+	// appended is closed and replaced after every append so that readers
+	// waiting for new data can block on it.
+	appended chan struct{}
+}
+
+// ParseDirName splits a partition directory name such as "foo-0".
+func ParseDirName(name string) (string, int32, error) {
+	i := strings.LastIndexByte(name, '-')
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid partition directory name %q", name)
+	}
+	partition, err := strconv.ParseInt(name[i+1:], 10, 32)
+	if err != nil || partition < 0 {
+		return "", 0, fmt.Errorf("invalid partition directory name %q", name)
+	}
+	return name[:i], int32(partition), nil
+}
+
+// DirName is the inverse of ParseDirName.
+func DirName(topic string, partition int32) string {
+	return topic + "-" + strconv.Itoa(int(partition))
+}
+
+// Open loads the log in dir, creating it if needed. The active segment is
+// recovered, dropping any torn write at its tail.
+func Open(dir string, config Config) (*Log, error) {
+	topic, partition, err := ParseDirName(filepath.Base(dir))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	l := &Log{Dir: dir, Topic: topic, Partition: partition, config: config, appended: make(chan struct{})}
+	if err := l.loadSegments(); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if l.topicID, err = readPartitionMetadata(dir); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) loadSegments() error {
+	if err := l.completeSwaps(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(l.Dir)
+	if err != nil {
+		return err
+	}
+	var baseOffsets []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, DeletedFileSuffix) || strings.HasSuffix(name, CleanedFileSuffix) {
+			os.Remove(filepath.Join(l.Dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, LogFileSuffix) {
+			continue
+		}
+		baseOffset, err := strconv.ParseInt(strings.TrimSuffix(name, LogFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		baseOffsets = append(baseOffsets, baseOffset)
+	}
+	sort.Slice(baseOffsets, func(i, j int) bool { return baseOffsets[i] < baseOffsets[j] })
+	for _, baseOffset := range baseOffsets {
+		s, err := openSegment(l.Dir, baseOffset, l.config.IndexIntervalBytes)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, s)
+	}
+	if len(l.segments) == 0 {
+		s, err := openSegment(l.Dir, 0, l.config.IndexIntervalBytes)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, s)
+	}
+	for _, s := range l.segments[:len(l.segments)-1] {
+		if err := s.loadState(); err != nil {
+			// An index pointing at garbage; rebuild it from the data.
+			if _, err := s.recover(); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := l.active().recover(); err != nil {
+		return err
+	}
+	l.logStartOffset = l.segments[0].BaseOffset
+	return nil
+}
+
+// completeSwaps finishes a cleaning interrupted by a restart: once the
+// cleaned files were renamed to *.swap they replace the originals.
+func (l *Log) completeSwaps() error {
+	entries, err := os.ReadDir(l.Dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, SwapFileSuffix) {
+			path := filepath.Join(l.Dir, name)
+			if err := os.Rename(path, strings.TrimSuffix(path, SwapFileSuffix)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Log) active() *Segment {
+	return l.segments[len(l.segments)-1]
+}
+
+// Config returns the settings the log currently runs with.
+func (l *Log) Config() Config {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.config
+}
+
+// SetConfig replaces the settings; they apply from the next append.
+func (l *Log) SetConfig(config Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+}
+
+func (l *Log) LogStartOffset() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.logStartOffset
+}
+
+// LogEndOffset is the offset the next appended record will get.
+func (l *Log) LogEndOffset() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.active().nextOffset
+}
+
+// HighWatermark equals the log end offset: there is no replication, so
+// every appended record is committed.
+func (l *Log) HighWatermark() int64 {
+	return l.LogEndOffset()
+}
+
+// LastStableOffset equals the high watermark: transactions are not
+// tracked, so no open transaction ever holds it back.
+func (l *Log) LastStableOffset() int64 {
+	return l.HighWatermark()
+}
+
+// Size is the total number of bytes of all segments.
+func (l *Log) Size() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var size int64
+	for _, s := range l.segments {
+		size += s.size
+	}
+	return size
+}
+
+// Append writes every batch of records, assigning consecutive offsets
+// starting at the log end offset. records must already be validated. The
+// append is all or nothing: every batch is checked before the first is
+// written, and a failed write truncates the log back to where it ended.
+func (l *Log) Append(records []byte) (AppendInfo, error) {
+	batches, err := SplitBatches(records)
+	if err != nil {
+		return AppendInfo{}, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info := AppendInfo{FirstOffset: l.active().nextOffset, MaxTimestamp: -1}
+	headers := make([]BatchHeader, len(batches))
+	offset := info.FirstOffset
+	for i, batch := range batches {
+		if len(batch) > int(l.config.SegmentBytes) {
+			return info, fmt.Errorf("%w: %d > %d", ErrRecordTooLarge, len(batch), l.config.SegmentBytes)
+		}
+		setBaseOffset(batch, offset)
+		if headers[i], err = ParseBatchHeader(batch); err != nil {
+			return info, err
+		}
+		offset = headers[i].LastOffset() + 1
+	}
+	segments := len(l.segments)
+	for i, batch := range batches {
+		if err := l.appendBatch(batch, headers[i]); err != nil {
+			if truncErr := l.truncateAppend(segments, info.FirstOffset); truncErr != nil {
+				return info, errors.Join(err, truncErr)
+			}
+			return info, err
+		}
+		info.LastOffset = headers[i].LastOffset()
+		info.MaxTimestamp = max(info.MaxTimestamp, headers[i].MaxTimestamp)
+	}
+	close(l.appended)
+	l.appended = make(chan struct{})
+	return info, nil
+}
+
+func (l *Log) appendBatch(batch []byte, h BatchHeader) error {
+	if l.shouldRoll(len(batch), h) {
+		if err := l.roll(h.BaseOffset); err != nil {
+			return err
+		}
+	}
+	return l.active().append(batch, h)
+}
+
+// truncateAppend undoes a failed append: it drops the segments rolled
+// since the log had n of them and truncates the one that was active to
+// offset.
+func (l *Log) truncateAppend(n int, offset int64) error {
+	var err error
+	for _, s := range l.segments[n:] {
+		err = errors.Join(err, s.delete())
+	}
+	l.segments = l.segments[:n]
+	return errors.Join(err, l.active().truncateTo(offset))
+}
+
+// Appended returns a channel that is closed by the next append.
+func (l *Log) Appended() <-chan struct{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.appended
+}
+
+// TopicID is the id recorded in partition.metadata, zero if unknown.
+func (l *Log) TopicID() types.UUID {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.topicID
+}
+
+// SetTopicID records the topic id in partition.metadata.
+func (l *Log) SetTopicID(id types.UUID) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.topicID == id {
+		return nil
+	}
+	if err := writePartitionMetadata(l.Dir, id); err != nil {
+		return err
+	}
+	l.topicID = id
+	return nil
+}
+
+func (l *Log) shouldRoll(size int, h BatchHeader) bool {
+	s := l.active()
+	if s.size == 0 {
+		return false
+	}
+	if s.size+int64(size) > int64(l.config.SegmentBytes) {
+		return true
+	}
+	if s.index.len() >= l.config.maxIndexEntries() {
+		return true
+	}
+	if !s.canHold(h.LastOffset()) {
+		return true
+	}
+	// Like upstream, time is measured in record timestamps once the
+	// segment has one and in wall clock time since creation otherwise.
+	if s.firstTimestamp >= 0 {
+		return h.MaxTimestamp-s.firstTimestamp > l.config.SegmentMs
+	}
+	return time.Now().UnixMilli()-s.created.UnixMilli() > l.config.SegmentMs
+}
+
+// roll closes the active segment for appends and starts a new one at
+// baseOffset.
+func (l *Log) roll(baseOffset int64) error {
+	if err := l.active().onBecomeInactive(); err != nil {
+		return err
+	}
+	s, err := openSegment(l.Dir, baseOffset, l.config.IndexIntervalBytes)
+	if err != nil {
+		return err
+	}
+	l.segments = append(l.segments, s)
+	return nil
+}
+
+// Read returns whole batches starting at the batch containing offset,
+// using at most maxBytes unless minOneBatch is set and the first batch
+// alone is larger. Reading at the log end offset returns no data.
+func (l *Log) Read(offset int64, maxBytes int, minOneBatch bool) ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	end := l.active().nextOffset
+	if offset < l.logStartOffset || offset > end {
+		return nil, fmt.Errorf("%w: %d not in [%d, %d]", ErrOffsetOutOfRange, offset, l.logStartOffset, end)
+	}
+	i := sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].BaseOffset > offset
+	})
+	if i > 0 {
+		i--
+	}
+	for ; i < len(l.segments); i++ {
+		data, err := l.segments[i].read(offset, maxBytes, minOneBatch)
+		if err != nil || data != nil {
+			return data, err
+		}
+	}
+	return nil, nil
+}
+
+// FindOffsetByTimestamp returns the first record whose timestamp is at
+// least timestamp, and false if no record has reached it yet. Segments are
+// skipped by their max timestamp and searched from their time index.
+func (l *Log) FindOffsetByTimestamp(timestamp int64) (TimestampOffset, bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, s := range l.segments {
+		if s.maxTimestamp < timestamp || s.nextOffset <= l.logStartOffset {
+			continue
+		}
+		found, ok, err := s.findTimestamp(timestamp, l.logStartOffset)
+		if err != nil || ok {
+			return found, ok, err
+		}
+	}
+	return TimestampOffset{}, false, nil
+}
+
+// MaxTimestampOffset returns the record with the largest timestamp, the
+// earliest one if several share it, and false if the log has no records.
+func (l *Log) MaxTimestampOffset() (TimestampOffset, bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var target *Segment
+	for _, s := range l.segments {
+		if s.nextOffset > l.logStartOffset && (target == nil || s.maxTimestamp > target.maxTimestamp) {
+			target = s
+		}
+	}
+	if target == nil || target.maxTimestamp < 0 {
+		return TimestampOffset{}, false, nil
+	}
+	return target.findTimestamp(target.maxTimestamp, l.logStartOffset)
+}
+
+// DeleteOldSegments drops the oldest segments that breach retention.ms or
+// retention.bytes when the cleanup policy includes delete, and advances
+// the log start offset past them. An active segment that has expired is
+// rolled first so that the log always keeps one. It returns the number of
+// segments deleted.
+func (l *Log) DeleteOldSegments() (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.config.Delete {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	var size int64
+	for _, s := range l.segments {
+		size += s.size
+	}
+	n := 0
+	for _, s := range l.segments {
+		if s.size == 0 {
+			break
+		}
+		expired := l.config.RetentionMs >= 0 && now-s.largestTimestamp() > l.config.RetentionMs
+		oversized := l.config.RetentionBytes >= 0 && size-s.size >= l.config.RetentionBytes
+		if !expired && !oversized {
+			break
+		}
+		size -= s.size
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if n == len(l.segments) {
+		if err := l.roll(l.active().nextOffset); err != nil {
+			return 0, err
+		}
+	}
+	return n, l.deleteSegments(n)
+}
+
+// DeleteRecordsBefore advances the log start offset to offset, -1 meaning
+// the high watermark, and deletes the segments that lie entirely below
+// it. Offsets below the current log start offset change nothing. It
+// returns the new log start offset.
+func (l *Log) DeleteRecordsBefore(offset int64) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	hw := l.active().nextOffset
+	if offset == -1 {
+		offset = hw
+	}
+	if offset < 0 || offset > hw {
+		return l.logStartOffset, fmt.Errorf("%w: %d not in [%d, %d]", ErrOffsetOutOfRange, offset, l.logStartOffset, hw)
+	}
+	if offset <= l.logStartOffset {
+		return l.logStartOffset, nil
+	}
+	l.logStartOffset = offset
+	n := 0
+	for n < len(l.segments)-1 && l.segments[n+1].BaseOffset <= offset {
+		n++
+	}
+	return l.logStartOffset, l.deleteSegments(n)
+}
+
+// restoreLogStartOffset applies a checkpointed log start offset that is
+// still within the log.
+func (l *Log) restoreLogStartOffset(offset int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if offset > l.logStartOffset && offset <= l.active().nextOffset {
+		l.logStartOffset = offset
+	}
+}
+
+// deleteSegments drops the n oldest segments, never the active one, and
+// advances the log start offset past them.
+func (l *Log) deleteSegments(n int) error {
+	deleted := l.segments[:n]
+	l.segments = append([]*Segment(nil), l.segments[n:]...)
+	l.logStartOffset = max(l.logStartOffset, l.segments[0].BaseOffset)
+
+	var err error
+	delay := time.Duration(l.config.FileDeleteDelayMs) * time.Millisecond
+	for _, s := range deleted {
+		if markErr := s.markDeleted(); markErr != nil {
+			err = errors.Join(err, markErr)
+			continue
+		}
+		time.AfterFunc(delay, func() { s.delete() })
+	}
+	return err
+}
+
+// Segments returns a snapshot of the segment list, oldest first.
+func (l *Log) Segments() []*Segment {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]*Segment(nil), l.segments...)
+}
+
+// Flush syncs the active segment to disk.
+func (l *Log) Flush() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.active().flush()
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var err error
+	for _, s := range l.segments {
+		err = errors.Join(err, s.close())
+	}
+	return err
+}
@@ -0,0 +1,413 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// openLog opens a log for partition 0 of topic "t" in a fresh directory.
+func openLog(t *testing.T, config Config) *Log {
+	t.Helper()
+	l, err := Open(filepath.Join(t.TempDir(), DirName("t", 0)), config)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func reopen(t *testing.T, l *Log) *Log {
+	t.Helper()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	l, err := Open(l.Dir, l.Config())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// batch encodes a batch with one record per timestamp.
+func batch(t *testing.T, timestamps ...int64) []byte {
+	t.Helper()
+	records := make([]types.Record, len(timestamps))
+	for i := range records {
+		records[i] = types.Record{Key: []byte{byte(i)}, Value: []byte("value")}
+	}
+	data, err := types.NewRecordBatch(0, timestamps, records).Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return data
+}
+
+func appendBatches(t *testing.T, l *Log, batches ...[]byte) AppendInfo {
+	t.Helper()
+	info, err := l.Append(bytes.Join(batches, nil))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	return info
+}
+
+func readAll(t *testing.T, l *Log) [][]byte {
+	t.Helper()
+	var batches [][]byte
+	// A read stops at the end of a segment.
+	for offset := l.LogStartOffset(); offset < l.LogEndOffset(); {
+		data, err := l.Read(offset, 1<<20, true)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		read, err := SplitBatches(data)
+		if err != nil || len(read) == 0 {
+			t.Fatalf("SplitBatches at %d = %d batches, %v", offset, len(read), err)
+		}
+		for _, b := range read {
+			h, err := ParseBatchHeader(b)
+			if err != nil {
+				t.Fatalf("ParseBatchHeader: %v", err)
+			}
+			offset = h.LastOffset() + 1
+		}
+		batches = append(batches, read...)
+	}
+	return batches
+}
+
+func TestAppendRead(t *testing.T) {
+	l := openLog(t, DefaultConfig)
+	info := appendBatches(t, l, batch(t, 10, 30), batch(t, 20))
+	if info.FirstOffset != 0 || info.LastOffset != 2 || info.MaxTimestamp != 30 {
+		t.Fatalf("Append = %+v, want offsets 0-2 and max timestamp 30", info)
+	}
+	info = appendBatches(t, l, batch(t, 40))
+	if info.FirstOffset != 3 || info.LastOffset != 3 {
+		t.Fatalf("second Append = %+v, want offset 3", info)
+	}
+	if got := l.LogEndOffset(); got != 4 {
+		t.Fatalf("LogEndOffset = %d, want 4", got)
+	}
+
+	batches := readAll(t, l)
+	if len(batches) != 3 {
+		t.Fatalf("read %d batches, want 3", len(batches))
+	}
+	for i, want := range []int64{0, 2, 3} {
+		h, err := ParseBatchHeader(batches[i])
+		if err != nil || h.BaseOffset != want {
+			t.Fatalf("batch %d: base offset %d, %v; want %d", i, h.BaseOffset, err, want)
+		}
+	}
+	if data, err := l.Read(4, 1<<20, true); err != nil || len(data) != 0 {
+		t.Fatalf("Read at the end = %d bytes, %v; want none", len(data), err)
+	}
+}
+
+func TestAppendRolls(t *testing.T) {
+	one := batch(t, 1)
+	config := DefaultConfig
+	config.SegmentBytes = int32(2*len(one) + 1)
+	l := openLog(t, config)
+	for range 5 {
+		appendBatches(t, l, one)
+	}
+	if got := len(l.Segments()); got != 3 {
+		t.Fatalf("%d segments, want 3", got)
+	}
+	if got := len(readAll(t, l)); got != 5 {
+		t.Fatalf("read %d batches, want 5", got)
+	}
+	if _, err := l.Append(make([]byte, config.SegmentBytes+1)); err == nil {
+		t.Fatal("Append accepted a batch larger than a segment")
+	}
+}
+
+func TestAppendAllOrNothing(t *testing.T) {
+	first := batch(t, 1)
+	config := DefaultConfig
+	config.SegmentBytes = int32(len(first) + 1)
+	l := openLog(t, config)
+	appendBatches(t, l, first)
+
+	// The second batch of the append has to roll onto a segment at offset
+	// 2, whose file cannot be created.
+	blocker := filepath.Join(l.Dir, SegmentName(2)+LogFileSuffix)
+	if err := os.Mkdir(blocker, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(append(batch(t, 2), batch(t, 3)...)); err == nil {
+		t.Fatal("Append succeeded without its segment")
+	}
+	if got := l.LogEndOffset(); got != 1 {
+		t.Fatalf("LogEndOffset after a failed append = %d, want 1", got)
+	}
+	if got := len(readAll(t, l)); got != 1 {
+		t.Fatalf("read %d batches after a failed append, want 1", got)
+	}
+
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	info := appendBatches(t, l, batch(t, 2), batch(t, 3))
+	if info.FirstOffset != 1 || info.LastOffset != 2 {
+		t.Fatalf("Append after the failure = %+v, want offsets 1-2", info)
+	}
+	l = reopen(t, l)
+	if got := len(readAll(t, l)); got != 3 {
+		t.Fatalf("read %d batches after reopening, want 3", got)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	config := DefaultConfig
+	config.SegmentBytes = int32(len(batch(t, 1)) + 1)
+	l := openLog(t, config)
+	appendBatches(t, l, batch(t, 100))
+	appendBatches(t, l, batch(t, 200))
+	appendBatches(t, l, batch(t, 300))
+
+	l = reopen(t, l)
+	if got := l.LogEndOffset(); got != 3 {
+		t.Fatalf("LogEndOffset = %d, want 3", got)
+	}
+	if got := len(l.Segments()); got != 3 {
+		t.Fatalf("%d segments after reopening, want 3", got)
+	}
+	found, ok, err := l.FindOffsetByTimestamp(150)
+	if err != nil || !ok || found.Offset != 1 || found.Timestamp != 200 {
+		t.Fatalf("FindOffsetByTimestamp(150) = %+v, %v, %v; want offset 1 at 200", found, ok, err)
+	}
+	if info := appendBatches(t, l, batch(t, 400)); info.FirstOffset != 3 {
+		t.Fatalf("Append after reopening starts at %d, want 3", info.FirstOffset)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name string
+		// damage changes the active segment file holding two batches,
+		// the second of which starts at second.
+		damage func(t *testing.T, path string, second int64)
+	}{
+		{"torn write", func(t *testing.T, path string, second int64) {
+			if err := os.Truncate(path, second+10); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"corrupt batch", func(t *testing.T, path string, second int64) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data[len(data)-1] ^= 0xff
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"trailing garbage", func(t *testing.T, path string, second int64) {
+			if err := os.Truncate(path, second); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if _, err := f.Write(bytes.Repeat([]byte{0xff}, 100)); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := openLog(t, DefaultConfig)
+			first := batch(t, 1, 2)
+			appendBatches(t, l, first)
+			appendBatches(t, l, batch(t, 3))
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+			tt.damage(t, filepath.Join(l.Dir, SegmentName(0)+LogFileSuffix), int64(len(first)))
+
+			l, err := Open(l.Dir, DefaultConfig)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer l.Close()
+			if got := l.LogEndOffset(); got != 2 {
+				t.Fatalf("LogEndOffset = %d, want 2", got)
+			}
+			if got := l.Size(); got != int64(len(first)) {
+				t.Fatalf("Size = %d, want %d", got, len(first))
+			}
+			if info := appendBatches(t, l, batch(t, 4)); info.FirstOffset != 2 {
+				t.Fatalf("Append after recovery starts at %d, want 2", info.FirstOffset)
+			}
+			if got := len(readAll(t, l)); got != 2 {
+				t.Fatalf("read %d batches, want 2", got)
+			}
+		})
+	}
+}
+
+func TestDeleteOldSegments(t *testing.T) {
+	now := time.Now().UnixMilli()
+	old := now - time.Hour.Milliseconds()
+	size := int64(len(batch(t, now)))
+	tests := []struct {
+		name       string
+		config     func(c *Config)
+		timestamps []int64
+		// deleted segments; each of the five batches has its own.
+		deleted int
+	}{
+		{"retention.ms", func(c *Config) { c.RetentionMs = 60_000 }, []int64{old, old, now, now, now}, 2},
+		{"retention.ms stops at a retained segment", func(c *Config) { c.RetentionMs = 60_000 }, []int64{old, now, old, now, now}, 1},
+		{"retention.bytes", func(c *Config) { c.RetentionMs = -1; c.RetentionBytes = 2 * size }, []int64{old, old, now, now, now}, 3},
+		{"retention.bytes leaves one byte more", func(c *Config) { c.RetentionMs = -1; c.RetentionBytes = 2*size + 1 }, []int64{now, now, now, now, now}, 2},
+		{"expired active segment", func(c *Config) { c.RetentionMs = 60_000 }, []int64{old, old, old, old, old}, 5},
+		{"delete disabled", func(c *Config) { c.Delete = false; c.RetentionMs = 0; c.RetentionBytes = 0 }, []int64{old, old, old, old, old}, 0},
+		{"unlimited", func(c *Config) { c.RetentionMs = -1; c.RetentionBytes = -1 }, []int64{old, old, old, old, old}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig
+			config.SegmentBytes = int32(size + 1)
+			tt.config(&config)
+			l := openLog(t, config)
+			for _, ts := range tt.timestamps {
+				appendBatches(t, l, batch(t, ts))
+			}
+			n, err := l.DeleteOldSegments()
+			if err != nil || n != tt.deleted {
+				t.Fatalf("DeleteOldSegments = %d, %v; want %d", n, err, tt.deleted)
+			}
+			if got := l.LogStartOffset(); got != int64(tt.deleted) {
+				t.Fatalf("LogStartOffset = %d, want %d", got, tt.deleted)
+			}
+			if got := l.LogEndOffset(); got != 5 {
+				t.Fatalf("LogEndOffset = %d, want 5", got)
+			}
+			// An expired active segment is rolled rather than dropped.
+			if got, want := len(l.Segments()), max(5-tt.deleted, 1); got != want {
+				t.Fatalf("%d segments, want %d", got, want)
+			}
+			if got := len(readAll(t, l)); got != 5-tt.deleted {
+				t.Fatalf("read %d batches, want %d", got, 5-tt.deleted)
+			}
+			if info := appendBatches(t, l, batch(t, now)); info.FirstOffset != 5 {
+				t.Fatalf("Append after deleting starts at %d, want 5", info.FirstOffset)
+			}
+		})
+	}
+}
+
+func TestDeleteOldSegmentsFiles(t *testing.T) {
+	config := DefaultConfig
+	config.SegmentBytes = int32(len(batch(t, 1)) + 1)
+	config.RetentionMs = 60_000
+	config.FileDeleteDelayMs = 100
+	l := openLog(t, config)
+	appendBatches(t, l, batch(t, 1))
+	appendBatches(t, l, batch(t, time.Now().UnixMilli()))
+	if n, err := l.DeleteOldSegments(); err != nil || n != 1 {
+		t.Fatalf("DeleteOldSegments = %d, %v; want 1", n, err)
+	}
+
+	prefix := filepath.Join(l.Dir, SegmentName(0))
+	for _, suffix := range segmentFileSuffixes {
+		if _, err := os.Stat(prefix + suffix); !os.IsNotExist(err) {
+			t.Fatalf("%s still present: %v", suffix, err)
+		}
+		if _, err := os.Stat(prefix + suffix + DeletedFileSuffix); err != nil {
+			t.Fatalf("%s not renamed: %v", suffix, err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for _, suffix := range segmentFileSuffixes {
+		for {
+			_, err := os.Stat(prefix + suffix + DeletedFileSuffix)
+			if os.IsNotExist(err) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s%s not removed after file.delete.delay.ms: %v", suffix, DeletedFileSuffix, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(l.Dir, SegmentName(1)+LogFileSuffix)); err != nil {
+		t.Fatalf("retained segment: %v", err)
+	}
+}
+
+func TestDeleteRecordsBefore(t *testing.T) {
+	tests := []struct {
+		name string
+		// before moves the log start offset first, when not zero.
+		before, offset int64
+		want           int64
+		segments       int
+		err            error
+	}{
+		{"high watermark", 0, -1, 5, 1, nil},
+		{"segment boundary", 0, 3, 3, 2, nil},
+		{"end of a segment", 0, 1, 1, 4, nil},
+		{"past the high watermark", 0, 6, 0, 5, ErrOffsetOutOfRange},
+		{"negative", 0, -2, 0, 5, ErrOffsetOutOfRange},
+		{"below the log start offset", 3, 2, 3, 2, nil},
+		{"at the log start offset", 3, 3, 3, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig
+			config.SegmentBytes = int32(len(batch(t, 1)) + 1)
+			l := openLog(t, config)
+			for ts := range int64(5) {
+				appendBatches(t, l, batch(t, ts))
+			}
+			if tt.before > 0 {
+				if _, err := l.DeleteRecordsBefore(tt.before); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := l.DeleteRecordsBefore(tt.offset)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("DeleteRecordsBefore(%d) = %d, %v; want %d, %v", tt.offset, got, err, tt.want, tt.err)
+			}
+			if got := l.LogStartOffset(); got != tt.want {
+				t.Fatalf("LogStartOffset = %d, want %d", got, tt.want)
+			}
+			if got := len(l.Segments()); got != tt.segments {
+				t.Fatalf("%d segments, want %d", got, tt.segments)
+			}
+			if got := len(readAll(t, l)); got != int(5-tt.want) {
+				t.Fatalf("read %d batches, want %d", got, 5-tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteRecordsBeforeWithinSegment(t *testing.T) {
+	l := openLog(t, DefaultConfig)
+	appendBatches(t, l, batch(t, 1, 2), batch(t, 3, 4))
+	if got, err := l.DeleteRecordsBefore(1); err != nil || got != 1 {
+		t.Fatalf("DeleteRecordsBefore(1) = %d, %v; want 1", got, err)
+	}
+	if got := len(l.Segments()); got != 1 {
+		t.Fatalf("%d segments, want the active one", got)
+	}
+	// The first batch still holds offset 1.
+	if got := len(readAll(t, l)); got != 2 {
+		t.Fatalf("read %d batches, want 2", got)
+	}
+}
@@ -0,0 +1,309 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// MetadataTopic is the KRaft metadata log, which lives next to the data
+// logs but is not managed by the Manager.
+const MetadataTopic = "__cluster_metadata"
+
+// DeleteDirSuffix marks partition directories scheduled for deletion.
+const DeleteDirSuffix = "-delete"
+
+type TopicPartition struct {
+	Topic     string
+	Partition int32
+}
+
+func (tp TopicPartition) String() string {
+	return DirName(tp.Topic, tp.Partition)
+}
+
+// Manager owns every partition log stored under the configured log.dirs.
+type Manager struct {
+	dirs   []string
+	config Config
+	// topicConfig, when set, supplies the settings of a topic's logs in
+	// place of config.
+	topicConfig func(topic string) Config
+
+	mu   sync.RWMutex
+	logs map[TopicPartition]*Log
+	// deleting tracks directories being removed in the background.
+	deleting sync.WaitGroup
+}
+
+func NewManager(dirs []string, config Config) *Manager {
+	return &Manager{dirs: dirs, config: config, logs: make(map[TopicPartition]*Log)}
+}
+
+// Dirs returns the log directories.
+func (m *Manager) Dirs() []string {
+	return m.dirs
+}
+
+// DefaultConfig is the configuration new logs start with.
+func (m *Manager) DefaultConfig() Config {
+	return m.config
+}
+
+// SetTopicConfig makes f supply the settings of every log opened from now
+// on; Reconfigure applies it to open logs.
+func (m *Manager) SetTopicConfig(f func(topic string) Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topicConfig = f
+}
+
+func (m *Manager) configOf(topic string) Config {
+	if m.topicConfig == nil {
+		return m.config
+	}
+	return m.topicConfig(topic)
+}
+
+// Reconfigure reapplies the settings of topic to its open logs, or of
+// every topic when topic is empty.
+func (m *Manager) Reconfigure(topic string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	configs := make(map[string]Config)
+	for tp, l := range m.logs {
+		if topic != "" && tp.Topic != topic {
+			continue
+		}
+		c, ok := configs[tp.Topic]
+		if !ok {
+			c = m.configOf(tp.Topic)
+			configs[tp.Topic] = c
+		}
+		l.SetConfig(c)
+	}
+}
+
+// Load opens every partition directory found in the log directories.
+func (m *Manager) Load() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, dir := range m.dirs {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		startOffsets, err := readOffsetCheckpoint(filepath.Join(dir, LogStartOffsetCheckpointFile))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if strings.HasSuffix(entry.Name(), DeleteDirSuffix) {
+				// A deletion interrupted by a restart.
+				m.removeAsync(filepath.Join(dir, entry.Name()))
+				continue
+			}
+			topic, partition, err := ParseDirName(entry.Name())
+			if err != nil || topic == MetadataTopic {
+				continue
+			}
+			tp := TopicPartition{Topic: topic, Partition: partition}
+			if _, ok := m.logs[tp]; ok {
+				return fmt.Errorf("duplicate log directories for %s", tp)
+			}
+			l, err := Open(filepath.Join(dir, entry.Name()), m.configOf(topic))
+			if err != nil {
+				return fmt.Errorf("cannot load %s: %w", tp, err)
+			}
+			if offset, ok := startOffsets[tp]; ok {
+				l.restoreLogStartOffset(offset)
+			}
+			m.logs[tp] = l
+		}
+	}
+	return nil
+}
+
+func (m *Manager) Get(tp TopicPartition) (*Log, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	l, ok := m.logs[tp]
+	return l, ok
+}
+
+// GetOrCreate returns the log for tp, creating it in the log directory
+// holding the fewest partitions when it does not exist yet.
+func (m *Manager) GetOrCreate(tp TopicPartition) (*Log, error) {
+	if l, ok := m.Get(tp); ok {
+		return l, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.logs[tp]; ok {
+		return l, nil
+	}
+	l, err := Open(filepath.Join(m.leastLoadedDir(), tp.String()), m.configOf(tp.Topic))
+	if err != nil {
+		return nil, err
+	}
+	m.logs[tp] = l
+	return l, nil
+}
+
+// Delete closes the log of tp and removes its directory. Like upstream,
+// the directory is first renamed to <topic>-<partition>.<id>-delete so a
+// new log for tp can be created at once, and its files are then removed
+// in the background.
+func (m *Manager) Delete(tp TopicPartition) error {
+	m.mu.Lock()
+	l, ok := m.logs[tp]
+	delete(m.logs, tp)
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	err := l.Close()
+	id := types.NewUUID()
+	target := fmt.Sprintf("%s.%x%s", l.Dir, id[:], DeleteDirSuffix)
+	if renameErr := os.Rename(l.Dir, target); renameErr != nil {
+		return errors.Join(err, renameErr)
+	}
+	m.removeAsync(target)
+	// Drop the checkpointed start offset so a recreated topic starts
+	// from scratch.
+	return errors.Join(err, m.CheckpointLogStartOffsets())
+}
+
+func (m *Manager) removeAsync(dir string) {
+	m.deleting.Add(1)
+	go func() {
+		defer m.deleting.Done()
+		os.RemoveAll(dir)
+	}()
+}
+
+func (m *Manager) leastLoadedDir() string {
+	counts := make(map[string]int, len(m.dirs))
+	for _, l := range m.logs {
+		counts[filepath.Dir(l.Dir)]++
+	}
+	best := m.dirs[0]
+	for _, dir := range m.dirs[1:] {
+		if counts[dir] < counts[best] {
+			best = dir
+		}
+	}
+	return best
+}
+
+// Partitions lists every managed partition, sorted.
+func (m *Manager) Partitions() []TopicPartition {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tps := make([]TopicPartition, 0, len(m.logs))
+	for tp := range m.logs {
+		tps = append(tps, tp)
+	}
+	sort.Slice(tps, func(i, j int) bool {
+		if tps[i].Topic != tps[j].Topic {
+			return tps[i].Topic < tps[j].Topic
+		}
+		return tps[i].Partition < tps[j].Partition
+	})
+	return tps
+}
+
+// DeleteOldSegments applies retention to every log and returns the
+// number of segments deleted.
+func (m *Manager) DeleteOldSegments() (int, error) {
+	m.mu.RLock()
+	logs := make([]*Log, 0, len(m.logs))
+	for _, l := range m.logs {
+		logs = append(logs, l)
+	}
+	m.mu.RUnlock()
+	var total int
+	var err error
+	for _, l := range logs {
+		n, deleteErr := l.DeleteOldSegments()
+		total += n
+		if deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("%s: %w", DirName(l.Topic, l.Partition), deleteErr))
+		}
+	}
+	return total, err
+}
+
+// Clean compacts every log that needs it on at most threads goroutines
+// and returns the number of bytes reclaimed.
+func (m *Manager) Clean(threads int) (int64, error) {
+	m.mu.RLock()
+	logs := make(chan *Log, len(m.logs))
+	for _, l := range m.logs {
+		logs <- l
+	}
+	m.mu.RUnlock()
+	close(logs)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		reclaimed int64
+		err       error
+	)
+	for range threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := range logs {
+				n, cleanErr := l.Clean(time.Now())
+				mu.Lock()
+				reclaimed += n
+				if cleanErr != nil {
+					err = errors.Join(err, fmt.Errorf("%s: %w", DirName(l.Topic, l.Partition), cleanErr))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return reclaimed, err
+}
+
+// Close closes every log and waits for pending directory deletions.
+func (m *Manager) Close() error {
+	m.deleting.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var err error
+	for _, l := range m.logs {
+		err = errors.Join(err, l.Close())
+	}
+	return err
+}
+
+// TopicName resolves a topic id through the partition.metadata of the
+// managed logs.
+func (m *Manager) TopicName(id types.UUID) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for tp, l := range m.logs {
+		if l.TopicID() == id {
+			return tp.Topic, true
+		}
+	}
+	return "", false
+}
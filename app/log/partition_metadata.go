@@ -0,0 +1,53 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// PartitionMetadataFile stores the topic id of a partition directory so
+// that a recreated topic with the same name is never confused with the
+// old one.
+const PartitionMetadataFile = "partition.metadata"
+
+func readPartitionMetadata(dir string) (types.UUID, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PartitionMetadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return types.UUID{}, nil
+	}
+	if err != nil {
+		return types.UUID{}, err
+	}
+	var version, topicID string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "version":
+			version = strings.TrimSpace(value)
+		case "topic_id":
+			topicID = strings.TrimSpace(value)
+		}
+	}
+	if version != "0" {
+		return types.UUID{}, fmt.Errorf("%s: unsupported version %q", PartitionMetadataFile, version)
+	}
+	return types.ParseUUIDString(topicID)
+}
+
+func writePartitionMetadata(dir string, id types.UUID) error {
+	path := filepath.Join(dir, PartitionMetadataFile)
+	tmp := path + ".tmp"
+	data := fmt.Sprintf("version: 0\ntopic_id: %s\n", id)
+	if err := os.WriteFile(tmp, []byte(data), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,454 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+const (
+	LogFileSuffix       = ".log"
+	IndexFileSuffix     = ".index"
+	TimeIndexFileSuffix = ".timeindex"
+	DeletedFileSuffix   = ".deleted"
+	// CleanedFileSuffix marks a segment being written by the cleaner and
+	// SwapFileSuffix one that is complete and about to replace the
+	// segment it was cleaned from.
+	CleanedFileSuffix = ".cleaned"
+	SwapFileSuffix    = ".swap"
+)
+
+// SegmentName returns the file name prefix for a segment starting at
+// baseOffset, e.g. 00000000000000000000.
+func SegmentName(baseOffset int64) string {
+	return fmt.Sprintf("%020d", baseOffset)
+}
+
+// Segment is one .log file together with its .index and .timeindex.
+type Segment struct {
+	BaseOffset int64
+
+	dir       string
+	file      *os.File
+	index     *offsetIndex
+	timeIndex *timeIndex
+
+	size                 int64
+	nextOffset           int64
+	bytesSinceIndexEntry int64
+	maxTimestamp         int64
+	offsetOfMaxTimestamp int64
+	firstTimestamp       int64
+	created              time.Time
+	indexIntervalBytes   int32
+	// suffix follows the name of every file of the segment while it is
+	// being cleaned or deleted.
+	suffix string
+}
+
+func openSegment(dir string, baseOffset int64, indexIntervalBytes int32) (*Segment, error) {
+	return openSegmentFiles(dir, baseOffset, "", indexIntervalBytes)
+}
+
+// openSegmentFiles opens the segment files whose names end in suffix.
+func openSegmentFiles(dir string, baseOffset int64, suffix string, indexIntervalBytes int32) (*Segment, error) {
+	prefix := filepath.Join(dir, SegmentName(baseOffset))
+	f, err := os.OpenFile(prefix+LogFileSuffix+suffix, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	index, err := openOffsetIndex(prefix + IndexFileSuffix + suffix)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	timeIndex, err := openTimeIndex(prefix + TimeIndexFileSuffix + suffix)
+	if err != nil {
+		f.Close()
+		index.file.Close()
+		return nil, err
+	}
+	return &Segment{
+		BaseOffset:           baseOffset,
+		dir:                  dir,
+		file:                 f,
+		index:                index,
+		timeIndex:            timeIndex,
+		size:                 info.Size(),
+		nextOffset:           baseOffset,
+		maxTimestamp:         -1,
+		offsetOfMaxTimestamp: -1,
+		firstTimestamp:       -1,
+		created:              info.ModTime(),
+		indexIntervalBytes:   indexIntervalBytes,
+		suffix:               suffix,
+	}, nil
+}
+
+func (s *Segment) Size() int64 {
+	return s.size
+}
+
+// NextOffset is the offset following the last batch of the segment.
+func (s *Segment) NextOffset() int64 {
+	return s.nextOffset
+}
+
+// MaxTimestamp is the largest batch timestamp in the segment, -1 if empty.
+func (s *Segment) MaxTimestamp() int64 {
+	return s.maxTimestamp
+}
+
+// LastModified is used by time based retention when the segment has no
+// timestamps.
+func (s *Segment) LastModified() time.Time {
+	if info, err := s.file.Stat(); err == nil {
+		return info.ModTime()
+	}
+	return s.created
+}
+
+// largestTimestamp is what time based retention compares against.
+func (s *Segment) largestTimestamp() int64 {
+	if s.maxTimestamp > 0 {
+		return s.maxTimestamp
+	}
+	return s.LastModified().UnixMilli()
+}
+
+// readHeaderAt reads the batch header stored at position.
+func (s *Segment) readHeaderAt(position int64) (BatchHeader, error) {
+	var buf [BatchHeaderSize]byte
+	n, err := s.file.ReadAt(buf[:], position)
+	if err != nil && !(errors.Is(err, io.EOF) && n > 0) {
+		return BatchHeader{}, err
+	}
+	return ParseBatchHeader(buf[:n])
+}
+
+// forEachBatch calls f with every batch of the segment, in order.
+func (s *Segment) forEachBatch(f func(data []byte, h BatchHeader) error) error {
+	for position := int64(0); position < s.size; {
+		h, err := s.readHeaderAt(position)
+		if err != nil {
+			return err
+		}
+		data := make([]byte, h.Size())
+		if _, err := s.file.ReadAt(data, position); err != nil {
+			return err
+		}
+		if err := f(data, h); err != nil {
+			return err
+		}
+		position += int64(h.Size())
+	}
+	return nil
+}
+
+// loadState scans the batches after the last index entry to restore the
+// next offset and max timestamp of a segment that was cleanly written.
+func (s *Segment) loadState() error {
+	var position int64
+	if n := s.index.len(); n > 0 {
+		position = int64(s.index.entries[n-1].Position)
+	}
+	if last, ok := s.timeIndex.last(); ok {
+		s.maxTimestamp = last.Timestamp
+		s.offsetOfMaxTimestamp = s.BaseOffset + int64(last.RelativeOffset)
+	}
+	if s.size > 0 {
+		if h, err := s.readHeaderAt(0); err == nil {
+			s.firstTimestamp = h.MaxTimestamp
+		}
+	}
+	for position < s.size {
+		h, err := s.readHeaderAt(position)
+		if err != nil {
+			return err
+		}
+		s.observe(h)
+		position += int64(h.Size())
+	}
+	return nil
+}
+
+func (s *Segment) observe(h BatchHeader) {
+	s.nextOffset = h.LastOffset() + 1
+	if h.MaxTimestamp > s.maxTimestamp {
+		s.maxTimestamp = h.MaxTimestamp
+		s.offsetOfMaxTimestamp = h.LastOffset()
+	}
+}
+
+// recover validates every batch, rebuilds both indexes and truncates the
+// file at the first torn or corrupt batch. It returns the number of bytes
+// dropped.
+func (s *Segment) recover() (int64, error) {
+	if err := s.index.truncateEntries(0); err != nil {
+		return 0, err
+	}
+	if err := s.timeIndex.truncateEntries(0); err != nil {
+		return 0, err
+	}
+	s.nextOffset = s.BaseOffset
+	s.maxTimestamp, s.offsetOfMaxTimestamp, s.firstTimestamp = -1, -1, -1
+	s.bytesSinceIndexEntry = 0
+
+	var position int64
+	for position < s.size {
+		h, err := s.readHeaderAt(position)
+		if err != nil || position+int64(h.Size()) > s.size || h.BaseOffset < s.nextOffset {
+			break
+		}
+		batch := make([]byte, h.Size())
+		if _, err := s.file.ReadAt(batch, position); err != nil {
+			break
+		}
+		if _, err := ValidateBatch(batch); err != nil {
+			break
+		}
+		if err := s.indexBatch(h, position); err != nil {
+			return 0, err
+		}
+		position += int64(h.Size())
+	}
+	truncated := s.size - position
+	if truncated > 0 {
+		if err := s.file.Truncate(position); err != nil {
+			return 0, err
+		}
+		s.size = position
+	}
+	return truncated, nil
+}
+
+// indexBatch updates the in-memory state and indexes for a batch stored
+// at position.
+func (s *Segment) indexBatch(h BatchHeader, position int64) error {
+	if s.firstTimestamp < 0 {
+		s.firstTimestamp = h.MaxTimestamp
+	}
+	s.observe(h)
+	if s.bytesSinceIndexEntry > int64(s.indexIntervalBytes) {
+		if err := s.index.append(offsetEntry{
+			RelativeOffset: int32(h.LastOffset() - s.BaseOffset),
+			Position:       int32(position),
+		}); err != nil {
+			return err
+		}
+		if err := s.timeIndex.maybeAppend(timeEntry{
+			Timestamp:      s.maxTimestamp,
+			RelativeOffset: int32(s.offsetOfMaxTimestamp - s.BaseOffset),
+		}); err != nil {
+			return err
+		}
+		s.bytesSinceIndexEntry = 0
+	}
+	s.bytesSinceIndexEntry += int64(h.Size())
+	return nil
+}
+
+// canHold reports whether a batch ending at lastOffset can be addressed
+// with the int32 relative offsets of the indexes.
+func (s *Segment) canHold(lastOffset int64) bool {
+	return lastOffset-s.BaseOffset <= math.MaxInt32
+}
+
+func (s *Segment) append(batch []byte, h BatchHeader) error {
+	if _, err := s.file.WriteAt(batch, s.size); err != nil {
+		// Drop whatever part of the batch made it to the file.
+		return errors.Join(err, s.file.Truncate(s.size))
+	}
+	position := s.size
+	s.size += int64(len(batch))
+	return s.indexBatch(h, position)
+}
+
+// findPosition returns the position of the first batch whose last offset
+// is at least offset, and false if no batch of the segment qualifies.
+func (s *Segment) findPosition(offset int64) (int64, BatchHeader, bool, error) {
+	relative := offset - s.BaseOffset
+	if relative < 0 {
+		relative = 0
+	}
+	position := int64(0)
+	if relative <= math.MaxInt32 {
+		position = int64(s.index.lookup(int32(relative)))
+	}
+	for position < s.size {
+		h, err := s.readHeaderAt(position)
+		if err != nil {
+			return 0, h, false, err
+		}
+		if h.LastOffset() >= offset {
+			return position, h, true, nil
+		}
+		position += int64(h.Size())
+	}
+	return 0, BatchHeader{}, false, nil
+}
+
+// findTimestamp scans the segment from the time index entry preceding
+// timestamp for the first record at or above from whose timestamp is at
+// least timestamp. Batches are decoded only when their max timestamp
+// shows they hold such a record.
+func (s *Segment) findTimestamp(timestamp, from int64) (TimestampOffset, bool, error) {
+	start := max(s.BaseOffset+int64(s.timeIndex.lookup(timestamp)), from)
+	position, h, ok, err := s.findPosition(start)
+	if err != nil || !ok {
+		return TimestampOffset{}, false, err
+	}
+	for {
+		if h.MaxTimestamp >= timestamp && !h.IsControl() {
+			data := make([]byte, h.Size())
+			if _, err := s.file.ReadAt(data, position); err != nil {
+				return TimestampOffset{}, false, err
+			}
+			batch, err := types.DecodeRecordBatch(data)
+			if err != nil {
+				return TimestampOffset{}, false, err
+			}
+			for _, r := range batch.Records {
+				offset, ts := batch.Offset(r), batch.Timestamp(r)
+				if offset >= start && ts >= timestamp {
+					return TimestampOffset{Timestamp: ts, Offset: offset}, true, nil
+				}
+			}
+		}
+		position += int64(h.Size())
+		if position >= s.size {
+			return TimestampOffset{}, false, nil
+		}
+		if h, err = s.readHeaderAt(position); err != nil {
+			return TimestampOffset{}, false, err
+		}
+	}
+}
+
+// read returns whole batches starting with the one containing offset, up
+// to maxBytes. With minOneBatch the first batch is returned in full even
+// when it is larger than maxBytes, so that consumers can make progress.
+func (s *Segment) read(offset int64, maxBytes int, minOneBatch bool) ([]byte, error) {
+	start, h, ok, err := s.findPosition(offset)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if !minOneBatch && h.Size() > maxBytes {
+		return []byte{}, nil
+	}
+	end := start + int64(h.Size())
+	for end < s.size {
+		next, err := s.readHeaderAt(end)
+		if err != nil {
+			return nil, err
+		}
+		if end-start+int64(next.Size()) > int64(maxBytes) {
+			break
+		}
+		end += int64(next.Size())
+	}
+	buf := make([]byte, end-start)
+	if _, err := s.file.ReadAt(buf, start); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// truncateTo drops every batch at or above offset.
+func (s *Segment) truncateTo(offset int64) error {
+	position, _, ok, err := s.findPosition(offset)
+	if err != nil || !ok {
+		return err
+	}
+	h, err := s.readHeaderAt(position)
+	if err != nil {
+		return err
+	}
+	if h.BaseOffset < offset {
+		// Batches are atomic, keep the one straddling offset.
+		position += int64(h.Size())
+	}
+	if err := s.file.Truncate(position); err != nil {
+		return err
+	}
+	s.size = position
+	return s.rebuild()
+}
+
+// rebuild recomputes the indexes from the log file.
+func (s *Segment) rebuild() error {
+	_, err := s.recover()
+	return err
+}
+
+// onBecomeInactive makes sure the time index records the largest
+// timestamp before the segment stops receiving appends.
+func (s *Segment) onBecomeInactive() error {
+	if s.maxTimestamp >= 0 {
+		if err := s.timeIndex.maybeAppend(timeEntry{
+			Timestamp:      s.maxTimestamp,
+			RelativeOffset: int32(s.offsetOfMaxTimestamp - s.BaseOffset),
+		}); err != nil {
+			return err
+		}
+	}
+	return s.flush()
+}
+
+func (s *Segment) flush() error {
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	if err := s.index.file.Sync(); err != nil {
+		return err
+	}
+	return s.timeIndex.file.Sync()
+}
+
+func (s *Segment) close() error {
+	return errors.Join(s.file.Close(), s.index.file.Close(), s.timeIndex.file.Close())
+}
+
+var segmentFileSuffixes = []string{LogFileSuffix, IndexFileSuffix, TimeIndexFileSuffix}
+
+// rename moves every file of the segment to the name ending in suffix.
+func (s *Segment) rename(suffix string) error {
+	prefix := filepath.Join(s.dir, SegmentName(s.BaseOffset))
+	for _, name := range segmentFileSuffixes {
+		if err := os.Rename(prefix+name+s.suffix, prefix+name+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	s.suffix = suffix
+	return nil
+}
+
+// markDeleted renames the files of a segment that left the log to
+// *.deleted. The files stay open until delete; a restart in between
+// removes them when the log is loaded.
+func (s *Segment) markDeleted() error {
+	return s.rename(DeletedFileSuffix)
+}
+
+// delete closes the segment and removes its files.
+func (s *Segment) delete() error {
+	prefix := filepath.Join(s.dir, SegmentName(s.BaseOffset))
+	err := s.close()
+	for _, name := range segmentFileSuffixes {
+		if rmErr := os.Remove(prefix + name + s.suffix); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+			err = errors.Join(err, rmErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	"github.com/nabinkhanal00/kafka/app/config"
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+// authorizedOperationsOmitted is sent when the client did not ask for
+// authorized operations.
+const authorizedOperationsOmitted int32 = math.MinInt32
+
+type MetadataHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewMetadataHandler(broker *Broker) *MetadataHandler {
+	return &MetadataHandler{FlexibleSince: 9, broker: broker}
+}
+
+func (h *MetadataHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseMetadata(r, version)
+}
+
+// Handle describes this broker, as reachable through the listener the
+// request arrived on, and the requested topics. Unknown topics named by
+// the client are created when both the request and auto.create.topics.enable
+// allow it.
+func (h *MetadataHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.Metadata)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	cfg := h.broker.Config
+	endpoint := h.broker.advertisedEndpoint(req.Listener)
+	resp := &responses.Metadata{
+		Version: rb.Version,
+		Brokers: []responses.MetadataBroker{{
+			NodeID: cfg.NodeID,
+			Host:   endpoint.Host,
+			Port:   endpoint.Port,
+		}},
+		ClusterID:                   h.broker.ClusterID,
+		ControllerID:                h.broker.controllerID(),
+		Topics:                      []responses.MetadataTopic{},
+		ClusterAuthorizedOperations: authorizedOperationsOmitted,
+	}
+	if cfg.Rack != "" {
+		resp.Brokers[0].Rack = &cfg.Rack
+	}
+
+	if rb.AllTopics() {
+		for _, t := range h.broker.Metadata.Topics() {
+			resp.Topics = append(resp.Topics, describeMetadataTopic(t, rb.IncludeTopicAuthorizedOperations))
+		}
+		return resp, nil
+	}
+	autoCreate := rb.AllowAutoTopicCreation && cfg.AutoCreateTopicsEnable
+	for _, topic := range rb.Topics {
+		rt := responses.MetadataTopic{
+			Name:                      topic.Name,
+			TopicID:                   topic.TopicID,
+			Partitions:                []responses.MetadataPartition{},
+			TopicAuthorizedOperations: authorizedOperationsOmitted,
+		}
+		if topic.Name == nil {
+			t, ok := h.broker.Metadata.TopicByID(topic.TopicID)
+			if !ok {
+				rt.ErrorCode = UNKNOWN_TOPIC_ID
+				resp.Topics = append(resp.Topics, rt)
+				continue
+			}
+			resp.Topics = append(resp.Topics, describeMetadataTopic(t, rb.IncludeTopicAuthorizedOperations))
+			continue
+		}
+		name := *topic.Name
+		t, ok := h.broker.Metadata.Topic(name)
+		if !ok {
+			if err := validateTopicName(name); err != nil {
+				rt.ErrorCode = INVALID_TOPIC_EXCEPTION
+				resp.Topics = append(resp.Topics, rt)
+				continue
+			}
+			if !autoCreate {
+				rt.ErrorCode = UNKNOWN_TOPIC_OR_PARTITION
+				resp.Topics = append(resp.Topics, rt)
+				continue
+			}
+			var err error
+			t, err = h.broker.createTopic(name, cfg.NumPartitions, cfg.DefaultReplicationFactor)
+			if errors.Is(err, errTopicExists) {
+				// Created concurrently by another request.
+				t, _ = h.broker.Metadata.Topic(name)
+			} else if err != nil {
+				rt.ErrorCode = topicErrorCode(err)
+				resp.Topics = append(resp.Topics, rt)
+				continue
+			}
+		}
+		resp.Topics = append(resp.Topics, describeMetadataTopic(t, rb.IncludeTopicAuthorizedOperations))
+	}
+	return resp, nil
+}
+
+func describeMetadataTopic(t metadata.Topic, includeAuthorizedOperations bool) responses.MetadataTopic {
+	name := t.Name
+	rt := responses.MetadataTopic{
+		ErrorCode:                 NONE,
+		Name:                      &name,
+		TopicID:                   t.ID,
+		IsInternal:                t.Internal(),
+		Partitions:                []responses.MetadataPartition{},
+		TopicAuthorizedOperations: authorizedOperationsOmitted,
+	}
+	if includeAuthorizedOperations {
+		rt.TopicAuthorizedOperations = topicAuthorizedOperations
+	}
+	for _, p := range t.Partitions {
+		rp := responses.MetadataPartition{
+			ErrorCode:       NONE,
+			PartitionIndex:  p.Index,
+			LeaderID:        p.Leader,
+			LeaderEpoch:     p.LeaderEpoch,
+			ReplicaNodes:    p.Replicas,
+			ISRNodes:        p.ISR,
+			OfflineReplicas: []int32{},
+		}
+		if p.Leader < 0 {
+			rp.ErrorCode = LEADER_NOT_AVAILABLE
+		}
+		rt.Partitions = append(rt.Partitions, rp)
+	}
+	return rt
+}
+
+// advertisedEndpoint is how clients of a listener reach this broker. An
+// empty host means the machine's host name, as upstream does.
+func (b *Broker) advertisedEndpoint(listener string) config.Endpoint {
+	endpoint, ok := b.Config.AdvertisedListener(listener)
+	if !ok && len(b.Config.AdvertisedListeners) > 0 {
+		endpoint = b.Config.AdvertisedListeners[0]
+	}
+	if endpoint.Host == "" {
+		endpoint.Host, _ = os.Hostname()
+	}
+	return endpoint
+}
+
+// controllerID is this node in combined mode and unknown otherwise.
+func (b *Broker) controllerID() int32 {
+	if slices.Contains(b.Config.ProcessRoles, "controller") {
+		return b.Config.NodeID
+	}
+	return -1
+}
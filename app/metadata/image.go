@@ -0,0 +1,262 @@
+// Package metadata replays the KRaft __cluster_metadata log into an
+// in-memory image of topics, partitions, configs and features.
+package metadata
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Topic is a snapshot of one topic of the image.
+type Topic struct {
+	Name       string
+	ID         types.UUID
+	Partitions []Partition
+}
+
+// Internal reports whether the topic is one of the broker's own topics.
+func (t Topic) Internal() bool {
+	return t.Name == "__consumer_offsets" || t.Name == "__transaction_state"
+}
+
+// Partition is the replica assignment and leadership of a partition.
+type Partition struct {
+	Index                  int32
+	Replicas               []int32
+	ISR                    []int32
+	RemovingReplicas       []int32
+	AddingReplicas         []int32
+	Leader                 int32
+	LeaderRecoveryState    int8
+	LeaderEpoch            int32
+	PartitionEpoch         int32
+	Directories            []types.UUID
+	EligibleLeaderReplicas []int32
+	LastKnownELR           []int32
+}
+
+type topicState struct {
+	name       string
+	id         types.UUID
+	partitions map[int32]*Partition
+}
+
+func (t *topicState) snapshot() Topic {
+	topic := Topic{Name: t.name, ID: t.id, Partitions: make([]Partition, 0, len(t.partitions))}
+	for _, p := range t.partitions {
+		topic.Partitions = append(topic.Partitions, p.clone())
+	}
+	sort.Slice(topic.Partitions, func(i, j int) bool {
+		return topic.Partitions[i].Index < topic.Partitions[j].Index
+	})
+	return topic
+}
+
+func (p *Partition) clone() Partition {
+	c := *p
+	c.Replicas = slices.Clone(p.Replicas)
+	c.ISR = slices.Clone(p.ISR)
+	c.RemovingReplicas = slices.Clone(p.RemovingReplicas)
+	c.AddingReplicas = slices.Clone(p.AddingReplicas)
+	c.Directories = slices.Clone(p.Directories)
+	c.EligibleLeaderReplicas = slices.Clone(p.EligibleLeaderReplicas)
+	c.LastKnownELR = slices.Clone(p.LastKnownELR)
+	return c
+}
+
+// ConfigResource identifies the owner of dynamic configs.
+type ConfigResource struct {
+	Type int8
+	Name string
+}
+
+// Image is the current state described by the metadata log. Readers get
+// copies, so the image may be updated while they hold them.
+type Image struct {
+	mu sync.RWMutex
+	// offset is the metadata log offset of the last applied record.
+	offset   int64
+	topics   map[types.UUID]*topicState
+	byName   map[string]types.UUID
+	configs  map[ConfigResource]map[string]string
+	features map[string]int16
+}
+
+func NewImage() *Image {
+	return &Image{
+		offset:   -1,
+		topics:   make(map[types.UUID]*topicState),
+		byName:   make(map[string]types.UUID),
+		configs:  make(map[ConfigResource]map[string]string),
+		features: make(map[string]int16),
+	}
+}
+
+// Apply updates the image with the record stored at offset.
+func (m *Image) Apply(offset int64, r Record) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.offset = max(m.offset, offset)
+	switch r := r.(type) {
+	case TopicRecord:
+		m.topics[r.TopicID] = &topicState{name: r.Name, id: r.TopicID, partitions: make(map[int32]*Partition)}
+		m.byName[r.Name] = r.TopicID
+	case PartitionRecord:
+		t, ok := m.topics[r.TopicID]
+		if !ok {
+			return
+		}
+		t.partitions[r.PartitionID] = &Partition{
+			Index:                  r.PartitionID,
+			Replicas:               r.Replicas,
+			ISR:                    r.ISR,
+			RemovingReplicas:       r.RemovingReplicas,
+			AddingReplicas:         r.AddingReplicas,
+			Leader:                 r.Leader,
+			LeaderRecoveryState:    r.LeaderRecoveryState,
+			LeaderEpoch:            r.LeaderEpoch,
+			PartitionEpoch:         r.PartitionEpoch,
+			Directories:            r.Directories,
+			EligibleLeaderReplicas: r.EligibleLeaderReplicas,
+			LastKnownELR:           r.LastKnownELR,
+		}
+	case PartitionChangeRecord:
+		t, ok := m.topics[r.TopicID]
+		if !ok {
+			return
+		}
+		if p, ok := t.partitions[r.PartitionID]; ok {
+			p.applyChange(r)
+		}
+	case RemoveTopicRecord:
+		if t, ok := m.topics[r.TopicID]; ok {
+			delete(m.topics, r.TopicID)
+			delete(m.byName, t.name)
+			delete(m.configs, ConfigResource{Type: TopicResource, Name: t.name})
+		}
+	case ConfigRecord:
+		resource := ConfigResource{Type: r.ResourceType, Name: r.ResourceName}
+		if r.Value == nil {
+			delete(m.configs[resource], r.Name)
+			return
+		}
+		if m.configs[resource] == nil {
+			m.configs[resource] = make(map[string]string)
+		}
+		m.configs[resource][r.Name] = *r.Value
+	case FeatureLevelRecord:
+		if r.FeatureLevel == 0 {
+			delete(m.features, r.Name)
+			return
+		}
+		m.features[r.Name] = r.FeatureLevel
+	}
+}
+
+// applyChange merges a PartitionChangeRecord. Like upstream, a new leader
+// bumps the leader epoch and every change bumps the partition epoch.
+func (p *Partition) applyChange(r PartitionChangeRecord) {
+	if r.ISR != nil {
+		p.ISR = r.ISR
+	}
+	if r.Replicas != nil {
+		p.Replicas = r.Replicas
+	}
+	if r.RemovingReplicas != nil {
+		p.RemovingReplicas = r.RemovingReplicas
+	}
+	if r.AddingReplicas != nil {
+		p.AddingReplicas = r.AddingReplicas
+	}
+	if r.EligibleLeaderReplicas != nil {
+		p.EligibleLeaderReplicas = r.EligibleLeaderReplicas
+	}
+	if r.LastKnownELR != nil {
+		p.LastKnownELR = r.LastKnownELR
+	}
+	if r.Directories != nil {
+		p.Directories = r.Directories
+	}
+	if r.LeaderRecoveryState >= 0 {
+		p.LeaderRecoveryState = r.LeaderRecoveryState
+	}
+	if r.Leader != NoLeaderChange {
+		p.Leader = r.Leader
+		p.LeaderEpoch++
+	}
+	p.PartitionEpoch++
+}
+
+// Offset is the metadata log offset the image reflects, -1 when empty.
+// Upstream reports it as the epoch of the finalized features.
+func (m *Image) Offset() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.offset
+}
+
+// Topic looks a topic up by name.
+func (m *Image) Topic(name string) (Topic, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, ok := m.byName[name]
+	if !ok {
+		return Topic{}, false
+	}
+	return m.topics[id].snapshot(), true
+}
+
+// TopicByID looks a topic up by its id.
+func (m *Image) TopicByID(id types.UUID) (Topic, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.topics[id]
+	if !ok {
+		return Topic{}, false
+	}
+	return t.snapshot(), true
+}
+
+// Topics returns every topic sorted by name.
+func (m *Image) Topics() []Topic {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	topics := make([]Topic, 0, len(m.topics))
+	for _, t := range m.topics {
+		topics = append(topics, t.snapshot())
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return topics
+}
+
+// Configs returns the dynamic configs of a resource.
+func (m *Image) Configs(resource ConfigResource) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	configs := make(map[string]string, len(m.configs[resource]))
+	for k, v := range m.configs[resource] {
+		configs[k] = v
+	}
+	return configs
+}
+
+// FeatureLevel returns the finalized level of a feature, 0 if unset.
+func (m *Image) FeatureLevel(name string) int16 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.features[name]
+}
+
+// Features returns every finalized feature level.
+func (m *Image) Features() map[string]int16 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	features := make(map[string]int16, len(m.features))
+	for k, v := range m.features {
+		features[k] = v
+	}
+	return features
+}
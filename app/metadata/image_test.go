@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+func TestImageApply(t *testing.T) {
+	m := NewImage()
+	id := types.NewUUID()
+	topic := ConfigResource{Type: TopicResource, Name: "orders"}
+	m.Apply(0, TopicRecord{Name: "orders", TopicID: id})
+	m.Apply(1, PartitionRecord{PartitionID: 1, TopicID: id, Replicas: []int32{1}, ISR: []int32{1}, Leader: 1})
+	m.Apply(2, PartitionRecord{PartitionID: 0, TopicID: id, Replicas: []int32{1, 2}, ISR: []int32{1, 2}, Leader: 1, LeaderEpoch: 5})
+	m.Apply(3, ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: "retention.ms", Value: types.NullableStringOf("1000")})
+	m.Apply(4, ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: "segment.ms", Value: types.NullableStringOf("10")})
+	m.Apply(5, ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: "segment.ms"})
+	m.Apply(6, FeatureLevelRecord{Name: "metadata.version", FeatureLevel: 21})
+	// Records for unknown topics are ignored.
+	m.Apply(7, PartitionRecord{PartitionID: 0, TopicID: types.NewUUID(), Leader: 1})
+
+	if got := m.Offset(); got != 7 {
+		t.Fatalf("Offset = %d, want 7", got)
+	}
+	byName, ok := m.Topic("orders")
+	if !ok || byName.ID != id || len(byName.Partitions) != 2 || byName.Partitions[0].Index != 0 {
+		t.Fatalf("Topic(orders) = %+v, %v; want two partitions sorted by index", byName, ok)
+	}
+	if byID, ok := m.TopicByID(id); !ok || !reflect.DeepEqual(byID, byName) {
+		t.Fatalf("TopicByID = %+v, %v; want %+v", byID, ok, byName)
+	}
+	if got := len(m.Topics()); got != 1 {
+		t.Fatalf("%d topics, want 1", got)
+	}
+	if got := m.Configs(topic); !reflect.DeepEqual(got, map[string]string{"retention.ms": "1000"}) {
+		t.Fatalf("Configs = %v, want only retention.ms", got)
+	}
+	if got := m.FeatureLevel("metadata.version"); got != 21 {
+		t.Fatalf("FeatureLevel = %d, want 21", got)
+	}
+
+	// Snapshots are copies.
+	byName.Partitions[0].ISR[0] = 9
+	if p, _ := m.Topic("orders"); p.Partitions[0].ISR[0] != 1 {
+		t.Fatal("modifying a snapshot changed the image")
+	}
+
+	m.Apply(8, FeatureLevelRecord{Name: "metadata.version"})
+	if _, ok := m.Features()["metadata.version"]; ok {
+		t.Fatal("level 0 did not remove the feature")
+	}
+	m.Apply(9, RemoveTopicRecord{TopicID: id})
+	if _, ok := m.Topic("orders"); ok {
+		t.Fatal("removed topic still found by name")
+	}
+	if _, ok := m.TopicByID(id); ok {
+		t.Fatal("removed topic still found by id")
+	}
+	if got := m.Configs(topic); len(got) != 0 {
+		t.Fatalf("removed topic kept configs %v", got)
+	}
+}
+
+func TestPartitionChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change PartitionChangeRecord
+		want   Partition
+	}{
+		{
+			name:   "isr shrink",
+			change: PartitionChangeRecord{ISR: []int32{1}, Leader: NoLeaderChange, LeaderRecoveryState: -1},
+			want:   Partition{Replicas: []int32{1, 2}, ISR: []int32{1}, Leader: 1, LeaderEpoch: 3, PartitionEpoch: 6},
+		},
+		{
+			name:   "new leader",
+			change: PartitionChangeRecord{Leader: 2, LeaderRecoveryState: -1},
+			want:   Partition{Replicas: []int32{1, 2}, ISR: []int32{1, 2}, Leader: 2, LeaderEpoch: 4, PartitionEpoch: 6},
+		},
+		{
+			name:   "reassignment",
+			change: PartitionChangeRecord{Replicas: []int32{1, 2, 3}, AddingReplicas: []int32{3}, Leader: NoLeaderChange, LeaderRecoveryState: 1},
+			want: Partition{Replicas: []int32{1, 2, 3}, ISR: []int32{1, 2}, AddingReplicas: []int32{3}, Leader: 1,
+				LeaderRecoveryState: 1, LeaderEpoch: 3, PartitionEpoch: 6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewImage()
+			id := types.NewUUID()
+			m.Apply(0, TopicRecord{Name: "orders", TopicID: id})
+			m.Apply(1, PartitionRecord{TopicID: id, Replicas: []int32{1, 2}, ISR: []int32{1, 2}, Leader: 1, LeaderEpoch: 3, PartitionEpoch: 5})
+			tt.change.TopicID = id
+			m.Apply(2, tt.change)
+			topic, _ := m.Topic("orders")
+			if got := topic.Partitions[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package metadata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Metadata record types, the api key that prefixes every record value.
+const (
+	RegisterBrokerRecordType   int16 = 0
+	UnregisterBrokerRecordType int16 = 1
+	TopicRecordType            int16 = 2
+	PartitionRecordType        int16 = 3
+	ConfigRecordType           int16 = 4
+	PartitionChangeRecordType  int16 = 5
+	FenceBrokerRecordType      int16 = 7
+	UnfenceBrokerRecordType    int16 = 8
+	RemoveTopicRecordType      int16 = 9
+	FeatureLevelRecordType     int16 = 12
+	NoOpRecordType             int16 = 20
+)
+
+// Resource types of ConfigRecord.
+const (
+	TopicResource  int8 = 2
+	BrokerResource int8 = 4
+)
+
+// recordFrameVersion is the version of the envelope around every record.
+const recordFrameVersion = 1
+
+// NoLeaderChange is the PartitionChangeRecord leader meaning "unchanged".
+const NoLeaderChange int32 = -2
+
+type Record interface {
+	Type() int16
+}
+
+type TopicRecord struct {
+	Name    string
+	TopicID types.UUID
+}
+
+type PartitionRecord struct {
+	PartitionID            int32
+	TopicID                types.UUID
+	Replicas               []int32
+	ISR                    []int32
+	RemovingReplicas       []int32
+	AddingReplicas         []int32
+	Leader                 int32
+	LeaderRecoveryState    int8
+	LeaderEpoch            int32
+	PartitionEpoch         int32
+	Directories            []types.UUID
+	EligibleLeaderReplicas []int32
+	LastKnownELR           []int32
+}
+
+// PartitionChangeRecord carries only the fields that changed; nil slices
+// and NoLeaderChange leave the current value in place.
+type PartitionChangeRecord struct {
+	PartitionID            int32
+	TopicID                types.UUID
+	ISR                    []int32
+	Leader                 int32
+	Replicas               []int32
+	RemovingReplicas       []int32
+	AddingReplicas         []int32
+	LeaderRecoveryState    int8
+	EligibleLeaderReplicas []int32
+	LastKnownELR           []int32
+	Directories            []types.UUID
+}
+
+type RemoveTopicRecord struct {
+	TopicID types.UUID
+}
+
+// ConfigRecord sets a dynamic config; a nil Value deletes it.
+type ConfigRecord struct {
+	ResourceType int8
+	ResourceName string
+	Name         string
+	Value        *string
+}
+
+// FeatureLevelRecord finalizes a feature; level 0 removes it.
+type FeatureLevelRecord struct {
+	Name         string
+	FeatureLevel int16
+}
+
+func (TopicRecord) Type() int16           { return TopicRecordType }
+func (PartitionRecord) Type() int16       { return PartitionRecordType }
+func (PartitionChangeRecord) Type() int16 { return PartitionChangeRecordType }
+func (RemoveTopicRecord) Type() int16     { return RemoveTopicRecordType }
+func (ConfigRecord) Type() int16          { return ConfigRecordType }
+func (FeatureLevelRecord) Type() int16    { return FeatureLevelRecordType }
+
+// Tags of the tagged fields of PartitionRecord and PartitionChangeRecord.
+const (
+	partitionLeaderRecoveryStateTag = 0
+	partitionELRTag                 = 1
+	partitionLastKnownELRTag        = 2
+
+	changeISRTag                 = 0
+	changeLeaderTag              = 1
+	changeReplicasTag            = 2
+	changeRemovingReplicasTag    = 3
+	changeAddingReplicasTag      = 4
+	changeLeaderRecoveryStateTag = 5
+	changeELRTag                 = 6
+	changeLastKnownELRTag        = 7
+	changeDirectoriesTag         = 8
+)
+
+// DecodeRecord decodes the value of a metadata log record. Record types
+// the image does not track are returned as nil without an error.
+func DecodeRecord(value []byte) (Record, error) {
+	d := types.NewDecoder(bytes.NewReader(value), true)
+	frameVersion := d.Uvarint()
+	recordType := int16(d.Uvarint())
+	version := int16(d.Uvarint())
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if frameVersion != recordFrameVersion {
+		return nil, fmt.Errorf("unsupported metadata record frame version %d", frameVersion)
+	}
+	var r Record
+	var err error
+	switch recordType {
+	case TopicRecordType:
+		r = TopicRecord{Name: d.String(), TopicID: d.UUID()}
+		d.TaggedFields()
+	case PartitionRecordType:
+		r, err = decodePartitionRecord(d, version)
+	case PartitionChangeRecordType:
+		r, err = decodePartitionChangeRecord(d)
+	case RemoveTopicRecordType:
+		r = RemoveTopicRecord{TopicID: d.UUID()}
+		d.TaggedFields()
+	case ConfigRecordType:
+		r = ConfigRecord{ResourceType: d.Int8(), ResourceName: d.String(), Name: d.String(), Value: d.NullableString()}
+		d.TaggedFields()
+	case FeatureLevelRecordType:
+		r = FeatureLevelRecord{Name: d.String(), FeatureLevel: d.Int16()}
+		d.TaggedFields()
+	default:
+		return nil, nil
+	}
+	if err := errors.Join(d.Err(), err); err != nil {
+		return nil, fmt.Errorf("metadata record type %d v%d: %w", recordType, version, err)
+	}
+	return r, nil
+}
+
+func decodePartitionRecord(d *types.Decoder, version int16) (PartitionRecord, error) {
+	p := PartitionRecord{
+		PartitionID:      d.Int32(),
+		TopicID:          d.UUID(),
+		Replicas:         int32Array(d),
+		ISR:              int32Array(d),
+		RemovingReplicas: int32Array(d),
+		AddingReplicas:   int32Array(d),
+		Leader:           d.Int32(),
+		LeaderEpoch:      d.Int32(),
+		PartitionEpoch:   d.Int32(),
+	}
+	if version >= 1 {
+		p.Directories = uuidArray(d)
+	}
+	tags := d.TaggedFields()
+	return p, errors.Join(
+		decodeTag(tags, partitionLeaderRecoveryStateTag, &p.LeaderRecoveryState, (*types.Decoder).Int8),
+		decodeTag(tags, partitionELRTag, &p.EligibleLeaderReplicas, int32Array),
+		decodeTag(tags, partitionLastKnownELRTag, &p.LastKnownELR, int32Array),
+	)
+}
+
+func decodePartitionChangeRecord(d *types.Decoder) (PartitionChangeRecord, error) {
+	p := PartitionChangeRecord{
+		PartitionID:         d.Int32(),
+		TopicID:             d.UUID(),
+		Leader:              NoLeaderChange,
+		LeaderRecoveryState: -1,
+	}
+	tags := d.TaggedFields()
+	return p, errors.Join(
+		decodeTag(tags, changeISRTag, &p.ISR, int32Array),
+		decodeTag(tags, changeLeaderTag, &p.Leader, (*types.Decoder).Int32),
+		decodeTag(tags, changeReplicasTag, &p.Replicas, int32Array),
+		decodeTag(tags, changeRemovingReplicasTag, &p.RemovingReplicas, int32Array),
+		decodeTag(tags, changeAddingReplicasTag, &p.AddingReplicas, int32Array),
+		decodeTag(tags, changeLeaderRecoveryStateTag, &p.LeaderRecoveryState, (*types.Decoder).Int8),
+		decodeTag(tags, changeELRTag, &p.EligibleLeaderReplicas, int32Array),
+		decodeTag(tags, changeLastKnownELRTag, &p.LastKnownELR, int32Array),
+		decodeTag(tags, changeDirectoriesTag, &p.Directories, uuidArray),
+	)
+}
+
+func int32Array(d *types.Decoder) []int32 {
+	return types.DecodeArray(d, (*types.Decoder).Int32)
+}
+
+func uuidArray(d *types.Decoder) []types.UUID {
+	return types.DecodeArray(d, (*types.Decoder).UUID)
+}
+
+// decodeTag decodes the tagged field tag into dst when it is present.
+func decodeTag[T any](tags types.TaggedFields, tag uint64, dst *T, f func(*types.Decoder) T) error {
+	v, ok := tags.Fields[tag]
+	if !ok {
+		return nil
+	}
+	d := types.NewDecoder(bytes.NewReader(v), true)
+	*dst = f(d)
+	if err := d.Err(); err != nil {
+		return fmt.Errorf("tagged field %d: %w", tag, err)
+	}
+	return nil
+}
+
+// EncodeRecord serializes r as the value of a metadata log record.
+func EncodeRecord(r Record) ([]byte, error) {
+	var buf bytes.Buffer
+	e := types.NewEncoder(&buf, true)
+	version := int16(0)
+	if p, ok := r.(PartitionRecord); ok && p.Directories != nil {
+		version = 1
+	}
+	e.Uvarint(recordFrameVersion)
+	e.Uvarint(uint64(r.Type()))
+	e.Uvarint(uint64(version))
+	switch r := r.(type) {
+	case TopicRecord:
+		e.String(r.Name)
+		e.UUID(r.TopicID)
+		e.TaggedFields(types.TaggedFields{})
+	case PartitionRecord:
+		e.Int32(r.PartitionID)
+		e.UUID(r.TopicID)
+		types.EncodeArray(e, r.Replicas, (*types.Encoder).Int32)
+		types.EncodeArray(e, r.ISR, (*types.Encoder).Int32)
+		types.EncodeArray(e, r.RemovingReplicas, (*types.Encoder).Int32)
+		types.EncodeArray(e, r.AddingReplicas, (*types.Encoder).Int32)
+		e.Int32(r.Leader)
+		e.Int32(r.LeaderEpoch)
+		e.Int32(r.PartitionEpoch)
+		if version >= 1 {
+			types.EncodeArray(e, r.Directories, (*types.Encoder).UUID)
+		}
+		tags := types.TaggedFields{Fields: map[uint64][]byte{}}
+		if r.LeaderRecoveryState != 0 {
+			tags.Fields[partitionLeaderRecoveryStateTag] = encodeTag(func(e *types.Encoder) { e.Int8(r.LeaderRecoveryState) })
+		}
+		e.TaggedFields(tags)
+	case RemoveTopicRecord:
+		e.UUID(r.TopicID)
+		e.TaggedFields(types.TaggedFields{})
+	case ConfigRecord:
+		e.Int8(r.ResourceType)
+		e.String(r.ResourceName)
+		e.String(r.Name)
+		e.NullableString(r.Value)
+		e.TaggedFields(types.TaggedFields{})
+	case FeatureLevelRecord:
+		e.String(r.Name)
+		e.Int16(r.FeatureLevel)
+		e.TaggedFields(types.TaggedFields{})
+	default:
+		return nil, fmt.Errorf("cannot encode metadata record type %d", r.Type())
+	}
+	return buf.Bytes(), e.Err()
+}
+
+func encodeTag(f func(*types.Encoder)) []byte {
+	var buf bytes.Buffer
+	f(types.NewEncoder(&buf, true))
+	return buf.Bytes()
+}
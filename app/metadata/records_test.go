@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	id := types.NewUUID()
+	tests := []struct {
+		name   string
+		record Record
+	}{
+		{"topic", TopicRecord{Name: "orders", TopicID: id}},
+		{"partition", PartitionRecord{
+			PartitionID:      3,
+			TopicID:          id,
+			Replicas:         []int32{1, 2},
+			ISR:              []int32{1},
+			RemovingReplicas: []int32{},
+			AddingReplicas:   []int32{2},
+			Leader:           1,
+			LeaderEpoch:      4,
+			PartitionEpoch:   7,
+		}},
+		{"partition with directories", PartitionRecord{
+			TopicID:             id,
+			Replicas:            []int32{1},
+			ISR:                 []int32{1},
+			RemovingReplicas:    []int32{},
+			AddingReplicas:      []int32{},
+			Leader:              1,
+			LeaderRecoveryState: 1,
+			Directories:         []types.UUID{{}},
+		}},
+		{"remove topic", RemoveTopicRecord{TopicID: id}},
+		{"config", ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: "retention.ms", Value: types.NullableStringOf("1000")}},
+		{"config deletion", ConfigRecord{ResourceType: BrokerResource, Name: "log.retention.ms"}},
+		{"feature level", FeatureLevelRecord{Name: "metadata.version", FeatureLevel: 21}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := EncodeRecord(tt.record)
+			if err != nil {
+				t.Fatalf("EncodeRecord: %v", err)
+			}
+			got, err := DecodeRecord(value)
+			if err != nil {
+				t.Fatalf("DecodeRecord: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.record) {
+				t.Fatalf("round trip mismatch:\n got  %+v\n want %+v", got, tt.record)
+			}
+		})
+	}
+}
+
+// encodeFrame writes the envelope of a record followed by body.
+func encodeFrame(frameVersion uint64, recordType int16, version int16, body func(e *types.Encoder)) []byte {
+	var buf bytes.Buffer
+	e := types.NewEncoder(&buf, true)
+	e.Uvarint(frameVersion)
+	e.Uvarint(uint64(recordType))
+	e.Uvarint(uint64(version))
+	body(e)
+	return buf.Bytes()
+}
+
+func TestDecodePartitionChangeRecord(t *testing.T) {
+	id := types.NewUUID()
+	value := encodeFrame(recordFrameVersion, PartitionChangeRecordType, 0, func(e *types.Encoder) {
+		e.Int32(2)
+		e.UUID(id)
+		e.TaggedFields(types.TaggedFields{Fields: map[uint64][]byte{
+			changeISRTag:    encodeTag(func(e *types.Encoder) { types.EncodeArray(e, []int32{1, 3}, (*types.Encoder).Int32) }),
+			changeLeaderTag: encodeTag(func(e *types.Encoder) { e.Int32(3) }),
+		}})
+	})
+	got, err := DecodeRecord(value)
+	if err != nil {
+		t.Fatalf("DecodeRecord: %v", err)
+	}
+	want := PartitionChangeRecord{PartitionID: 2, TopicID: id, ISR: []int32{1, 3}, Leader: 3, LeaderRecoveryState: -1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeRecordUntracked(t *testing.T) {
+	noOp := encodeFrame(recordFrameVersion, NoOpRecordType, 0, func(e *types.Encoder) {
+		e.TaggedFields(types.TaggedFields{})
+	})
+	if r, err := DecodeRecord(noOp); r != nil || err != nil {
+		t.Fatalf("DecodeRecord(NoOpRecord) = %v, %v; want nil, nil", r, err)
+	}
+	badFrame := encodeFrame(0, TopicRecordType, 0, func(e *types.Encoder) {})
+	if _, err := DecodeRecord(badFrame); err == nil {
+		t.Fatal("DecodeRecord accepted frame version 0")
+	}
+	truncated, err := EncodeRecord(TopicRecord{Name: "orders", TopicID: types.NewUUID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeRecord(truncated[:len(truncated)-5]); err == nil {
+		t.Fatal("DecodeRecord accepted a truncated record")
+	}
+}
@@ -0,0 +1,188 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// SnapshotFileSuffix marks KRaft snapshots, named <end offset>-<epoch>.
+const SnapshotFileSuffix = ".checkpoint"
+
+// replayReadBytes bounds a single read while replaying the log.
+const replayReadBytes = 1 << 20
+
+// Store couples the image with the metadata log it is replayed from.
+type Store struct {
+	*Image
+	log *log.Log
+	// writeMu serializes updates so that checks made against the image
+	// still hold when their records are appended.
+	writeMu sync.Mutex
+}
+
+// Open loads the metadata log of partition 0 under dir, first applying
+// the latest snapshot, if any, and then every record after it.
+func Open(dir string) (*Store, error) {
+	l, err := log.Open(filepath.Join(dir, log.DirName(log.MetadataTopic, 0)), log.DefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{Image: NewImage(), log: l}
+	offset, err := s.loadSnapshot()
+	if err == nil {
+		err = s.replay(max(offset, l.LogStartOffset()))
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadSnapshot applies the newest snapshot and returns the offset the log
+// must be replayed from.
+func (s *Store) loadSnapshot() (int64, error) {
+	entries, err := os.ReadDir(s.log.Dir)
+	if err != nil {
+		return 0, err
+	}
+	latest, endOffset := "", int64(0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, SnapshotFileSuffix) {
+			continue
+		}
+		offset, _, ok := strings.Cut(strings.TrimSuffix(name, SnapshotFileSuffix), "-")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil || n < endOffset {
+			continue
+		}
+		latest, endOffset = name, n
+	}
+	if latest == "" {
+		return 0, nil
+	}
+	data, err := os.ReadFile(filepath.Join(s.log.Dir, latest))
+	if err != nil {
+		return 0, err
+	}
+	if err := s.applyBatches(data, 0); err != nil {
+		return 0, fmt.Errorf("snapshot %s: %w", latest, err)
+	}
+	// Snapshot records carry their own offsets; the image now reflects
+	// everything before the snapshot's end offset.
+	s.mu.Lock()
+	s.offset = endOffset - 1
+	s.mu.Unlock()
+	return endOffset, nil
+}
+
+// replay applies every record of the log from offset on.
+func (s *Store) replay(offset int64) error {
+	for offset < s.log.LogEndOffset() {
+		data, err := s.log.Read(offset, replayReadBytes, true)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			break
+		}
+		if err := s.applyBatches(data, offset); err != nil {
+			return err
+		}
+		h, err := lastBatchHeader(data)
+		if err != nil {
+			return err
+		}
+		offset = h.LastOffset() + 1
+	}
+	return nil
+}
+
+// applyBatches applies the records of data with an offset of at least
+// from. Control batches, which hold KRaft bookkeeping such as leader
+// changes and snapshot markers, are skipped.
+func (s *Store) applyBatches(data []byte, from int64) error {
+	batches, err := types.ParseRecordBatches(data)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		if batch.IsControl() {
+			continue
+		}
+		for _, r := range batch.Records {
+			if batch.Offset(r) < from {
+				continue
+			}
+			record, err := DecodeRecord(r.Value)
+			if err != nil {
+				return fmt.Errorf("offset %d: %w", batch.Offset(r), err)
+			}
+			if record != nil {
+				s.Apply(batch.Offset(r), record)
+			}
+		}
+	}
+	return nil
+}
+
+func lastBatchHeader(data []byte) (log.BatchHeader, error) {
+	batches, err := log.SplitBatches(data)
+	if err != nil {
+		return log.BatchHeader{}, err
+	}
+	return log.ParseBatchHeader(batches[len(batches)-1])
+}
+
+// Update runs fn with writes excluded and appends the records it returns
+// to the metadata log as a single batch before applying them.
+func (s *Store) Update(fn func() ([]Record, error)) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	records, err := fn()
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	timestamps := make([]int64, len(records))
+	entries := make([]types.Record, len(records))
+	for i, r := range records {
+		value, err := EncodeRecord(r)
+		if err != nil {
+			return err
+		}
+		timestamps[i] = now
+		entries[i] = types.Record{Value: value}
+	}
+	batch, err := types.NewRecordBatch(0, timestamps, entries).Encode()
+	if err != nil {
+		return err
+	}
+	info, err := s.log.Append(batch)
+	if err != nil {
+		return err
+	}
+	if err := s.log.Flush(); err != nil {
+		return err
+	}
+	for i, r := range records {
+		s.Apply(info.FirstOffset+int64(i), r)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.log.Close()
+}
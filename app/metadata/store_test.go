@@ -0,0 +1,151 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+func openStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func update(t *testing.T, s *Store, records ...Record) {
+	t.Helper()
+	if err := s.Update(func() ([]Record, error) { return records, nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
+
+// encodeBatch encodes records into one batch starting at baseOffset.
+func encodeBatch(t *testing.T, baseOffset int64, records ...Record) []byte {
+	t.Helper()
+	values := make([][]byte, len(records))
+	for i, r := range records {
+		var err error
+		if values[i], err = EncodeRecord(r); err != nil {
+			t.Fatalf("EncodeRecord: %v", err)
+		}
+	}
+	return valueBatch(t, baseOffset, 0, values...)
+}
+
+func valueBatch(t *testing.T, baseOffset int64, attributes int16, values ...[]byte) []byte {
+	t.Helper()
+	entries := make([]types.Record, len(values))
+	timestamps := make([]int64, len(values))
+	for i, v := range values {
+		entries[i] = types.Record{Value: v}
+	}
+	b := types.NewRecordBatch(baseOffset, timestamps, entries)
+	b.Attributes = attributes
+	data, err := b.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return data
+}
+
+type imageState struct {
+	Offset   int64
+	Topics   []Topic
+	Configs  map[string]string
+	Features map[string]int16
+}
+
+func stateOf(m *Image) imageState {
+	return imageState{
+		Offset:   m.Offset(),
+		Topics:   m.Topics(),
+		Configs:  m.Configs(ConfigResource{Type: TopicResource, Name: "orders"}),
+		Features: m.Features(),
+	}
+}
+
+func TestStoreReplay(t *testing.T) {
+	dir := t.TempDir()
+	s := openStore(t, dir)
+	if got := s.Offset(); got != -1 {
+		t.Fatalf("empty store at offset %d, want -1", got)
+	}
+	id := types.NewUUID()
+	update(t, s,
+		TopicRecord{Name: "orders", TopicID: id},
+		PartitionRecord{TopicID: id, Replicas: []int32{1}, ISR: []int32{1}, RemovingReplicas: []int32{}, AddingReplicas: []int32{}, Leader: 1},
+		ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: "retention.ms", Value: types.NullableStringOf("1000")},
+	)
+	update(t, s, ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: "retention.ms", Value: types.NullableStringOf("2000")})
+
+	// Records the broker never writes itself, as a controller would.
+	change := PartitionChangeRecord{TopicID: id, ISR: []int32{1}, Leader: 1, LeaderRecoveryState: -1}
+	value := encodeFrame(recordFrameVersion, PartitionChangeRecordType, 0, func(e *types.Encoder) {
+		e.Int32(0)
+		e.UUID(id)
+		e.TaggedFields(types.TaggedFields{Fields: map[uint64][]byte{
+			changeLeaderTag: encodeTag(func(e *types.Encoder) { e.Int32(1) }),
+		}})
+	})
+	info, err := s.log.Append(valueBatch(t, 0, 0, value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Apply(info.FirstOffset, change)
+	// A control batch, whose records are not metadata records.
+	if _, err := s.log.Append(valueBatch(t, 0, types.ControlFlagMask, []byte{0, 0, 0, 0})); err != nil {
+		t.Fatal(err)
+	}
+	update(t, s, FeatureLevelRecord{Name: "metadata.version", FeatureLevel: 21})
+
+	want := stateOf(s.Image)
+	if want.Offset != 6 || want.Topics[0].Partitions[0].LeaderEpoch != 1 {
+		t.Fatalf("before reopening: %+v", want)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := stateOf(openStore(t, dir).Image); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayed image:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s := openStore(t, dir)
+	id := types.NewUUID()
+	orders := func(name, value string) ConfigRecord {
+		return ConfigRecord{ResourceType: TopicResource, ResourceName: "orders", Name: name, Value: types.NullableStringOf(value)}
+	}
+	update(t, s, TopicRecord{Name: "orders", TopicID: id})
+	update(t, s, orders("segment.ms", "1"))
+	update(t, s, orders("retention.ms", "1"))
+	update(t, s, orders("retention.ms", "2"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The snapshot covers offsets 0 and 1 with a different history; only
+	// the log records from offset 2 on apply on top of it.
+	snapshot := encodeBatch(t, 0, TopicRecord{Name: "orders", TopicID: id}, orders("cleanup.policy", "compact"))
+	name := fmt.Sprintf("%020d-%010d%s", 2, 1, SnapshotFileSuffix)
+	if err := os.WriteFile(filepath.Join(s.log.Dir, name), snapshot, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s = openStore(t, dir)
+	want := map[string]string{"cleanup.policy": "compact", "retention.ms": "2"}
+	if got := s.Configs(ConfigResource{Type: TopicResource, Name: "orders"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Configs = %v, want %v", got, want)
+	}
+	if got := s.Offset(); got != 3 {
+		t.Fatalf("Offset = %d, want 3", got)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type OffsetCommitHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewOffsetCommitHandler(broker *Broker) *OffsetCommitHandler {
+	return &OffsetCommitHandler{FlexibleSince: 8, broker: broker}
+}
+
+func (h *OffsetCommitHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseOffsetCommit(r, version)
+}
+
+// Handle persists the offsets of the partitions that exist in a single
+// append to __consumer_offsets. retention_time_ms is ignored: committed
+// offsets are kept until deleted.
+func (h *OffsetCommitHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.OffsetCommit)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	commit := group.CommitRequest{
+		GroupID:    rb.GroupID,
+		MemberID:   rb.MemberID,
+		InstanceID: rb.GroupInstanceID,
+		Generation: rb.GenerationID,
+		Offsets:    make(map[log.TopicPartition]group.Offset),
+	}
+	unknown := make(map[log.TopicPartition]bool)
+	for _, topic := range rb.Topics {
+		t, exists := h.broker.Metadata.Topic(topic.Name)
+		for _, p := range topic.Partitions {
+			tp := log.TopicPartition{Topic: topic.Name, Partition: p.PartitionIndex}
+			if !exists || !hasPartition(t, p.PartitionIndex) {
+				unknown[tp] = true
+				continue
+			}
+			o := group.Offset{Offset: p.CommittedOffset, LeaderEpoch: p.CommittedLeaderEpoch}
+			if p.CommittedMetadata != nil {
+				o.Metadata = *p.CommittedMetadata
+			}
+			commit.Offsets[tp] = o
+		}
+	}
+	refused, err := h.broker.Groups.Commit(commit)
+
+	resp := &responses.OffsetCommit{Version: rb.Version, Topics: []responses.OffsetCommitTopicResult{}}
+	for _, topic := range rb.Topics {
+		rt := responses.OffsetCommitTopicResult{Name: topic.Name, Partitions: []responses.OffsetCommitPartitionResult{}}
+		for _, p := range topic.Partitions {
+			tp := log.TopicPartition{Topic: topic.Name, Partition: p.PartitionIndex}
+			rp := responses.OffsetCommitPartitionResult{PartitionIndex: p.PartitionIndex}
+			switch {
+			case unknown[tp]:
+				rp.ErrorCode = UNKNOWN_TOPIC_OR_PARTITION
+			case err != nil:
+				rp.ErrorCode = groupErrorCode(err)
+			default:
+				rp.ErrorCode = groupErrorCode(refused[tp])
+			}
+			rt.Partitions = append(rt.Partitions, rp)
+		}
+		resp.Topics = append(resp.Topics, rt)
+	}
+	return resp, nil
+}
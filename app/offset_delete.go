@@ -0,0 +1,67 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type OffsetDeleteHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewOffsetDeleteHandler(broker *Broker) *OffsetDeleteHandler {
+	return &OffsetDeleteHandler{FlexibleSince: -1, broker: broker}
+}
+
+func (h *OffsetDeleteHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseOffsetDelete(r, version)
+}
+
+// Handle deletes committed offsets of a group. Offsets of topics its
+// consumers subscribe to are kept with GROUP_SUBSCRIBED_TO_TOPIC.
+func (h *OffsetDeleteHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.OffsetDelete)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	var partitions []log.TopicPartition
+	unknown := make(map[log.TopicPartition]bool)
+	for _, topic := range rb.Topics {
+		t, exists := h.broker.Metadata.Topic(topic.Name)
+		for _, p := range topic.Partitions {
+			tp := log.TopicPartition{Topic: topic.Name, Partition: p.PartitionIndex}
+			if !exists || !hasPartition(t, p.PartitionIndex) {
+				unknown[tp] = true
+				continue
+			}
+			partitions = append(partitions, tp)
+		}
+	}
+	resp := &responses.OffsetDelete{Version: rb.Version, Topics: []responses.OffsetDeleteTopicResult{}}
+	refused, err := h.broker.Groups.DeleteOffsets(rb.GroupID, partitions)
+	if err != nil {
+		resp.ErrorCode = groupErrorCode(err)
+		return resp, nil
+	}
+	for _, topic := range rb.Topics {
+		rt := responses.OffsetDeleteTopicResult{Name: topic.Name, Partitions: []responses.OffsetDeletePartitionResult{}}
+		for _, p := range topic.Partitions {
+			tp := log.TopicPartition{Topic: topic.Name, Partition: p.PartitionIndex}
+			rp := responses.OffsetDeletePartitionResult{PartitionIndex: p.PartitionIndex}
+			if unknown[tp] {
+				rp.ErrorCode = UNKNOWN_TOPIC_OR_PARTITION
+			} else {
+				rp.ErrorCode = groupErrorCode(refused[tp])
+			}
+			rt.Partitions = append(rt.Partitions, rp)
+		}
+		resp.Topics = append(resp.Topics, rt)
+	}
+	return resp, nil
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type OffsetFetchHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewOffsetFetchHandler(broker *Broker) *OffsetFetchHandler {
+	return &OffsetFetchHandler{FlexibleSince: 6, broker: broker}
+}
+
+func (h *OffsetFetchHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseOffsetFetch(r, version)
+}
+
+// Handle returns the committed offsets of every group asked for.
+// Partitions without a committed offset get -1. Version 1 has no group
+// level error, so it is repeated on every partition instead.
+func (h *OffsetFetchHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.OffsetFetch)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.OffsetFetch{Version: rb.Version, Groups: []responses.OffsetFetchGroupResult{}}
+	for _, g := range rb.FetchGroups() {
+		resp.Groups = append(resp.Groups, h.broker.fetchOffsets(g, rb.Version < 2))
+	}
+	if rb.Version < 8 {
+		resp.Topics, resp.ErrorCode = resp.Groups[0].Topics, resp.Groups[0].ErrorCode
+		resp.Groups = nil
+	}
+	return resp, nil
+}
+
+func (b *Broker) fetchOffsets(g requests.OffsetFetchGroup, partitionErrors bool) responses.OffsetFetchGroupResult {
+	result := responses.OffsetFetchGroupResult{GroupID: g.GroupID, Topics: []responses.OffsetFetchTopicResult{}}
+	var partitions []log.TopicPartition
+	if g.Topics != nil {
+		partitions = []log.TopicPartition{}
+		for _, t := range g.Topics {
+			for _, p := range t.PartitionIndexes {
+				partitions = append(partitions, log.TopicPartition{Topic: t.Name, Partition: p})
+			}
+		}
+	}
+	offsets, err := b.Groups.Fetch(g.GroupID, partitions)
+	if err != nil {
+		result.ErrorCode = groupErrorCode(err)
+		if !partitionErrors {
+			return result
+		}
+	}
+	if partitions == nil {
+		for tp := range offsets {
+			partitions = append(partitions, tp)
+		}
+		slices.SortFunc(partitions, func(a, b log.TopicPartition) int {
+			return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+		})
+	}
+	for _, tp := range partitions {
+		noMetadata := ""
+		rp := responses.OffsetFetchPartitionResult{
+			PartitionIndex:       tp.Partition,
+			CommittedOffset:      -1,
+			CommittedLeaderEpoch: -1,
+			Metadata:             &noMetadata,
+			ErrorCode:            result.ErrorCode,
+		}
+		if o, ok := offsets[tp]; ok {
+			rp.CommittedOffset, rp.CommittedLeaderEpoch, rp.Metadata = o.Offset, o.LeaderEpoch, &o.Metadata
+		}
+		if n := len(result.Topics); n == 0 || result.Topics[n-1].Name != tp.Topic {
+			result.Topics = append(result.Topics, responses.OffsetFetchTopicResult{Name: tp.Topic, Partitions: []responses.OffsetFetchPartitionResult{}})
+		}
+		last := &result.Topics[len(result.Topics)-1]
+		last.Partitions = append(last.Partitions, rp)
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nabinkhanal00/kafka/app/compression"
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+type ProduceHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewProduceHandler(broker *Broker) *ProduceHandler {
+	return &ProduceHandler{FlexibleSince: 9, broker: broker}
+}
+
+func (h *ProduceHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseProduce(r, version)
+}
+
+// Handle appends every partition's batch. With acks=0 the client does not
+// wait for an answer, so no response is produced at all.
+func (h *ProduceHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.Produce)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	validAcks := rb.Acks == -1 || rb.Acks == 0 || rb.Acks == 1
+	resp := &responses.Produce{Version: rb.Version}
+	for _, topic := range rb.Topics {
+		rt := responses.ProduceTopic{Name: topic.Name}
+		for _, partition := range topic.Partitions {
+			var rp responses.ProducePartition
+			if validAcks {
+				rp = h.broker.appendRecords(topic.Name, partition.Index, rb.Version, partition.Records)
+			} else {
+				rp = failedProducePartition(partition.Index, INVALID_REQUIRED_ACKS, "acks must be -1, 0 or 1")
+			}
+			rt.Partitions = append(rt.Partitions, rp)
+		}
+		resp.Responses = append(resp.Responses, rt)
+	}
+	if rb.Acks == 0 {
+		return nil, nil
+	}
+	return resp, nil
+}
+
+func failedProducePartition(index int32, errorCode int16, message string) responses.ProducePartition {
+	return responses.ProducePartition{
+		Index:           index,
+		ErrorCode:       errorCode,
+		BaseOffset:      -1,
+		LogAppendTimeMs: -1,
+		LogStartOffset:  -1,
+		ErrorMessage:    &message,
+	}
+}
+
+// appendRecords validates a produced record set, recompresses it when the
+// topic's compression.type asks for a different codec and appends it to
+// the partition log. With a single broker the leader is the whole ISR, so
+// acks=1 and acks=-1 complete at the same point.
+func (b *Broker) appendRecords(topic string, partition int32, version int16, records []byte) responses.ProducePartition {
+	l, ok, err := b.partitionLog(topic, partition)
+	if err != nil {
+		return failedProducePartition(partition, KAFKA_STORAGE_ERROR, err.Error())
+	}
+	if !ok {
+		return failedProducePartition(partition, UNKNOWN_TOPIC_OR_PARTITION, "this server does not host this topic-partition")
+	}
+	batches, err := log.SplitBatches(records)
+	if err != nil {
+		return failedProducePartition(partition, CORRUPT_MESSAGE, err.Error())
+	}
+	if len(batches) != 1 {
+		return failedProducePartition(partition, INVALID_RECORD, "produce requests must contain exactly one record batch")
+	}
+	batch := batches[0]
+	configs := b.topicConfigValues(topic)
+	if maxMessageBytes := topicConfig[int32](configs, "max.message.bytes"); len(batch) > int(maxMessageBytes) {
+		return failedProducePartition(partition, MESSAGE_TOO_LARGE,
+			fmt.Sprintf("batch of %d bytes exceeds max.message.bytes %d", len(batch), maxMessageBytes))
+	}
+	header, err := log.ValidateBatch(batch)
+	if err != nil {
+		if errors.Is(err, log.ErrUnsupportedMagic) {
+			return failedProducePartition(partition, INVALID_RECORD, err.Error())
+		}
+		return failedProducePartition(partition, CORRUPT_MESSAGE, err.Error())
+	}
+	codec := compression.Codec(header.Attributes & types.CompressionCodecMask)
+	if codec == compression.Zstd && version < 7 {
+		return failedProducePartition(partition, UNSUPPORTED_COMPRESSION_TYPE, "zstd compression requires produce version 7 or later")
+	}
+	decoded, err := types.DecodeRecordBatch(batch)
+	if err != nil {
+		if errors.Is(err, types.ErrUnsupportedCompression) {
+			return failedProducePartition(partition, UNSUPPORTED_COMPRESSION_TYPE, err.Error())
+		}
+		return failedProducePartition(partition, CORRUPT_MESSAGE, err.Error())
+	}
+	if err := validateRecords(decoded); err != nil {
+		return failedProducePartition(partition, INVALID_RECORD, err.Error())
+	}
+	target, ok, err := compression.ParseType(topicConfig[string](configs, "compression.type"))
+	if err != nil {
+		return failedProducePartition(partition, UNSUPPORTED_COMPRESSION_TYPE, err.Error())
+	}
+	if ok && target != codec {
+		decoded.Attributes = decoded.Attributes&^types.CompressionCodecMask | int16(target)
+		if batch, err = decoded.Encode(); err != nil {
+			return failedProducePartition(partition, KAFKA_STORAGE_ERROR, err.Error())
+		}
+	}
+
+	logAppendTime := int64(-1)
+	if topicConfig[string](configs, "message.timestamp.type") == "LogAppendTime" {
+		logAppendTime = time.Now().UnixMilli()
+		log.SetLogAppendTime(batch, logAppendTime)
+	}
+	info, err := l.Append(batch)
+	if err != nil {
+		if errors.Is(err, log.ErrRecordTooLarge) {
+			return failedProducePartition(partition, RECORD_LIST_TOO_LARGE, err.Error())
+		}
+		return failedProducePartition(partition, KAFKA_STORAGE_ERROR, err.Error())
+	}
+	return responses.ProducePartition{
+		Index:           partition,
+		ErrorCode:       NONE,
+		BaseOffset:      info.FirstOffset,
+		LogAppendTimeMs: logAppendTime,
+		LogStartOffset:  l.LogStartOffset(),
+	}
+}
+
+// validateRecords applies the checks upstream runs on produced batches:
+// clients may not write control batches and inner offsets must be the
+// consecutive deltas 0..n-1.
+func validateRecords(batch *types.RecordBatch) error {
+	if batch.IsControl() {
+		return fmt.Errorf("clients are not allowed to write control records")
+	}
+	if len(batch.Records) == 0 {
+		return fmt.Errorf("record batch has no records")
+	}
+	if int(batch.LastOffsetDelta) != len(batch.Records)-1 {
+		return fmt.Errorf("last offset delta %d does not match %d records", batch.LastOffsetDelta, len(batch.Records))
+	}
+	for i, r := range batch.Records {
+		if int(r.OffsetDelta) != i {
+			return fmt.Errorf("inner record offsets are not consecutive: expected delta %d, got %d", i, r.OffsetDelta)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/nabinkhanal00/kafka/app/compression"
+	"github.com/nabinkhanal00/kafka/app/config"
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// newTestBroker starts a broker on a fresh log directory with props on top
+// of the defaults.
+func newTestBroker(t *testing.T, props map[string]string) *Broker {
+	t.Helper()
+	all := map[string]string{"log.dirs": t.TempDir()}
+	for k, v := range props {
+		all[k] = v
+	}
+	cfg, err := config.New(all)
+	if err != nil {
+		t.Fatalf("config.New: %v", err)
+	}
+	b, err := NewBroker(cfg)
+	if err != nil {
+		t.Fatalf("NewBroker: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func createPartition(t *testing.T, b *Broker, topic string, partition int32) *log.Log {
+	t.Helper()
+	l, err := b.Logs.GetOrCreate(log.TopicPartition{Topic: topic, Partition: partition})
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	return l
+}
+
+// recordBatch encodes an uncompressed batch of n records, optionally
+// changed by edit before encoding.
+func recordBatch(n int, edit ...func(*types.RecordBatch)) []byte {
+	timestamps := make([]int64, n)
+	records := make([]types.Record, n)
+	for i := range records {
+		timestamps[i] = 1000 + int64(i)
+		records[i] = types.Record{Key: []byte{byte(i)}, Value: []byte("value")}
+	}
+	batch := types.NewRecordBatch(0, timestamps, records)
+	for _, f := range edit {
+		f(batch)
+	}
+	data, err := batch.Encode()
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func produce(t *testing.T, b *Broker, acks int16, version int16, records []byte) *responses.Produce {
+	t.Helper()
+	req := &Request{Body: &requests.Produce{
+		Version: version,
+		Acks:    acks,
+		Topics: []requests.ProduceTopic{{
+			Name:       "t",
+			Partitions: []requests.ProducePartition{{Index: 0, Records: records}},
+		}},
+	}}
+	body, err := NewProduceHandler(b).Handle(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if body == nil {
+		return nil
+	}
+	return body.(*responses.Produce)
+}
+
+// producePartition produces records and returns the single partition
+// response.
+func producePartition(t *testing.T, b *Broker, version int16, records []byte) responses.ProducePartition {
+	t.Helper()
+	resp := produce(t, b, -1, version, records)
+	if len(resp.Responses) != 1 || len(resp.Responses[0].Partitions) != 1 {
+		t.Fatalf("response %+v does not hold exactly one partition", resp)
+	}
+	return resp.Responses[0].Partitions[0]
+}
+
+func TestProduce(t *testing.T) {
+	b := newTestBroker(t, nil)
+	l := createPartition(t, b, "t", 0)
+	for _, want := range []int64{0, 3} {
+		rp := producePartition(t, b, 9, recordBatch(3))
+		if rp.ErrorCode != NONE || rp.BaseOffset != want || rp.LogAppendTimeMs != -1 {
+			t.Fatalf("got %+v, want base offset %d", rp, want)
+		}
+	}
+	if got := l.LogEndOffset(); got != 6 {
+		t.Fatalf("LogEndOffset = %d, want 6", got)
+	}
+}
+
+func TestProduceRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]string
+		records []byte
+		want    int16
+	}{
+		{"several batches", nil, append(recordBatch(1), recordBatch(1)...), INVALID_RECORD},
+		{"truncated batch", nil, recordBatch(2)[:70], CORRUPT_MESSAGE},
+		{"crc mismatch", nil, append(recordBatch(1)[:len(recordBatch(1))-1], 'x'), CORRUPT_MESSAGE},
+		{"control batch", nil, recordBatch(1, func(b *types.RecordBatch) { b.Attributes |= types.ControlFlagMask }), INVALID_RECORD},
+		{"inner offsets", nil, recordBatch(2, func(b *types.RecordBatch) { b.Records[1].OffsetDelta = 5 }), INVALID_RECORD},
+		{"last offset delta", nil, recordBatch(2, func(b *types.RecordBatch) { b.LastOffsetDelta = 2 }), INVALID_RECORD},
+		{"message.max.bytes", map[string]string{"message.max.bytes": "100"}, recordBatch(10), MESSAGE_TOO_LARGE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBroker(t, tt.props)
+			l := createPartition(t, b, "t", 0)
+			rp := producePartition(t, b, 9, tt.records)
+			if rp.ErrorCode != tt.want || rp.BaseOffset != -1 {
+				t.Fatalf("got error %d and base offset %d, want error %d", rp.ErrorCode, rp.BaseOffset, tt.want)
+			}
+			if got := l.LogEndOffset(); got != 0 {
+				t.Fatalf("LogEndOffset = %d after a rejected produce", got)
+			}
+		})
+	}
+}
+
+func TestProduceMessageMaxBytesBoundary(t *testing.T) {
+	records := recordBatch(2)
+	b := newTestBroker(t, map[string]string{"message.max.bytes": strconv.Itoa(len(records))})
+	createPartition(t, b, "t", 0)
+	if rp := producePartition(t, b, 9, records); rp.ErrorCode != NONE {
+		t.Fatalf("batch of exactly message.max.bytes: error %d", rp.ErrorCode)
+	}
+}
+
+func TestProduceUnknownPartition(t *testing.T) {
+	b := newTestBroker(t, nil)
+	if rp := producePartition(t, b, 9, recordBatch(1)); rp.ErrorCode != UNKNOWN_TOPIC_OR_PARTITION {
+		t.Fatalf("got error %d, want UNKNOWN_TOPIC_OR_PARTITION", rp.ErrorCode)
+	}
+}
+
+func TestProduceAcks(t *testing.T) {
+	b := newTestBroker(t, nil)
+	l := createPartition(t, b, "t", 0)
+	if resp := produce(t, b, 0, 9, recordBatch(1)); resp != nil {
+		t.Fatalf("acks=0 produced a response: %+v", resp)
+	}
+	if got := l.LogEndOffset(); got != 1 {
+		t.Fatalf("LogEndOffset after acks=0 = %d, want 1", got)
+	}
+	resp := produce(t, b, 2, 9, recordBatch(1))
+	if rp := resp.Responses[0].Partitions[0]; rp.ErrorCode != INVALID_REQUIRED_ACKS {
+		t.Fatalf("acks=2: got error %d, want INVALID_REQUIRED_ACKS", rp.ErrorCode)
+	}
+	if got := l.LogEndOffset(); got != 1 {
+		t.Fatalf("LogEndOffset after acks=2 = %d, want 1", got)
+	}
+}
+
+func TestProduceCompression(t *testing.T) {
+	zstd := recordBatch(2, func(b *types.RecordBatch) { b.Attributes = int16(compression.Zstd) })
+	tests := []struct {
+		name    string
+		version int16
+		records []byte
+		want    int16
+	}{
+		{"zstd before v7", 6, zstd, UNSUPPORTED_COMPRESSION_TYPE},
+		{"zstd from v7", 7, zstd, NONE},
+		{"gzip before v7", 6, recordBatch(2, func(b *types.RecordBatch) { b.Attributes = int16(compression.Gzip) }), NONE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBroker(t, nil)
+			createPartition(t, b, "t", 0)
+			if rp := producePartition(t, b, tt.version, tt.records); rp.ErrorCode != tt.want {
+				t.Fatalf("got error %d, want %d", rp.ErrorCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestProduceRecompresses(t *testing.T) {
+	b := newTestBroker(t, map[string]string{"compression.type": "lz4"})
+	l := createPartition(t, b, "t", 0)
+	if rp := producePartition(t, b, 9, recordBatch(3)); rp.ErrorCode != NONE {
+		t.Fatalf("got error %d", rp.ErrorCode)
+	}
+	data, err := l.Read(0, 1<<20, true)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	stored, err := types.DecodeRecordBatch(data)
+	if err != nil {
+		t.Fatalf("DecodeRecordBatch: %v", err)
+	}
+	if codec := compression.Codec(stored.Compression()); codec != compression.LZ4 || len(stored.Records) != 3 {
+		t.Fatalf("stored %d records compressed with %v, want 3 with lz4", len(stored.Records), codec)
+	}
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+var ErrUnknownAPIKey = errors.New("unknown api key")
+
+// Handler implements a single API over a range of versions. It owns the
+// parsing of the request body, producing the response body and picking
+// the response header version the client expects.
+type Handler interface {
+	ParseRequest(version int16, r *bytes.Reader) (RequestBody, error)
+	// Handle returns the response body for req. A nil body means that no
+	// response frame is sent at all.
+	Handle(ctx context.Context, req *Request) (ResponseBody, error)
+	RequestHeaderVersion(version int16) int16
+	ResponseHeaderVersion(version int16) int16
+}
+
+// FlexibleSince implements the header selection of Handler for APIs whose
+// versions from the given one onwards are flexible: request header v2 and
+// response header v1 for flexible versions, v1 and v0 otherwise. A value
+// of -1 means no version is flexible.
+type FlexibleSince int16
+
+func (f FlexibleSince) Flexible(version int16) bool {
+	return f >= 0 && version >= int16(f)
+}
+
+func (f FlexibleSince) RequestHeaderVersion(version int16) int16 {
+	if f.Flexible(version) {
+		return 2
+	}
+	return 1
+}
+
+func (f FlexibleSince) ResponseHeaderVersion(version int16) int16 {
+	if f.Flexible(version) {
+		return 1
+	}
+	return 0
+}
+
+// Parker is implemented by handlers whose requests may wait for an event,
+// such as long polling fetches. Connections hand those requests to their
+// own goroutine so that they do not hold up the requests behind them.
+type Parker interface {
+	Parks(req *Request) bool
+}
+
+type registration struct {
+	minVersion int16
+	maxVersion int16
+	handler    Handler
+}
+
+// Registry maps (api key, version range) to the Handler serving it.
+type Registry struct {
+	handlers map[int16][]registration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[int16][]registration)}
+}
+
+// Register adds h for apiKey in the inclusive range [minVersion, maxVersion].
+// Ranges for the same key must not overlap.
+func (r *Registry) Register(apiKey, minVersion, maxVersion int16, h Handler) {
+	if minVersion > maxVersion {
+		panic(fmt.Sprintf("invalid version range %d..%d for api key %d", minVersion, maxVersion, apiKey))
+	}
+	for _, reg := range r.handlers[apiKey] {
+		if minVersion <= reg.maxVersion && reg.minVersion <= maxVersion {
+			panic(fmt.Sprintf("overlapping version range %d..%d for api key %d", minVersion, maxVersion, apiKey))
+		}
+	}
+	r.handlers[apiKey] = append(r.handlers[apiKey], registration{
+		minVersion: minVersion,
+		maxVersion: maxVersion,
+		handler:    h,
+	})
+}
+
+// Lookup returns the handler serving apiKey at version.
+func (r *Registry) Lookup(apiKey, version int16) (Handler, bool) {
+	for _, reg := range r.handlers[apiKey] {
+		if version >= reg.minVersion && version <= reg.maxVersion {
+			return reg.handler, true
+		}
+	}
+	return nil, false
+}
+
+// Supports reports whether any handler is registered for apiKey at version.
+func (r *Registry) Supports(apiKey, version int16) bool {
+	_, ok := r.Lookup(apiKey, version)
+	return ok
+}
+
+// RequestHeaderVersion picks the request header version for apiKey at
+// version. Versions newer than any registered handler are assumed to
+// follow the newest handler, which lets ApiVersions requests from newer
+// clients be read far enough to answer them.
+func (r *Registry) RequestHeaderVersion(apiKey, version int16) int16 {
+	if h, ok := r.Lookup(apiKey, version); ok {
+		return h.RequestHeaderVersion(version)
+	}
+	regs := r.handlers[apiKey]
+	if len(regs) == 0 {
+		return 1
+	}
+	newest := regs[0]
+	for _, reg := range regs[1:] {
+		if reg.maxVersion > newest.maxVersion {
+			newest = reg
+		}
+	}
+	return newest.handler.RequestHeaderVersion(version)
+}
+
+// APIKeys lists every registered api key with the overall version range
+// it supports, sorted by key, in the shape ApiVersions advertises.
+func (r *Registry) APIKeys() []responses.APIKey {
+	keys := make([]responses.APIKey, 0, len(r.handlers))
+	for apiKey, regs := range r.handlers {
+		k := responses.APIKey{Key: apiKey, MinVersion: regs[0].minVersion, MaxVersion: regs[0].maxVersion}
+		for _, reg := range regs[1:] {
+			k.MinVersion = min(k.MinVersion, reg.minVersion)
+			k.MaxVersion = max(k.MaxVersion, reg.maxVersion)
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys
+}
+
+// Parks reports whether the handler of req may park it; see Parker.
+func (r *Registry) Parks(req *Request) bool {
+	h, ok := r.Lookup(req.Header.GetAPIKey(), req.Header.GetAPIVersion())
+	if !ok {
+		return false
+	}
+	p, ok := h.(Parker)
+	return ok && p.Parks(req)
+}
+
+// Handle dispatches req to its handler. It returns a nil response when
+// the handler decided that no response frame must be written.
+func (r *Registry) Handle(ctx context.Context, req *Request) (*Response, error) {
+	apiKey, version := req.Header.GetAPIKey(), req.Header.GetAPIVersion()
+	h, ok := r.Lookup(apiKey, version)
+	if !ok {
+		// ApiVersions must answer even versions it does not know about,
+		// so that the client can learn what to downgrade to.
+		regs := r.handlers[apiKey]
+		if apiKey != ApiVersions || len(regs) == 0 {
+			return nil, fmt.Errorf("%w: %d version %d", ErrUnknownAPIKey, apiKey, version)
+		}
+		h = regs[0].handler
+	}
+	body, err := h.Handle(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, nil
+	}
+	return &Response{
+		Header: NewResponseHeader(h.ResponseHeaderVersion(version), req.Header.GetCorrelationID()),
+		Body:   body,
+	}, nil
+}
@@ -6,31 +6,45 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/nabinkhanal00/kafka/app/requests"
 	"github.com/nabinkhanal00/kafka/app/types"
 )
 
 type RequestHeader interface {
 	Write(io.Writer) error
 	GetAPIKey() int16
+	GetAPIVersion() int16
+	GetCorrelationID() int32
+	GetClientID() string
 }
 type RequestBody interface {
 	Write(io.Writer) error
 }
 
-type RequestHeaderV2 struct {
+// RequestHeaderV1 is used by every non-flexible request version.
+type RequestHeaderV1 struct {
 	RequestAPIKey     int16                `desc:"request_api_key"`
 	RequestAPIVersion int16                `desc:"request_api_version"`
 	CorrelationID     int32                `desc:"correlation_id"`
 	ClientID          types.NullableString `desc:"client_id"`
-	TaggedFields      types.TaggedFields   `desc:"_tagged_fields"`
 }
 
-func (rh *RequestHeaderV2) GetAPIKey() int16 {
+func (rh *RequestHeaderV1) GetAPIKey() int16 {
 	return rh.RequestAPIKey
 }
 
-func (rh *RequestHeaderV2) Write(w io.Writer) error {
+func (rh *RequestHeaderV1) GetAPIVersion() int16 {
+	return rh.RequestAPIVersion
+}
+
+func (rh *RequestHeaderV1) GetCorrelationID() int32 {
+	return rh.CorrelationID
+}
+
+func (rh *RequestHeaderV1) GetClientID() string {
+	return string(rh.ClientID)
+}
+
+func (rh *RequestHeaderV1) Write(w io.Writer) error {
 	if err := binary.Write(w, binary.BigEndian, rh.RequestAPIKey); err != nil {
 		return err
 	}
@@ -40,18 +54,42 @@ func (rh *RequestHeaderV2) Write(w io.Writer) error {
 	if err := binary.Write(w, binary.BigEndian, rh.CorrelationID); err != nil {
 		return err
 	}
-	if err := rh.ClientID.Write(w); err != nil {
+	return rh.ClientID.Write(w)
+}
+
+// RequestHeaderV2 is used by flexible request versions and adds a tagged
+// field section after the client id.
+type RequestHeaderV2 struct {
+	RequestHeaderV1
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (rh *RequestHeaderV2) Write(w io.Writer) error {
+	if err := rh.RequestHeaderV1.Write(w); err != nil {
 		return err
 	}
 	return rh.TaggedFields.Write(w)
 }
 
-func ParseRequestHeader(r *bytes.Reader) (RequestHeader, error) {
-	return ParseRequestHeaderV2(r)
+// ParseRequestHeader reads the common header fields and, when the registry
+// says the api key and version are flexible, the tagged field section.
+func ParseRequestHeader(r *bytes.Reader, reg *Registry) (RequestHeader, error) {
+	rh, err := ParseRequestHeaderV1(r)
+	if err != nil {
+		return nil, err
+	}
+	if reg.RequestHeaderVersion(rh.RequestAPIKey, rh.RequestAPIVersion) < 2 {
+		return rh, nil
+	}
+	tfs, err := types.ParseTaggedFields(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse tagged fields: %w", err)
+	}
+	return &RequestHeaderV2{RequestHeaderV1: *rh, TaggedFields: *tfs}, nil
 }
 
-func ParseRequestHeaderV2(r *bytes.Reader) (*RequestHeaderV2, error) {
-	var rh RequestHeaderV2
+func ParseRequestHeaderV1(r *bytes.Reader) (*RequestHeaderV1, error) {
+	var rh RequestHeaderV1
 	if err := binary.Read(r, binary.BigEndian, &rh.RequestAPIKey); err != nil {
 		return nil, fmt.Errorf("cannot read api key: %w", err)
 	}
@@ -66,18 +104,30 @@ func ParseRequestHeaderV2(r *bytes.Reader) (*RequestHeaderV2, error) {
 		return nil, fmt.Errorf("cannot parse nullable string: %w", err)
 	}
 	rh.ClientID = *ci
+	return &rh, nil
+}
+
+func ParseRequestHeaderV2(r *bytes.Reader) (*RequestHeaderV2, error) {
+	rh, err := ParseRequestHeaderV1(r)
+	if err != nil {
+		return nil, err
+	}
 	tfs, err := types.ParseTaggedFields(r)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse tagged fields: %w", err)
 	}
-	rh.TaggedFields = *tfs
-	return &rh, nil
+	return &RequestHeaderV2{RequestHeaderV1: *rh, TaggedFields: *tfs}, nil
 }
 
 type Request struct {
 	MessageSize int32         `desc:"message_size"`
 	Header      RequestHeader `desc:"request_header"`
 	Body        RequestBody   `desc:"data"`
+	// Listener is the name of the listener the request arrived on.
+	Listener string `desc:"-"`
+	// ClientHost is the address of the client, formatted like upstream
+	// as /<ip>.
+	ClientHost string `desc:"-"`
 }
 
 func MarshallRequest(r Request) []byte {
@@ -88,28 +138,24 @@ func MarshallRequest(r Request) []byte {
 	return buf.Bytes()
 }
 
-func UnmarshallRequest(b []byte) (Request, error) {
+// UnmarshallRequest decodes a whole frame, message_size included. The body
+// is parsed by the handler registered for the api key and version; when
+// no handler serves that version the body is left nil.
+func UnmarshallRequest(b []byte, reg *Registry) (Request, error) {
 	buf := bytes.NewReader(b)
 	var req Request
 	if err := binary.Read(buf, binary.BigEndian, &req.MessageSize); err != nil {
 		return req, err
 	}
-	header, err := ParseRequestHeader(buf)
+	header, err := ParseRequestHeader(buf, reg)
 	if err != nil {
 		return req, err
 	}
 	req.Header = header
-	req.Body, err = ParseRequestBody(header, buf)
-	return req, err
-}
-
-func ParseRequestBody(h RequestHeader, r *bytes.Reader) (RequestBody, error) {
-	switch h.GetAPIKey() {
-	case ApiVersions:
-		return requests.ParseAPIVersionsV4(r)
-	case DescribeTopicPartitions:
-		return requests.ParseDescribeTopicPartitionsV0(r)
-	default:
-		return nil, nil
+	h, ok := reg.Lookup(header.GetAPIKey(), header.GetAPIVersion())
+	if !ok {
+		return req, nil
 	}
+	req.Body, err = h.ParseRequest(header.GetAPIVersion(), buf)
+	return req, err
 }
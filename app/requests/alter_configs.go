@@ -0,0 +1,72 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// AlterConfigs covers versions 0 through 2; version 2 onwards is flexible.
+// Every dynamic config of a resource that is not listed is removed.
+type AlterConfigs struct {
+	Version      int16                  `desc:"-"`
+	Resources    []AlterConfigsResource `desc:"resources"`
+	ValidateOnly bool                   `desc:"validate_only"`
+	TaggedFields types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+type AlterConfigsResource struct {
+	ResourceType int8               `desc:"resource_type"`
+	ResourceName string             `desc:"resource_name"`
+	Configs      []AlterableConfig  `desc:"configs"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type AlterableConfig struct {
+	Name         string             `desc:"name"`
+	Value        *string            `desc:"value"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseAlterConfigs(r *bytes.Reader, version int16) (*AlterConfigs, error) {
+	d := types.NewDecoder(r, version >= 2)
+	c := &AlterConfigs{Version: version}
+	c.Resources = types.DecodeArray(d, func(d *types.Decoder) AlterConfigsResource {
+		var res AlterConfigsResource
+		res.ResourceType = d.Int8()
+		res.ResourceName = d.String()
+		res.Configs = types.DecodeArray(d, func(d *types.Decoder) AlterableConfig {
+			return AlterableConfig{
+				Name:         d.String(),
+				Value:        d.NullableString(),
+				TaggedFields: d.TaggedFields(),
+			}
+		})
+		res.TaggedFields = d.TaggedFields()
+		return res
+	})
+	c.ValidateOnly = d.Bool()
+	c.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *AlterConfigs) Write(w io.Writer) error {
+	e := types.NewEncoder(w, c.Version >= 2)
+	types.EncodeArray(e, c.Resources, func(e *types.Encoder, res AlterConfigsResource) {
+		e.Int8(res.ResourceType)
+		e.String(res.ResourceName)
+		types.EncodeArray(e, res.Configs, func(e *types.Encoder, cfg AlterableConfig) {
+			e.String(cfg.Name)
+			e.NullableString(cfg.Value)
+			e.TaggedFields(cfg.TaggedFields)
+		})
+		e.TaggedFields(res.TaggedFields)
+	})
+	e.Bool(c.ValidateOnly)
+	e.TaggedFields(c.TaggedFields)
+	return e.Err()
+}
@@ -7,13 +7,19 @@ import (
 	"github.com/nabinkhanal00/kafka/app/types"
 )
 
-type APIVersionsV4 struct {
+// APIVersions covers versions 0 through 4. Versions 0-2 have an empty body;
+// version 3 added the client software fields and became flexible.
+type APIVersions struct {
+	Version               int16               `desc:"-"`
 	ClientSoftwareName    types.CompactString `desc:"client_software_name"`
 	ClientSoftwareVersion types.CompactString `desc:"client_software_version"`
 	TaggedFields          types.TaggedFields  `desc:"_tagged_fields"`
 }
 
-func (r *APIVersionsV4) Write(w io.Writer) error {
+func (r *APIVersions) Write(w io.Writer) error {
+	if r.Version < 3 {
+		return nil
+	}
 	if err := r.ClientSoftwareName.Write(w); err != nil {
 		return err
 	}
@@ -22,7 +28,11 @@ func (r *APIVersionsV4) Write(w io.Writer) error {
 	}
 	return r.TaggedFields.Write(w)
 }
-func ParseAPIVersionsV4(r *bytes.Reader) (*APIVersionsV4, error) {
+
+func ParseAPIVersions(r *bytes.Reader, version int16) (*APIVersions, error) {
+	if version < 3 {
+		return &APIVersions{Version: version}, nil
+	}
 	clientSoftwareName, err := types.ParseCompactString(r)
 	if err != nil {
 		return nil, err
@@ -37,7 +47,8 @@ func ParseAPIVersionsV4(r *bytes.Reader) (*APIVersionsV4, error) {
 		return nil, err
 	}
 
-	return &APIVersionsV4{
+	return &APIVersions{
+		Version:               version,
 		ClientSoftwareName:    *clientSoftwareName,
 		ClientSoftwareVersion: *clientSoftwareVersion,
 		TaggedFields:          *taggedFields,
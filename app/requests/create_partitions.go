@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// CreatePartitions covers versions 0 through 3; version 2 onwards is
+// flexible.
+type CreatePartitions struct {
+	Version      int16                   `desc:"-"`
+	Topics       []CreatePartitionsTopic `desc:"topics"`
+	TimeoutMs    int32                   `desc:"timeout_ms"`
+	ValidateOnly bool                    `desc:"validate_only"`
+	TaggedFields types.TaggedFields      `desc:"_tagged_fields"`
+}
+
+// CreatePartitionsTopic has nil Assignments when the broker places the
+// new replicas.
+type CreatePartitionsTopic struct {
+	Name         string                       `desc:"name"`
+	Count        int32                        `desc:"count"`
+	Assignments  []CreatePartitionsAssignment `desc:"assignments"`
+	TaggedFields types.TaggedFields           `desc:"_tagged_fields"`
+}
+
+type CreatePartitionsAssignment struct {
+	BrokerIDs    []int32            `desc:"broker_ids"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseCreatePartitions(r *bytes.Reader, version int16) (*CreatePartitions, error) {
+	d := types.NewDecoder(r, version >= 2)
+	c := &CreatePartitions{Version: version}
+	c.Topics = types.DecodeArray(d, func(d *types.Decoder) CreatePartitionsTopic {
+		var t CreatePartitionsTopic
+		t.Name = d.String()
+		t.Count = d.Int32()
+		t.Assignments = types.DecodeArray(d, func(d *types.Decoder) CreatePartitionsAssignment {
+			return CreatePartitionsAssignment{
+				BrokerIDs:    types.DecodeArray(d, (*types.Decoder).Int32),
+				TaggedFields: d.TaggedFields(),
+			}
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	c.TimeoutMs = d.Int32()
+	c.ValidateOnly = d.Bool()
+	c.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CreatePartitions) Write(w io.Writer) error {
+	e := types.NewEncoder(w, c.Version >= 2)
+	types.EncodeArray(e, c.Topics, func(e *types.Encoder, t CreatePartitionsTopic) {
+		e.String(t.Name)
+		e.Int32(t.Count)
+		if t.Assignments == nil {
+			e.ArrayLength(-1)
+		} else {
+			types.EncodeArray(e, t.Assignments, func(e *types.Encoder, a CreatePartitionsAssignment) {
+				types.EncodeArray(e, a.BrokerIDs, (*types.Encoder).Int32)
+				e.TaggedFields(a.TaggedFields)
+			})
+		}
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.Int32(c.TimeoutMs)
+	e.Bool(c.ValidateOnly)
+	e.TaggedFields(c.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,98 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// CreateTopics covers versions 2 through 7; version 5 onwards is flexible.
+type CreateTopics struct {
+	Version      int16              `desc:"-"`
+	Topics       []CreatableTopic   `desc:"topics"`
+	TimeoutMs    int32              `desc:"timeout_ms"`
+	ValidateOnly bool               `desc:"validate_only"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+// CreatableTopic leaves NumPartitions and ReplicationFactor at -1 when
+// Assignments places the replicas or the broker defaults apply.
+type CreatableTopic struct {
+	Name              string                       `desc:"name"`
+	NumPartitions     int32                        `desc:"num_partitions"`
+	ReplicationFactor int16                        `desc:"replication_factor"`
+	Assignments       []CreatableReplicaAssignment `desc:"assignments"`
+	Configs           []CreatableTopicConfig       `desc:"configs"`
+	TaggedFields      types.TaggedFields           `desc:"_tagged_fields"`
+}
+
+type CreatableReplicaAssignment struct {
+	PartitionIndex int32              `desc:"partition_index"`
+	BrokerIDs      []int32            `desc:"broker_ids"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type CreatableTopicConfig struct {
+	Name         string             `desc:"name"`
+	Value        *string            `desc:"value"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseCreateTopics(r *bytes.Reader, version int16) (*CreateTopics, error) {
+	d := types.NewDecoder(r, version >= 5)
+	c := &CreateTopics{Version: version}
+	c.Topics = types.DecodeArray(d, func(d *types.Decoder) CreatableTopic {
+		var t CreatableTopic
+		t.Name = d.String()
+		t.NumPartitions = d.Int32()
+		t.ReplicationFactor = d.Int16()
+		t.Assignments = types.DecodeArray(d, func(d *types.Decoder) CreatableReplicaAssignment {
+			return CreatableReplicaAssignment{
+				PartitionIndex: d.Int32(),
+				BrokerIDs:      types.DecodeArray(d, (*types.Decoder).Int32),
+				TaggedFields:   d.TaggedFields(),
+			}
+		})
+		t.Configs = types.DecodeArray(d, func(d *types.Decoder) CreatableTopicConfig {
+			return CreatableTopicConfig{
+				Name:         d.String(),
+				Value:        d.NullableString(),
+				TaggedFields: d.TaggedFields(),
+			}
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	c.TimeoutMs = d.Int32()
+	c.ValidateOnly = d.Bool()
+	c.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CreateTopics) Write(w io.Writer) error {
+	e := types.NewEncoder(w, c.Version >= 5)
+	types.EncodeArray(e, c.Topics, func(e *types.Encoder, t CreatableTopic) {
+		e.String(t.Name)
+		e.Int32(t.NumPartitions)
+		e.Int16(t.ReplicationFactor)
+		types.EncodeArray(e, t.Assignments, func(e *types.Encoder, a CreatableReplicaAssignment) {
+			e.Int32(a.PartitionIndex)
+			types.EncodeArray(e, a.BrokerIDs, (*types.Encoder).Int32)
+			e.TaggedFields(a.TaggedFields)
+		})
+		types.EncodeArray(e, t.Configs, func(e *types.Encoder, cfg CreatableTopicConfig) {
+			e.String(cfg.Name)
+			e.NullableString(cfg.Value)
+			e.TaggedFields(cfg.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.Int32(c.TimeoutMs)
+	e.Bool(c.ValidateOnly)
+	e.TaggedFields(c.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,33 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DeleteGroups covers versions 0 through 2; version 2 is flexible.
+type DeleteGroups struct {
+	Version      int16              `desc:"-"`
+	GroupsNames  []string           `desc:"groups_names"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseDeleteGroups(r *bytes.Reader, version int16) (*DeleteGroups, error) {
+	d := types.NewDecoder(r, version >= 2)
+	g := &DeleteGroups{Version: version}
+	g.GroupsNames = types.DecodeArray(d, (*types.Decoder).String)
+	g.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *DeleteGroups) Write(w io.Writer) error {
+	e := types.NewEncoder(w, g.Version >= 2)
+	types.EncodeArray(e, g.GroupsNames, (*types.Encoder).String)
+	e.TaggedFields(g.TaggedFields)
+	return e.Err()
+}
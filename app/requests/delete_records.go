@@ -0,0 +1,70 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DeleteRecords covers versions 0 through 2; version 2 is flexible.
+type DeleteRecords struct {
+	Version      int16                `desc:"-"`
+	Topics       []DeleteRecordsTopic `desc:"topics"`
+	TimeoutMs    int32                `desc:"timeout_ms"`
+	TaggedFields types.TaggedFields   `desc:"_tagged_fields"`
+}
+
+type DeleteRecordsTopic struct {
+	Name         string                   `desc:"name"`
+	Partitions   []DeleteRecordsPartition `desc:"partitions"`
+	TaggedFields types.TaggedFields       `desc:"_tagged_fields"`
+}
+
+// DeleteRecordsPartition asks for every record below Offset to be
+// deleted; -1 stands for the high watermark.
+type DeleteRecordsPartition struct {
+	PartitionIndex int32              `desc:"partition_index"`
+	Offset         int64              `desc:"offset"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseDeleteRecords(r *bytes.Reader, version int16) (*DeleteRecords, error) {
+	d := types.NewDecoder(r, version >= 2)
+	c := &DeleteRecords{Version: version}
+	c.Topics = types.DecodeArray(d, func(d *types.Decoder) DeleteRecordsTopic {
+		var t DeleteRecordsTopic
+		t.Name = d.String()
+		t.Partitions = types.DecodeArray(d, func(d *types.Decoder) DeleteRecordsPartition {
+			return DeleteRecordsPartition{
+				PartitionIndex: d.Int32(),
+				Offset:         d.Int64(),
+				TaggedFields:   d.TaggedFields(),
+			}
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	c.TimeoutMs = d.Int32()
+	c.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *DeleteRecords) Write(w io.Writer) error {
+	e := types.NewEncoder(w, c.Version >= 2)
+	types.EncodeArray(e, c.Topics, func(e *types.Encoder, t DeleteRecordsTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p DeleteRecordsPartition) {
+			e.Int32(p.PartitionIndex)
+			e.Int64(p.Offset)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.Int32(c.TimeoutMs)
+	e.TaggedFields(c.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,74 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DeleteTopics covers versions 1 through 6. Version 4 onwards is flexible;
+// version 6 replaces topic_names with topics that may name a topic by id.
+type DeleteTopics struct {
+	Version      int16              `desc:"-"`
+	Topics       []DeleteTopicState `desc:"topics"`
+	TopicNames   []string           `desc:"topic_names"`
+	TimeoutMs    int32              `desc:"timeout_ms"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type DeleteTopicState struct {
+	Name         *string            `desc:"name"`
+	TopicID      types.UUID         `desc:"topic_id"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseDeleteTopics(r *bytes.Reader, version int16) (*DeleteTopics, error) {
+	d := types.NewDecoder(r, version >= 4)
+	t := &DeleteTopics{Version: version}
+	if version >= 6 {
+		t.Topics = types.DecodeArray(d, func(d *types.Decoder) DeleteTopicState {
+			return DeleteTopicState{
+				Name:         d.NullableString(),
+				TopicID:      d.UUID(),
+				TaggedFields: d.TaggedFields(),
+			}
+		})
+	} else {
+		t.TopicNames = types.DecodeArray(d, (*types.Decoder).String)
+	}
+	t.TimeoutMs = d.Int32()
+	t.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// States returns the requested topics in the version 6 form.
+func (t *DeleteTopics) States() []DeleteTopicState {
+	if t.Version >= 6 {
+		return t.Topics
+	}
+	states := make([]DeleteTopicState, 0, len(t.TopicNames))
+	for _, name := range t.TopicNames {
+		states = append(states, DeleteTopicState{Name: types.NullableStringOf(name)})
+	}
+	return states
+}
+
+func (t *DeleteTopics) Write(w io.Writer) error {
+	e := types.NewEncoder(w, t.Version >= 4)
+	if t.Version >= 6 {
+		types.EncodeArray(e, t.Topics, func(e *types.Encoder, s DeleteTopicState) {
+			e.NullableString(s.Name)
+			e.UUID(s.TopicID)
+			e.TaggedFields(s.TaggedFields)
+		})
+	} else {
+		types.EncodeArray(e, t.TopicNames, (*types.Encoder).String)
+	}
+	e.Int32(t.TimeoutMs)
+	e.TaggedFields(t.TaggedFields)
+	return e.Err()
+}
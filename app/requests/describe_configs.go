@@ -0,0 +1,73 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DescribeConfigs covers versions 0 through 4; version 4 onwards is
+// flexible.
+type DescribeConfigs struct {
+	Version              int16                     `desc:"-"`
+	Resources            []DescribeConfigsResource `desc:"resources"`
+	IncludeSynonyms      bool                      `desc:"include_synonyms"`
+	IncludeDocumentation bool                      `desc:"include_documentation"`
+	TaggedFields         types.TaggedFields        `desc:"_tagged_fields"`
+}
+
+// DescribeConfigsResource asks for every config when ConfigurationKeys is
+// nil.
+type DescribeConfigsResource struct {
+	ResourceType      int8               `desc:"resource_type"`
+	ResourceName      string             `desc:"resource_name"`
+	ConfigurationKeys []string           `desc:"configuration_keys"`
+	TaggedFields      types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseDescribeConfigs(r *bytes.Reader, version int16) (*DescribeConfigs, error) {
+	d := types.NewDecoder(r, version >= 4)
+	c := &DescribeConfigs{Version: version}
+	c.Resources = types.DecodeArray(d, func(d *types.Decoder) DescribeConfigsResource {
+		return DescribeConfigsResource{
+			ResourceType:      d.Int8(),
+			ResourceName:      d.String(),
+			ConfigurationKeys: types.DecodeArray(d, (*types.Decoder).String),
+			TaggedFields:      d.TaggedFields(),
+		}
+	})
+	if version >= 1 {
+		c.IncludeSynonyms = d.Bool()
+	}
+	if version >= 3 {
+		c.IncludeDocumentation = d.Bool()
+	}
+	c.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *DescribeConfigs) Write(w io.Writer) error {
+	e := types.NewEncoder(w, c.Version >= 4)
+	types.EncodeArray(e, c.Resources, func(e *types.Encoder, r DescribeConfigsResource) {
+		e.Int8(r.ResourceType)
+		e.String(r.ResourceName)
+		if r.ConfigurationKeys == nil {
+			e.ArrayLength(-1)
+		} else {
+			types.EncodeArray(e, r.ConfigurationKeys, (*types.Encoder).String)
+		}
+		e.TaggedFields(r.TaggedFields)
+	})
+	if c.Version >= 1 {
+		e.Bool(c.IncludeSynonyms)
+	}
+	if c.Version >= 3 {
+		e.Bool(c.IncludeDocumentation)
+	}
+	e.TaggedFields(c.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,40 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DescribeGroups covers versions 0 through 5; version 5 is flexible.
+type DescribeGroups struct {
+	Version                     int16              `desc:"-"`
+	Groups                      []string           `desc:"groups"`
+	IncludeAuthorizedOperations bool               `desc:"include_authorized_operations"`
+	TaggedFields                types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseDescribeGroups(r *bytes.Reader, version int16) (*DescribeGroups, error) {
+	d := types.NewDecoder(r, version >= 5)
+	g := &DescribeGroups{Version: version}
+	g.Groups = types.DecodeArray(d, (*types.Decoder).String)
+	if version >= 3 {
+		g.IncludeAuthorizedOperations = d.Bool()
+	}
+	g.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *DescribeGroups) Write(w io.Writer) error {
+	e := types.NewEncoder(w, g.Version >= 5)
+	types.EncodeArray(e, g.Groups, (*types.Encoder).String)
+	if g.Version >= 3 {
+		e.Bool(g.IncludeAuthorizedOperations)
+	}
+	e.TaggedFields(g.TaggedFields)
+	return e.Err()
+}
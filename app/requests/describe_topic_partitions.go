@@ -11,8 +11,11 @@ import (
 type DescribeTopicPartitionsV0 struct {
 	Topics                 []Topic            `desc:"topics"`
 	ResponsePartitionLimit int32              `desc:"response_partition_limits"`
-	Cursor                 int8               `desc:"cursor"`
+	Cursor                 *Cursor            `desc:"cursor"`
 	TaggedFields           types.TaggedFields `desc:"_tagged_fields"`
+	// Invalid explains why a request that could be read violates the
+	// schema, such as a null topic name; it is answered INVALID_REQUEST.
+	Invalid string `desc:"-"`
 }
 
 type Topic struct {
@@ -26,55 +29,68 @@ func (t *Topic) Write(w io.Writer) error {
 	}
 	return t.TaggedFields.Write(w)
 }
-func ParseTopic(r *bytes.Reader) (*Topic, error) {
-	name, err := types.ParseCompactString(r)
-	if err != nil {
-		return nil, err
+
+// Cursor is where a previous response stopped. It is a nullable struct,
+// encoded as -1 when absent and 1 followed by the fields otherwise.
+type Cursor struct {
+	TopicName      types.CompactString `desc:"topic_name"`
+	PartitionIndex int32               `desc:"partition_index"`
+	TaggedFields   types.TaggedFields  `desc:"_tagged_fields"`
+}
+
+func (c *Cursor) Write(w io.Writer) error {
+	if c == nil {
+		return binary.Write(w, binary.BigEndian, int8(-1))
 	}
-	taggedFields, err := types.ParseTaggedFields(r)
-	if err != nil {
-		return nil, err
+	if err := binary.Write(w, binary.BigEndian, int8(1)); err != nil {
+		return err
+	}
+	if err := c.TopicName.Write(w); err != nil {
+		return err
 	}
-	return &Topic{
-		Name:         *name,
-		TaggedFields: *taggedFields,
-	}, nil
+	if err := binary.Write(w, binary.BigEndian, c.PartitionIndex); err != nil {
+		return err
+	}
+	return c.TaggedFields.Write(w)
 }
 
+// ParseDescribeTopicPartitionsV0 reads the request. Null topic and cursor
+// names are read as empty and reported through Invalid.
 func ParseDescribeTopicPartitionsV0(r *bytes.Reader) (*DescribeTopicPartitionsV0, error) {
-	numTopics, err := types.ReadUvarint(r)
-	if err != nil {
-		return nil, err
-	}
-	// the length of array is always 1 more than actual
-	numTopics -= 1
-	topics := []Topic{}
-	for range numTopics {
-		topic, err := ParseTopic(r)
-		if err != nil {
-			return nil, err
+	d := types.NewDecoder(r, true)
+	req := &DescribeTopicPartitionsV0{}
+	name := func() types.CompactString {
+		s := d.NullableString()
+		if s == nil {
+			if d.Err() == nil {
+				req.Invalid = "null topic name"
+			}
+			return ""
 		}
-		topics = append(topics, *topic)
+		return types.CompactString(*s)
 	}
-	responsePartitionLimit, err := types.Parse[int32](r)
-	if err != nil {
-		return nil, err
+	req.Topics = types.DecodeArray(d, func(d *types.Decoder) Topic {
+		var t Topic
+		t.Name = name()
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	if req.Topics == nil {
+		req.Topics = []Topic{}
 	}
-	cursor, err := types.Parse[int8](r)
-	if err != nil {
-		return nil, err
+	req.ResponsePartitionLimit = d.Int32()
+	if d.Int8() >= 0 {
+		c := &Cursor{}
+		c.TopicName = name()
+		c.PartitionIndex = d.Int32()
+		c.TaggedFields = d.TaggedFields()
+		req.Cursor = c
 	}
-	taggedFields, err := types.Parse[types.TaggedFields](r)
-	if err != nil {
+	req.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
 		return nil, err
 	}
-	return &DescribeTopicPartitionsV0{
-		Topics:                 topics,
-		ResponsePartitionLimit: *responsePartitionLimit,
-		Cursor:                 *cursor,
-		TaggedFields:           *taggedFields,
-	}, err
-
+	return req, nil
 }
 
 func (r *DescribeTopicPartitionsV0) Write(w io.Writer) error {
@@ -89,5 +105,8 @@ func (r *DescribeTopicPartitionsV0) Write(w io.Writer) error {
 	if err := binary.Write(w, binary.BigEndian, r.ResponsePartitionLimit); err != nil {
 		return err
 	}
+	if err := r.Cursor.Write(w); err != nil {
+		return err
+	}
 	return r.TaggedFields.Write(w)
 }
@@ -0,0 +1,199 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Fetch covers versions 4 through 16. Version 12 onwards is flexible and
+// version 13 onwards names topics by id instead of by name.
+type Fetch struct {
+	Version             int16                 `desc:"-"`
+	ClusterID           *string               `desc:"cluster_id"`
+	ReplicaID           int32                 `desc:"replica_id"`
+	ReplicaState        *FetchReplicaState    `desc:"replica_state"`
+	MaxWaitMs           int32                 `desc:"max_wait_ms"`
+	MinBytes            int32                 `desc:"min_bytes"`
+	MaxBytes            int32                 `desc:"max_bytes"`
+	IsolationLevel      int8                  `desc:"isolation_level"`
+	SessionID           int32                 `desc:"session_id"`
+	SessionEpoch        int32                 `desc:"session_epoch"`
+	Topics              []FetchTopic          `desc:"topics"`
+	ForgottenTopicsData []FetchForgottenTopic `desc:"forgotten_topics_data"`
+	RackID              string                `desc:"rack_id"`
+	TaggedFields        types.TaggedFields    `desc:"_tagged_fields"`
+}
+
+// FetchReplicaState replaces replica_id from version 15 and is sent as
+// tagged field 1.
+type FetchReplicaState struct {
+	ReplicaID    int32 `desc:"replica_id"`
+	ReplicaEpoch int64 `desc:"replica_epoch"`
+}
+
+type FetchTopic struct {
+	Topic        string             `desc:"topic"`
+	TopicID      types.UUID         `desc:"topic_id"`
+	Partitions   []FetchPartition   `desc:"partitions"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type FetchPartition struct {
+	Partition          int32              `desc:"partition"`
+	CurrentLeaderEpoch int32              `desc:"current_leader_epoch"`
+	FetchOffset        int64              `desc:"fetch_offset"`
+	LastFetchedEpoch   int32              `desc:"last_fetched_epoch"`
+	LogStartOffset     int64              `desc:"log_start_offset"`
+	PartitionMaxBytes  int32              `desc:"partition_max_bytes"`
+	TaggedFields       types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type FetchForgottenTopic struct {
+	Topic        string             `desc:"topic"`
+	TopicID      types.UUID         `desc:"topic_id"`
+	Partitions   []int32            `desc:"partitions"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+const (
+	fetchClusterIDTag    = 0
+	fetchReplicaStateTag = 1
+)
+
+func ParseFetch(r *bytes.Reader, version int16) (*Fetch, error) {
+	d := types.NewDecoder(r, version >= 12)
+	f := &Fetch{Version: version, ReplicaID: -1}
+	if version <= 14 {
+		f.ReplicaID = d.Int32()
+	}
+	f.MaxWaitMs = d.Int32()
+	f.MinBytes = d.Int32()
+	f.MaxBytes = d.Int32()
+	f.IsolationLevel = d.Int8()
+	if version >= 7 {
+		f.SessionID = d.Int32()
+		f.SessionEpoch = d.Int32()
+	} else {
+		f.SessionEpoch = -1
+	}
+	f.Topics = types.DecodeArray(d, func(d *types.Decoder) FetchTopic {
+		var t FetchTopic
+		if version >= 13 {
+			t.TopicID = d.UUID()
+		} else {
+			t.Topic = d.String()
+		}
+		t.Partitions = types.DecodeArray(d, func(d *types.Decoder) FetchPartition {
+			p := FetchPartition{CurrentLeaderEpoch: -1, LastFetchedEpoch: -1, LogStartOffset: -1}
+			p.Partition = d.Int32()
+			if version >= 9 {
+				p.CurrentLeaderEpoch = d.Int32()
+			}
+			p.FetchOffset = d.Int64()
+			if version >= 12 {
+				p.LastFetchedEpoch = d.Int32()
+			}
+			if version >= 5 {
+				p.LogStartOffset = d.Int64()
+			}
+			p.PartitionMaxBytes = d.Int32()
+			p.TaggedFields = d.TaggedFields()
+			return p
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	if version >= 7 {
+		f.ForgottenTopicsData = types.DecodeArray(d, func(d *types.Decoder) FetchForgottenTopic {
+			var t FetchForgottenTopic
+			if version >= 13 {
+				t.TopicID = d.UUID()
+			} else {
+				t.Topic = d.String()
+			}
+			t.Partitions = types.DecodeArray(d, (*types.Decoder).Int32)
+			t.TaggedFields = d.TaggedFields()
+			return t
+		})
+	}
+	if version >= 11 {
+		f.RackID = d.String()
+	}
+	f.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if v, ok := f.TaggedFields.Fields[fetchClusterIDTag]; ok {
+		td := types.NewDecoder(bytes.NewReader(v), true)
+		f.ClusterID = td.NullableString()
+		if err := td.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := f.TaggedFields.Fields[fetchReplicaStateTag]; ok {
+		td := types.NewDecoder(bytes.NewReader(v), true)
+		f.ReplicaState = &FetchReplicaState{ReplicaID: td.Int32(), ReplicaEpoch: td.Int64()}
+		td.TaggedFields()
+		if err := td.Err(); err != nil {
+			return nil, err
+		}
+		f.ReplicaID = f.ReplicaState.ReplicaID
+	}
+	return f, nil
+}
+
+func (f *Fetch) Write(w io.Writer) error {
+	e := types.NewEncoder(w, f.Version >= 12)
+	if f.Version <= 14 {
+		e.Int32(f.ReplicaID)
+	}
+	e.Int32(f.MaxWaitMs)
+	e.Int32(f.MinBytes)
+	e.Int32(f.MaxBytes)
+	e.Int8(f.IsolationLevel)
+	if f.Version >= 7 {
+		e.Int32(f.SessionID)
+		e.Int32(f.SessionEpoch)
+	}
+	types.EncodeArray(e, f.Topics, func(e *types.Encoder, t FetchTopic) {
+		if f.Version >= 13 {
+			e.UUID(t.TopicID)
+		} else {
+			e.String(t.Topic)
+		}
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p FetchPartition) {
+			e.Int32(p.Partition)
+			if f.Version >= 9 {
+				e.Int32(p.CurrentLeaderEpoch)
+			}
+			e.Int64(p.FetchOffset)
+			if f.Version >= 12 {
+				e.Int32(p.LastFetchedEpoch)
+			}
+			if f.Version >= 5 {
+				e.Int64(p.LogStartOffset)
+			}
+			e.Int32(p.PartitionMaxBytes)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	if f.Version >= 7 {
+		types.EncodeArray(e, f.ForgottenTopicsData, func(e *types.Encoder, t FetchForgottenTopic) {
+			if f.Version >= 13 {
+				e.UUID(t.TopicID)
+			} else {
+				e.String(t.Topic)
+			}
+			types.EncodeArray(e, t.Partitions, (*types.Encoder).Int32)
+			e.TaggedFields(t.TaggedFields)
+		})
+	}
+	if f.Version >= 11 {
+		e.String(f.RackID)
+	}
+	e.TaggedFields(f.TaggedFields)
+	return e.Err()
+}
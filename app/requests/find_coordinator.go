@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// FindCoordinator covers versions 0 through 5; version 3 onwards is
+// flexible. Versions 4 and later look up a batch of keys.
+type FindCoordinator struct {
+	Version         int16              `desc:"-"`
+	Key             string             `desc:"key"`
+	KeyType         int8               `desc:"key_type"`
+	CoordinatorKeys []string           `desc:"coordinator_keys"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+// Keys returns the keys looked up, whatever the version.
+func (f *FindCoordinator) Keys() []string {
+	if f.Version >= 4 {
+		return f.CoordinatorKeys
+	}
+	return []string{f.Key}
+}
+
+func ParseFindCoordinator(r *bytes.Reader, version int16) (*FindCoordinator, error) {
+	d := types.NewDecoder(r, version >= 3)
+	f := &FindCoordinator{Version: version}
+	if version < 4 {
+		f.Key = d.String()
+	}
+	if version >= 1 {
+		f.KeyType = d.Int8()
+	}
+	if version >= 4 {
+		f.CoordinatorKeys = types.DecodeArray(d, (*types.Decoder).String)
+	}
+	f.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FindCoordinator) Write(w io.Writer) error {
+	e := types.NewEncoder(w, f.Version >= 3)
+	if f.Version < 4 {
+		e.String(f.Key)
+	}
+	if f.Version >= 1 {
+		e.Int8(f.KeyType)
+	}
+	if f.Version >= 4 {
+		types.EncodeArray(e, f.CoordinatorKeys, (*types.Encoder).String)
+	}
+	e.TaggedFields(f.TaggedFields)
+	return e.Err()
+}
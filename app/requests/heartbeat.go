@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Heartbeat covers versions 0 through 4; version 4 is flexible.
+type Heartbeat struct {
+	Version         int16              `desc:"-"`
+	GroupID         string             `desc:"group_id"`
+	GenerationID    int32              `desc:"generation_id"`
+	MemberID        string             `desc:"member_id"`
+	GroupInstanceID *string            `desc:"group_instance_id"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseHeartbeat(r *bytes.Reader, version int16) (*Heartbeat, error) {
+	d := types.NewDecoder(r, version >= 4)
+	h := &Heartbeat{Version: version}
+	h.GroupID = d.String()
+	h.GenerationID = d.Int32()
+	h.MemberID = d.String()
+	if version >= 3 {
+		h.GroupInstanceID = d.NullableString()
+	}
+	h.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *Heartbeat) Write(w io.Writer) error {
+	e := types.NewEncoder(w, h.Version >= 4)
+	e.String(h.GroupID)
+	e.Int32(h.GenerationID)
+	e.String(h.MemberID)
+	if h.Version >= 3 {
+		e.NullableString(h.GroupInstanceID)
+	}
+	e.TaggedFields(h.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,83 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Operations of IncrementalAlterConfigs. APPEND and SUBTRACT only apply
+// to list configs.
+const (
+	ConfigOperationSet      int8 = 0
+	ConfigOperationDelete   int8 = 1
+	ConfigOperationAppend   int8 = 2
+	ConfigOperationSubtract int8 = 3
+)
+
+// IncrementalAlterConfigs covers versions 0 and 1; version 1 is flexible.
+type IncrementalAlterConfigs struct {
+	Version      int16                             `desc:"-"`
+	Resources    []IncrementalAlterConfigsResource `desc:"resources"`
+	ValidateOnly bool                              `desc:"validate_only"`
+	TaggedFields types.TaggedFields                `desc:"_tagged_fields"`
+}
+
+type IncrementalAlterConfigsResource struct {
+	ResourceType int8                         `desc:"resource_type"`
+	ResourceName string                       `desc:"resource_name"`
+	Configs      []IncrementalAlterableConfig `desc:"configs"`
+	TaggedFields types.TaggedFields           `desc:"_tagged_fields"`
+}
+
+type IncrementalAlterableConfig struct {
+	Name            string             `desc:"name"`
+	ConfigOperation int8               `desc:"config_operation"`
+	Value           *string            `desc:"value"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseIncrementalAlterConfigs(r *bytes.Reader, version int16) (*IncrementalAlterConfigs, error) {
+	d := types.NewDecoder(r, version >= 1)
+	c := &IncrementalAlterConfigs{Version: version}
+	c.Resources = types.DecodeArray(d, func(d *types.Decoder) IncrementalAlterConfigsResource {
+		var res IncrementalAlterConfigsResource
+		res.ResourceType = d.Int8()
+		res.ResourceName = d.String()
+		res.Configs = types.DecodeArray(d, func(d *types.Decoder) IncrementalAlterableConfig {
+			return IncrementalAlterableConfig{
+				Name:            d.String(),
+				ConfigOperation: d.Int8(),
+				Value:           d.NullableString(),
+				TaggedFields:    d.TaggedFields(),
+			}
+		})
+		res.TaggedFields = d.TaggedFields()
+		return res
+	})
+	c.ValidateOnly = d.Bool()
+	c.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *IncrementalAlterConfigs) Write(w io.Writer) error {
+	e := types.NewEncoder(w, c.Version >= 1)
+	types.EncodeArray(e, c.Resources, func(e *types.Encoder, res IncrementalAlterConfigsResource) {
+		e.Int8(res.ResourceType)
+		e.String(res.ResourceName)
+		types.EncodeArray(e, res.Configs, func(e *types.Encoder, cfg IncrementalAlterableConfig) {
+			e.String(cfg.Name)
+			e.Int8(cfg.ConfigOperation)
+			e.NullableString(cfg.Value)
+			e.TaggedFields(cfg.TaggedFields)
+		})
+		e.TaggedFields(res.TaggedFields)
+	})
+	e.Bool(c.ValidateOnly)
+	e.TaggedFields(c.TaggedFields)
+	return e.Err()
+}
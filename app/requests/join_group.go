@@ -0,0 +1,83 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// JoinGroup covers versions 0 through 9; version 6 onwards is flexible.
+type JoinGroup struct {
+	Version            int16               `desc:"-"`
+	GroupID            string              `desc:"group_id"`
+	SessionTimeoutMs   int32               `desc:"session_timeout_ms"`
+	RebalanceTimeoutMs int32               `desc:"rebalance_timeout_ms"`
+	MemberID           string              `desc:"member_id"`
+	GroupInstanceID    *string             `desc:"group_instance_id"`
+	ProtocolType       string              `desc:"protocol_type"`
+	Protocols          []JoinGroupProtocol `desc:"protocols"`
+	Reason             *string             `desc:"reason"`
+	TaggedFields       types.TaggedFields  `desc:"_tagged_fields"`
+}
+
+type JoinGroupProtocol struct {
+	Name         string             `desc:"name"`
+	Metadata     []byte             `desc:"metadata"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseJoinGroup(r *bytes.Reader, version int16) (*JoinGroup, error) {
+	d := types.NewDecoder(r, version >= 6)
+	j := &JoinGroup{Version: version}
+	j.GroupID = d.String()
+	j.SessionTimeoutMs = d.Int32()
+	j.RebalanceTimeoutMs = j.SessionTimeoutMs
+	if version >= 1 {
+		j.RebalanceTimeoutMs = d.Int32()
+	}
+	j.MemberID = d.String()
+	if version >= 5 {
+		j.GroupInstanceID = d.NullableString()
+	}
+	j.ProtocolType = d.String()
+	j.Protocols = types.DecodeArray(d, func(d *types.Decoder) JoinGroupProtocol {
+		return JoinGroupProtocol{
+			Name:         d.String(),
+			Metadata:     d.Bytes(),
+			TaggedFields: d.TaggedFields(),
+		}
+	})
+	if version >= 8 {
+		j.Reason = d.NullableString()
+	}
+	j.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *JoinGroup) Write(w io.Writer) error {
+	e := types.NewEncoder(w, j.Version >= 6)
+	e.String(j.GroupID)
+	e.Int32(j.SessionTimeoutMs)
+	if j.Version >= 1 {
+		e.Int32(j.RebalanceTimeoutMs)
+	}
+	e.String(j.MemberID)
+	if j.Version >= 5 {
+		e.NullableString(j.GroupInstanceID)
+	}
+	e.String(j.ProtocolType)
+	types.EncodeArray(e, j.Protocols, func(e *types.Encoder, p JoinGroupProtocol) {
+		e.String(p.Name)
+		e.Bytes(p.Metadata)
+		e.TaggedFields(p.TaggedFields)
+	})
+	if j.Version >= 8 {
+		e.NullableString(j.Reason)
+	}
+	e.TaggedFields(j.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,69 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// LeaveGroup covers versions 0 through 5; version 4 onwards is flexible.
+// Versions 3 and later remove a batch of members.
+type LeaveGroup struct {
+	Version      int16              `desc:"-"`
+	GroupID      string             `desc:"group_id"`
+	MemberID     string             `desc:"member_id"`
+	Members      []LeaveGroupMember `desc:"members"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type LeaveGroupMember struct {
+	MemberID        string             `desc:"member_id"`
+	GroupInstanceID *string            `desc:"group_instance_id"`
+	Reason          *string            `desc:"reason"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseLeaveGroup(r *bytes.Reader, version int16) (*LeaveGroup, error) {
+	d := types.NewDecoder(r, version >= 4)
+	l := &LeaveGroup{Version: version}
+	l.GroupID = d.String()
+	if version < 3 {
+		l.MemberID = d.String()
+	} else {
+		l.Members = types.DecodeArray(d, func(d *types.Decoder) LeaveGroupMember {
+			var m LeaveGroupMember
+			m.MemberID = d.String()
+			m.GroupInstanceID = d.NullableString()
+			if version >= 5 {
+				m.Reason = d.NullableString()
+			}
+			m.TaggedFields = d.TaggedFields()
+			return m
+		})
+	}
+	l.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *LeaveGroup) Write(w io.Writer) error {
+	e := types.NewEncoder(w, l.Version >= 4)
+	e.String(l.GroupID)
+	if l.Version < 3 {
+		e.String(l.MemberID)
+	} else {
+		types.EncodeArray(e, l.Members, func(e *types.Encoder, m LeaveGroupMember) {
+			e.String(m.MemberID)
+			e.NullableString(m.GroupInstanceID)
+			if l.Version >= 5 {
+				e.NullableString(m.Reason)
+			}
+			e.TaggedFields(m.TaggedFields)
+		})
+	}
+	e.TaggedFields(l.TaggedFields)
+	return e.Err()
+}
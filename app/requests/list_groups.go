@@ -0,0 +1,44 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// ListGroups covers versions 0 through 5; version 3 onwards is flexible.
+type ListGroups struct {
+	Version      int16              `desc:"-"`
+	StatesFilter []string           `desc:"states_filter"`
+	TypesFilter  []string           `desc:"types_filter"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseListGroups(r *bytes.Reader, version int16) (*ListGroups, error) {
+	d := types.NewDecoder(r, version >= 3)
+	l := &ListGroups{Version: version}
+	if version >= 4 {
+		l.StatesFilter = types.DecodeArray(d, (*types.Decoder).String)
+	}
+	if version >= 5 {
+		l.TypesFilter = types.DecodeArray(d, (*types.Decoder).String)
+	}
+	l.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *ListGroups) Write(w io.Writer) error {
+	e := types.NewEncoder(w, l.Version >= 3)
+	if l.Version >= 4 {
+		types.EncodeArray(e, l.StatesFilter, (*types.Encoder).String)
+	}
+	if l.Version >= 5 {
+		types.EncodeArray(e, l.TypesFilter, (*types.Encoder).String)
+	}
+	e.TaggedFields(l.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,82 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// ListOffsets covers versions 1 through 9; version 6 onwards is flexible.
+type ListOffsets struct {
+	Version        int16              `desc:"-"`
+	ReplicaID      int32              `desc:"replica_id"`
+	IsolationLevel int8               `desc:"isolation_level"`
+	Topics         []ListOffsetsTopic `desc:"topics"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ListOffsetsTopic struct {
+	Name         string                 `desc:"name"`
+	Partitions   []ListOffsetsPartition `desc:"partitions"`
+	TaggedFields types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+type ListOffsetsPartition struct {
+	PartitionIndex     int32              `desc:"partition_index"`
+	CurrentLeaderEpoch int32              `desc:"current_leader_epoch"`
+	Timestamp          int64              `desc:"timestamp"`
+	TaggedFields       types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseListOffsets(r *bytes.Reader, version int16) (*ListOffsets, error) {
+	d := types.NewDecoder(r, version >= 6)
+	l := &ListOffsets{Version: version}
+	l.ReplicaID = d.Int32()
+	if version >= 2 {
+		l.IsolationLevel = d.Int8()
+	}
+	l.Topics = types.DecodeArray(d, func(d *types.Decoder) ListOffsetsTopic {
+		var t ListOffsetsTopic
+		t.Name = d.String()
+		t.Partitions = types.DecodeArray(d, func(d *types.Decoder) ListOffsetsPartition {
+			p := ListOffsetsPartition{CurrentLeaderEpoch: -1}
+			p.PartitionIndex = d.Int32()
+			if version >= 4 {
+				p.CurrentLeaderEpoch = d.Int32()
+			}
+			p.Timestamp = d.Int64()
+			p.TaggedFields = d.TaggedFields()
+			return p
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	l.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *ListOffsets) Write(w io.Writer) error {
+	e := types.NewEncoder(w, l.Version >= 6)
+	e.Int32(l.ReplicaID)
+	if l.Version >= 2 {
+		e.Int8(l.IsolationLevel)
+	}
+	types.EncodeArray(e, l.Topics, func(e *types.Encoder, t ListOffsetsTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p ListOffsetsPartition) {
+			e.Int32(p.PartitionIndex)
+			if l.Version >= 4 {
+				e.Int32(p.CurrentLeaderEpoch)
+			}
+			e.Int64(p.Timestamp)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(l.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,97 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Metadata covers versions 0 through 12. Version 9 onwards is flexible and
+// version 10 onwards may name topics by id. A nil Topics asks for every
+// topic; version 0 uses an empty array for that instead.
+type Metadata struct {
+	Version                            int16              `desc:"-"`
+	Topics                             []MetadataTopic    `desc:"topics"`
+	AllowAutoTopicCreation             bool               `desc:"allow_auto_topic_creation"`
+	IncludeClusterAuthorizedOperations bool               `desc:"include_cluster_authorized_operations"`
+	IncludeTopicAuthorizedOperations   bool               `desc:"include_topic_authorized_operations"`
+	TaggedFields                       types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type MetadataTopic struct {
+	TopicID      types.UUID         `desc:"topic_id"`
+	Name         *string            `desc:"name"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseMetadata(r *bytes.Reader, version int16) (*Metadata, error) {
+	d := types.NewDecoder(r, version >= 9)
+	m := &Metadata{Version: version, AllowAutoTopicCreation: true}
+	m.Topics = types.DecodeArray(d, func(d *types.Decoder) MetadataTopic {
+		var t MetadataTopic
+		if version >= 10 {
+			t.TopicID = d.UUID()
+			t.Name = d.NullableString()
+		} else {
+			t.Name = types.NullableStringOf(d.String())
+		}
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	if version == 0 && m.Topics == nil {
+		m.Topics = []MetadataTopic{}
+	}
+	if version >= 4 {
+		m.AllowAutoTopicCreation = d.Bool()
+	}
+	if version >= 8 && version <= 10 {
+		m.IncludeClusterAuthorizedOperations = d.Bool()
+	}
+	if version >= 8 {
+		m.IncludeTopicAuthorizedOperations = d.Bool()
+	}
+	m.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AllTopics reports whether the request asks for every topic.
+func (m *Metadata) AllTopics() bool {
+	if m.Version == 0 {
+		return len(m.Topics) == 0
+	}
+	return m.Topics == nil
+}
+
+func (m *Metadata) Write(w io.Writer) error {
+	e := types.NewEncoder(w, m.Version >= 9)
+	if m.Topics == nil && m.Version >= 1 {
+		e.ArrayLength(-1)
+	} else {
+		types.EncodeArray(e, m.Topics, func(e *types.Encoder, t MetadataTopic) {
+			if m.Version >= 10 {
+				e.UUID(t.TopicID)
+				e.NullableString(t.Name)
+			} else if t.Name != nil {
+				e.String(*t.Name)
+			} else {
+				e.String("")
+			}
+			e.TaggedFields(t.TaggedFields)
+		})
+	}
+	if m.Version >= 4 {
+		e.Bool(m.AllowAutoTopicCreation)
+	}
+	if m.Version >= 8 && m.Version <= 10 {
+		e.Bool(m.IncludeClusterAuthorizedOperations)
+	}
+	if m.Version >= 8 {
+		e.Bool(m.IncludeTopicAuthorizedOperations)
+	}
+	e.TaggedFields(m.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,98 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetCommit covers versions 2 through 8; version 8 is flexible.
+type OffsetCommit struct {
+	Version         int16               `desc:"-"`
+	GroupID         string              `desc:"group_id"`
+	GenerationID    int32               `desc:"generation_id_or_member_epoch"`
+	MemberID        string              `desc:"member_id"`
+	GroupInstanceID *string             `desc:"group_instance_id"`
+	RetentionTimeMs int64               `desc:"retention_time_ms"`
+	Topics          []OffsetCommitTopic `desc:"topics"`
+	TaggedFields    types.TaggedFields  `desc:"_tagged_fields"`
+}
+
+type OffsetCommitTopic struct {
+	Name         string                  `desc:"name"`
+	Partitions   []OffsetCommitPartition `desc:"partitions"`
+	TaggedFields types.TaggedFields      `desc:"_tagged_fields"`
+}
+
+type OffsetCommitPartition struct {
+	PartitionIndex       int32              `desc:"partition_index"`
+	CommittedOffset      int64              `desc:"committed_offset"`
+	CommittedLeaderEpoch int32              `desc:"committed_leader_epoch"`
+	CommittedMetadata    *string            `desc:"committed_metadata"`
+	TaggedFields         types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseOffsetCommit(r *bytes.Reader, version int16) (*OffsetCommit, error) {
+	d := types.NewDecoder(r, version >= 8)
+	o := &OffsetCommit{Version: version, RetentionTimeMs: -1}
+	o.GroupID = d.String()
+	o.GenerationID = d.Int32()
+	o.MemberID = d.String()
+	if version >= 7 {
+		o.GroupInstanceID = d.NullableString()
+	}
+	if version <= 4 {
+		o.RetentionTimeMs = d.Int64()
+	}
+	o.Topics = types.DecodeArray(d, func(d *types.Decoder) OffsetCommitTopic {
+		var t OffsetCommitTopic
+		t.Name = d.String()
+		t.Partitions = types.DecodeArray(d, func(d *types.Decoder) OffsetCommitPartition {
+			p := OffsetCommitPartition{CommittedLeaderEpoch: -1}
+			p.PartitionIndex = d.Int32()
+			p.CommittedOffset = d.Int64()
+			if version >= 6 {
+				p.CommittedLeaderEpoch = d.Int32()
+			}
+			p.CommittedMetadata = d.NullableString()
+			p.TaggedFields = d.TaggedFields()
+			return p
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	o.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *OffsetCommit) Write(w io.Writer) error {
+	e := types.NewEncoder(w, o.Version >= 8)
+	e.String(o.GroupID)
+	e.Int32(o.GenerationID)
+	e.String(o.MemberID)
+	if o.Version >= 7 {
+		e.NullableString(o.GroupInstanceID)
+	}
+	if o.Version <= 4 {
+		e.Int64(o.RetentionTimeMs)
+	}
+	types.EncodeArray(e, o.Topics, func(e *types.Encoder, t OffsetCommitTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p OffsetCommitPartition) {
+			e.Int32(p.PartitionIndex)
+			e.Int64(p.CommittedOffset)
+			if o.Version >= 6 {
+				e.Int32(p.CommittedLeaderEpoch)
+			}
+			e.NullableString(p.CommittedMetadata)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(o.TaggedFields)
+	return e.Err()
+}
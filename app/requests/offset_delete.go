@@ -0,0 +1,54 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetDelete covers version 0, which is not flexible.
+type OffsetDelete struct {
+	Version int16               `desc:"-"`
+	GroupID string              `desc:"group_id"`
+	Topics  []OffsetDeleteTopic `desc:"topics"`
+}
+
+type OffsetDeleteTopic struct {
+	Name       string                  `desc:"name"`
+	Partitions []OffsetDeletePartition `desc:"partitions"`
+}
+
+type OffsetDeletePartition struct {
+	PartitionIndex int32 `desc:"partition_index"`
+}
+
+func ParseOffsetDelete(r *bytes.Reader, version int16) (*OffsetDelete, error) {
+	d := types.NewDecoder(r, false)
+	o := &OffsetDelete{Version: version}
+	o.GroupID = d.String()
+	o.Topics = types.DecodeArray(d, func(d *types.Decoder) OffsetDeleteTopic {
+		var t OffsetDeleteTopic
+		t.Name = d.String()
+		t.Partitions = types.DecodeArray(d, func(d *types.Decoder) OffsetDeletePartition {
+			return OffsetDeletePartition{PartitionIndex: d.Int32()}
+		})
+		return t
+	})
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *OffsetDelete) Write(w io.Writer) error {
+	e := types.NewEncoder(w, false)
+	e.String(o.GroupID)
+	types.EncodeArray(e, o.Topics, func(e *types.Encoder, t OffsetDeleteTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p OffsetDeletePartition) {
+			e.Int32(p.PartitionIndex)
+		})
+	})
+	return e.Err()
+}
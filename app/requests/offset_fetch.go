@@ -0,0 +1,108 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetFetch covers versions 1 through 8; version 6 onwards is flexible.
+// Versions 8 and later fetch for a batch of groups, earlier versions for
+// GroupID and Topics alone.
+type OffsetFetch struct {
+	Version       int16              `desc:"-"`
+	GroupID       string             `desc:"group_id"`
+	Topics        []OffsetFetchTopic `desc:"topics"`
+	Groups        []OffsetFetchGroup `desc:"groups"`
+	RequireStable bool               `desc:"require_stable"`
+	TaggedFields  types.TaggedFields `desc:"_tagged_fields"`
+}
+
+// OffsetFetchGroup asks for the offsets of Topics, or of every partition
+// the group committed for when Topics is null.
+type OffsetFetchGroup struct {
+	GroupID      string             `desc:"group_id"`
+	Topics       []OffsetFetchTopic `desc:"topics"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type OffsetFetchTopic struct {
+	Name             string             `desc:"name"`
+	PartitionIndexes []int32            `desc:"partition_indexes"`
+	TaggedFields     types.TaggedFields `desc:"_tagged_fields"`
+}
+
+// FetchGroups returns the groups fetched for, whatever the version.
+func (o *OffsetFetch) FetchGroups() []OffsetFetchGroup {
+	if o.Version >= 8 {
+		return o.Groups
+	}
+	return []OffsetFetchGroup{{GroupID: o.GroupID, Topics: o.Topics}}
+}
+
+func ParseOffsetFetch(r *bytes.Reader, version int16) (*OffsetFetch, error) {
+	d := types.NewDecoder(r, version >= 6)
+	o := &OffsetFetch{Version: version}
+	if version < 8 {
+		o.GroupID = d.String()
+		o.Topics = decodeOffsetFetchTopics(d)
+	} else {
+		o.Groups = types.DecodeArray(d, func(d *types.Decoder) OffsetFetchGroup {
+			return OffsetFetchGroup{
+				GroupID:      d.String(),
+				Topics:       decodeOffsetFetchTopics(d),
+				TaggedFields: d.TaggedFields(),
+			}
+		})
+	}
+	if version >= 7 {
+		o.RequireStable = d.Bool()
+	}
+	o.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func decodeOffsetFetchTopics(d *types.Decoder) []OffsetFetchTopic {
+	return types.DecodeArray(d, func(d *types.Decoder) OffsetFetchTopic {
+		return OffsetFetchTopic{
+			Name:             d.String(),
+			PartitionIndexes: types.DecodeArray(d, (*types.Decoder).Int32),
+			TaggedFields:     d.TaggedFields(),
+		}
+	})
+}
+
+func (o *OffsetFetch) Write(w io.Writer) error {
+	e := types.NewEncoder(w, o.Version >= 6)
+	if o.Version < 8 {
+		e.String(o.GroupID)
+		encodeOffsetFetchTopics(e, o.Topics)
+	} else {
+		types.EncodeArray(e, o.Groups, func(e *types.Encoder, g OffsetFetchGroup) {
+			e.String(g.GroupID)
+			encodeOffsetFetchTopics(e, g.Topics)
+			e.TaggedFields(g.TaggedFields)
+		})
+	}
+	if o.Version >= 7 {
+		e.Bool(o.RequireStable)
+	}
+	e.TaggedFields(o.TaggedFields)
+	return e.Err()
+}
+
+func encodeOffsetFetchTopics(e *types.Encoder, topics []OffsetFetchTopic) {
+	if topics == nil {
+		e.ArrayLength(-1)
+		return
+	}
+	types.EncodeArray(e, topics, func(e *types.Encoder, t OffsetFetchTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.PartitionIndexes, (*types.Encoder).Int32)
+		e.TaggedFields(t.TaggedFields)
+	})
+}
@@ -0,0 +1,71 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Produce covers versions 3 through 11; version 9 onwards is flexible.
+type Produce struct {
+	Version         int16              `desc:"-"`
+	TransactionalID *string            `desc:"transactional_id"`
+	Acks            int16              `desc:"acks"`
+	TimeoutMs       int32              `desc:"timeout_ms"`
+	Topics          []ProduceTopic     `desc:"topic_data"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ProduceTopic struct {
+	Name         string             `desc:"name"`
+	Partitions   []ProducePartition `desc:"partition_data"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ProducePartition struct {
+	Index        int32              `desc:"index"`
+	Records      []byte             `desc:"records"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseProduce(r *bytes.Reader, version int16) (*Produce, error) {
+	d := types.NewDecoder(r, version >= 9)
+	p := &Produce{Version: version}
+	p.TransactionalID = d.NullableString()
+	p.Acks = d.Int16()
+	p.TimeoutMs = d.Int32()
+	p.Topics = types.DecodeArray(d, func(d *types.Decoder) ProduceTopic {
+		var t ProduceTopic
+		t.Name = d.String()
+		t.Partitions = types.DecodeArray(d, func(d *types.Decoder) ProducePartition {
+			return ProducePartition{
+				Index:        d.Int32(),
+				Records:      d.NullableBytes(),
+				TaggedFields: d.TaggedFields(),
+			}
+		})
+		t.TaggedFields = d.TaggedFields()
+		return t
+	})
+	p.TaggedFields = d.TaggedFields()
+	return p, d.Err()
+}
+
+func (p *Produce) Write(w io.Writer) error {
+	e := types.NewEncoder(w, p.Version >= 9)
+	e.NullableString(p.TransactionalID)
+	e.Int16(p.Acks)
+	e.Int32(p.TimeoutMs)
+	types.EncodeArray(e, p.Topics, func(e *types.Encoder, t ProduceTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, pd ProducePartition) {
+			e.Int32(pd.Index)
+			e.NullableBytes(pd.Records)
+			e.TaggedFields(pd.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(p.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,75 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// SyncGroup covers versions 0 through 5; version 4 onwards is flexible.
+type SyncGroup struct {
+	Version         int16                 `desc:"-"`
+	GroupID         string                `desc:"group_id"`
+	GenerationID    int32                 `desc:"generation_id"`
+	MemberID        string                `desc:"member_id"`
+	GroupInstanceID *string               `desc:"group_instance_id"`
+	ProtocolType    *string               `desc:"protocol_type"`
+	ProtocolName    *string               `desc:"protocol_name"`
+	Assignments     []SyncGroupAssignment `desc:"assignments"`
+	TaggedFields    types.TaggedFields    `desc:"_tagged_fields"`
+}
+
+type SyncGroupAssignment struct {
+	MemberID     string             `desc:"member_id"`
+	Assignment   []byte             `desc:"assignment"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseSyncGroup(r *bytes.Reader, version int16) (*SyncGroup, error) {
+	d := types.NewDecoder(r, version >= 4)
+	s := &SyncGroup{Version: version}
+	s.GroupID = d.String()
+	s.GenerationID = d.Int32()
+	s.MemberID = d.String()
+	if version >= 3 {
+		s.GroupInstanceID = d.NullableString()
+	}
+	if version >= 5 {
+		s.ProtocolType = d.NullableString()
+		s.ProtocolName = d.NullableString()
+	}
+	s.Assignments = types.DecodeArray(d, func(d *types.Decoder) SyncGroupAssignment {
+		return SyncGroupAssignment{
+			MemberID:     d.String(),
+			Assignment:   d.Bytes(),
+			TaggedFields: d.TaggedFields(),
+		}
+	})
+	s.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyncGroup) Write(w io.Writer) error {
+	e := types.NewEncoder(w, s.Version >= 4)
+	e.String(s.GroupID)
+	e.Int32(s.GenerationID)
+	e.String(s.MemberID)
+	if s.Version >= 3 {
+		e.NullableString(s.GroupInstanceID)
+	}
+	if s.Version >= 5 {
+		e.NullableString(s.ProtocolType)
+		e.NullableString(s.ProtocolName)
+	}
+	types.EncodeArray(e, s.Assignments, func(e *types.Encoder, a SyncGroupAssignment) {
+		e.String(a.MemberID)
+		e.Bytes(a.Assignment)
+		e.TaggedFields(a.TaggedFields)
+	})
+	e.TaggedFields(s.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,79 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Upgrade types of UpdateFeatures version 1 onwards.
+const (
+	UpgradeTypeUpgrade         int8 = 1
+	UpgradeTypeSafeDowngrade   int8 = 2
+	UpgradeTypeUnsafeDowngrade int8 = 3
+)
+
+// UpdateFeatures covers versions 0 and 1, both flexible. Version 1
+// replaced allow_downgrade with upgrade_type and added validate_only;
+// version 0 requests are normalized to an upgrade type on parsing.
+type UpdateFeatures struct {
+	Version        int16              `desc:"-"`
+	TimeoutMs      int32              `desc:"timeout_ms"`
+	FeatureUpdates []FeatureUpdate    `desc:"feature_updates"`
+	ValidateOnly   bool               `desc:"validate_only"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type FeatureUpdate struct {
+	Feature         string             `desc:"feature"`
+	MaxVersionLevel int16              `desc:"max_version_level"`
+	UpgradeType     int8               `desc:"upgrade_type"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func ParseUpdateFeatures(r *bytes.Reader, version int16) (*UpdateFeatures, error) {
+	d := types.NewDecoder(r, true)
+	u := &UpdateFeatures{Version: version}
+	u.TimeoutMs = d.Int32()
+	u.FeatureUpdates = types.DecodeArray(d, func(d *types.Decoder) FeatureUpdate {
+		f := FeatureUpdate{Feature: d.String(), MaxVersionLevel: d.Int16(), UpgradeType: UpgradeTypeUpgrade}
+		if version == 0 {
+			if d.Bool() {
+				f.UpgradeType = UpgradeTypeSafeDowngrade
+			}
+		} else {
+			f.UpgradeType = d.Int8()
+		}
+		f.TaggedFields = d.TaggedFields()
+		return f
+	})
+	if version >= 1 {
+		u.ValidateOnly = d.Bool()
+	}
+	u.TaggedFields = d.TaggedFields()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (u *UpdateFeatures) Write(w io.Writer) error {
+	e := types.NewEncoder(w, true)
+	e.Int32(u.TimeoutMs)
+	types.EncodeArray(e, u.FeatureUpdates, func(e *types.Encoder, f FeatureUpdate) {
+		e.String(f.Feature)
+		e.Int16(f.MaxVersionLevel)
+		if u.Version == 0 {
+			e.Bool(f.UpgradeType != UpgradeTypeUpgrade)
+		} else {
+			e.Int8(f.UpgradeType)
+		}
+		e.TaggedFields(f.TaggedFields)
+	})
+	if u.Version >= 1 {
+		e.Bool(u.ValidateOnly)
+	}
+	e.TaggedFields(u.TaggedFields)
+	return e.Err()
+}
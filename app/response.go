@@ -38,6 +38,14 @@ func (rh *ResponseHeaderV1) Write(w io.Writer) error {
 type ResponseHeaderV2 struct {
 }
 
+// NewResponseHeader builds the response header of the given version.
+func NewResponseHeader(version int16, correlationID int32) ResponseHeader {
+	if version >= 1 {
+		return &ResponseHeaderV1{CorrelationID: correlationID}
+	}
+	return &ResponseHeaderV0{CorrelationID: correlationID}
+}
+
 type Response struct {
 	MessageSize int32          `desc:"message_size"`
 	Header      ResponseHeader `desc:"response_header"`
@@ -0,0 +1,41 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// AlterConfigs covers versions 0 through 2; version 2 onwards is flexible.
+type AlterConfigs struct {
+	Version        int16                          `desc:"-"`
+	ThrottleTimeMs int32                          `desc:"throttle_time_ms"`
+	Responses      []AlterConfigsResourceResponse `desc:"responses"`
+	TaggedFields   types.TaggedFields             `desc:"_tagged_fields"`
+}
+
+// AlterConfigsResourceResponse is shared with IncrementalAlterConfigs.
+type AlterConfigsResourceResponse struct {
+	ErrorCode    int16              `desc:"error_code"`
+	ErrorMessage *string            `desc:"error_message"`
+	ResourceType int8               `desc:"resource_type"`
+	ResourceName string             `desc:"resource_name"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *AlterConfigs) Write(w io.Writer) error {
+	return writeAlterConfigsResponses(types.NewEncoder(w, r.Version >= 2), r.ThrottleTimeMs, r.Responses, r.TaggedFields)
+}
+
+func writeAlterConfigsResponses(e *types.Encoder, throttleTimeMs int32, responses []AlterConfigsResourceResponse, tags types.TaggedFields) error {
+	e.Int32(throttleTimeMs)
+	types.EncodeArray(e, responses, func(e *types.Encoder, res AlterConfigsResourceResponse) {
+		e.Int16(res.ErrorCode)
+		e.NullableString(res.ErrorMessage)
+		e.Int8(res.ResourceType)
+		e.String(res.ResourceName)
+		e.TaggedFields(res.TaggedFields)
+	})
+	e.TaggedFields(tags)
+	return e.Err()
+}
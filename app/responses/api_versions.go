@@ -1,17 +1,25 @@
 package responses
 
 import (
-	"encoding/binary"
+	"bytes"
 	"io"
 
 	"github.com/nabinkhanal00/kafka/app/types"
 )
 
-type APIVersionsV4 struct {
-	ErrorCode      int16              `desc:"error_code"`
-	APIKeys        []APIKey           `desc:"api_keys"`
-	ThrottleTimeMS int32              `desc:"throttle_time_ms"`
-	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+// APIVersions covers versions 0 through 4. Version 0 has no throttle time
+// and version 3 onwards is flexible and carries the feature fields as
+// tagged fields, each omitted when it holds its default.
+type APIVersions struct {
+	Version                int16              `desc:"-"`
+	ErrorCode              int16              `desc:"error_code"`
+	APIKeys                []APIKey           `desc:"api_keys"`
+	ThrottleTimeMS         int32              `desc:"throttle_time_ms"`
+	SupportedFeatures      []SupportedFeature `desc:"supported_features"`
+	FinalizedFeaturesEpoch int64              `desc:"finalized_features_epoch"`
+	FinalizedFeatures      []FinalizedFeature `desc:"finalized_features"`
+	ZkMigrationReady       bool               `desc:"zk_migration_ready"`
+	TaggedFields           types.TaggedFields `desc:"_tagged_fields"`
 }
 type APIKey struct {
 	Key          int16              `desc:"api_key"`
@@ -20,18 +28,84 @@ type APIKey struct {
 	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
 }
 
-func (r *APIVersionsV4) Write(w io.Writer) error {
-	if err := binary.Write(w, binary.BigEndian, r.ErrorCode); err != nil {
-		return err
+type SupportedFeature struct {
+	Name         string             `desc:"name"`
+	MinVersion   int16              `desc:"min_version"`
+	MaxVersion   int16              `desc:"max_version"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type FinalizedFeature struct {
+	Name            string             `desc:"name"`
+	MaxVersionLevel int16              `desc:"max_version_level"`
+	MinVersionLevel int16              `desc:"min_version_level"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+const (
+	supportedFeaturesTag      = 0
+	finalizedFeaturesEpochTag = 1
+	finalizedFeaturesTag      = 2
+	zkMigrationReadyTag       = 3
+)
+
+func (r *APIVersions) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 3)
+	e.Int16(r.ErrorCode)
+	types.EncodeArray(e, r.APIKeys, func(e *types.Encoder, apikey APIKey) {
+		e.Int16(apikey.Key)
+		e.Int16(apikey.MinVersion)
+		e.Int16(apikey.MaxVersion)
+		e.TaggedFields(apikey.TaggedFields)
+	})
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMS)
 	}
-	binary.Write(w, binary.BigEndian, uint8(len(r.APIKeys)+1))
-	for _, apikey := range r.APIKeys {
-		binary.Write(w, binary.BigEndian, apikey.Key)
-		binary.Write(w, binary.BigEndian, apikey.MinVersion)
-		binary.Write(w, binary.BigEndian, apikey.MaxVersion)
-		apikey.TaggedFields.Write(w)
+	e.TaggedFields(r.featureTags())
+	return e.Err()
+}
+
+// featureTags adds the non-default feature fields to TaggedFields.
+func (r *APIVersions) featureTags() types.TaggedFields {
+	tags := types.TaggedFields{Fields: make(map[uint64][]byte)}
+	for tag, v := range r.TaggedFields.Fields {
+		tags.Fields[tag] = v
+	}
+	if len(r.SupportedFeatures) > 0 {
+		tags.Fields[supportedFeaturesTag] = encodeTag(func(e *types.Encoder) {
+			types.EncodeArray(e, r.SupportedFeatures, func(e *types.Encoder, f SupportedFeature) {
+				e.String(f.Name)
+				e.Int16(f.MinVersion)
+				e.Int16(f.MaxVersion)
+				e.TaggedFields(f.TaggedFields)
+			})
+		})
+	}
+	if r.FinalizedFeaturesEpoch != -1 {
+		tags.Fields[finalizedFeaturesEpochTag] = encodeTag(func(e *types.Encoder) {
+			e.Int64(r.FinalizedFeaturesEpoch)
+		})
 	}
-	binary.Write(w, binary.BigEndian, r.ThrottleTimeMS)
-	r.TaggedFields.Write(w)
-	return nil
+	if len(r.FinalizedFeatures) > 0 {
+		tags.Fields[finalizedFeaturesTag] = encodeTag(func(e *types.Encoder) {
+			types.EncodeArray(e, r.FinalizedFeatures, func(e *types.Encoder, f FinalizedFeature) {
+				e.String(f.Name)
+				e.Int16(f.MaxVersionLevel)
+				e.Int16(f.MinVersionLevel)
+				e.TaggedFields(f.TaggedFields)
+			})
+		})
+	}
+	if r.ZkMigrationReady {
+		tags.Fields[zkMigrationReadyTag] = encodeTag(func(e *types.Encoder) {
+			e.Bool(true)
+		})
+	}
+	return tags
+}
+
+func encodeTag(f func(*types.Encoder)) []byte {
+	var buf bytes.Buffer
+	f(types.NewEncoder(&buf, true))
+	return buf.Bytes()
 }
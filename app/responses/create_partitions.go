@@ -0,0 +1,36 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// CreatePartitions covers versions 0 through 3; version 2 onwards is
+// flexible.
+type CreatePartitions struct {
+	Version        int16                         `desc:"-"`
+	ThrottleTimeMs int32                         `desc:"throttle_time_ms"`
+	Results        []CreatePartitionsTopicResult `desc:"results"`
+	TaggedFields   types.TaggedFields            `desc:"_tagged_fields"`
+}
+
+type CreatePartitionsTopicResult struct {
+	Name         string             `desc:"name"`
+	ErrorCode    int16              `desc:"error_code"`
+	ErrorMessage *string            `desc:"error_message"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *CreatePartitions) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 2)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Results, func(e *types.Encoder, t CreatePartitionsTopicResult) {
+		e.String(t.Name)
+		e.Int16(t.ErrorCode)
+		e.NullableString(t.ErrorMessage)
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
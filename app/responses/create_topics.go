@@ -0,0 +1,83 @@
+package responses
+
+import (
+	"io"
+	"maps"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// CreateTopics covers versions 2 through 7; version 5 onwards is flexible.
+type CreateTopics struct {
+	Version        int16                  `desc:"-"`
+	ThrottleTimeMs int32                  `desc:"throttle_time_ms"`
+	Topics         []CreatableTopicResult `desc:"topics"`
+	TaggedFields   types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+// CreatableTopicResult carries the partition count, replication factor
+// and configs from version 5; Configs is nil when they are not reported.
+type CreatableTopicResult struct {
+	Name                 string                  `desc:"name"`
+	TopicID              types.UUID              `desc:"topic_id"`
+	ErrorCode            int16                   `desc:"error_code"`
+	ErrorMessage         *string                 `desc:"error_message"`
+	TopicConfigErrorCode int16                   `desc:"topic_config_error_code"`
+	NumPartitions        int32                   `desc:"num_partitions"`
+	ReplicationFactor    int16                   `desc:"replication_factor"`
+	Configs              []CreatableTopicConfigs `desc:"configs"`
+	TaggedFields         types.TaggedFields      `desc:"_tagged_fields"`
+}
+
+type CreatableTopicConfigs struct {
+	Name         string             `desc:"name"`
+	Value        *string            `desc:"value"`
+	ReadOnly     bool               `desc:"read_only"`
+	ConfigSource int8               `desc:"config_source"`
+	IsSensitive  bool               `desc:"is_sensitive"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+const topicConfigErrorCodeTag = 0
+
+func (r *CreateTopics) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 5)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Topics, func(e *types.Encoder, t CreatableTopicResult) {
+		e.String(t.Name)
+		if r.Version >= 7 {
+			e.UUID(t.TopicID)
+		}
+		e.Int16(t.ErrorCode)
+		e.NullableString(t.ErrorMessage)
+		if r.Version >= 5 {
+			e.Int32(t.NumPartitions)
+			e.Int16(t.ReplicationFactor)
+			if t.Configs == nil {
+				e.ArrayLength(-1)
+			} else {
+				types.EncodeArray(e, t.Configs, func(e *types.Encoder, c CreatableTopicConfigs) {
+					e.String(c.Name)
+					e.NullableString(c.Value)
+					e.Bool(c.ReadOnly)
+					e.Int8(c.ConfigSource)
+					e.Bool(c.IsSensitive)
+					e.TaggedFields(c.TaggedFields)
+				})
+			}
+		}
+		tags := t.TaggedFields
+		if t.TopicConfigErrorCode != 0 {
+			tags = types.TaggedFields{Fields: maps.Clone(t.TaggedFields.Fields)}
+			if tags.Fields == nil {
+				tags.Fields = make(map[uint64][]byte)
+			}
+			tags.Fields[topicConfigErrorCodeTag] = encodeTag(func(e *types.Encoder) {
+				e.Int16(t.TopicConfigErrorCode)
+			})
+		}
+		e.TaggedFields(tags)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DeleteGroups covers versions 0 through 2; version 2 is flexible.
+type DeleteGroups struct {
+	Version        int16                  `desc:"-"`
+	ThrottleTimeMs int32                  `desc:"throttle_time_ms"`
+	Results        []DeletableGroupResult `desc:"results"`
+	TaggedFields   types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+type DeletableGroupResult struct {
+	GroupID      string             `desc:"group_id"`
+	ErrorCode    int16              `desc:"error_code"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *DeleteGroups) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 2)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Results, func(e *types.Encoder, g DeletableGroupResult) {
+		e.String(g.GroupID)
+		e.Int16(g.ErrorCode)
+		e.TaggedFields(g.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
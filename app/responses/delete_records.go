@@ -0,0 +1,45 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DeleteRecords covers versions 0 through 2; version 2 is flexible.
+type DeleteRecords struct {
+	Version        int16                      `desc:"-"`
+	ThrottleTimeMs int32                      `desc:"throttle_time_ms"`
+	Topics         []DeleteRecordsTopicResult `desc:"topics"`
+	TaggedFields   types.TaggedFields         `desc:"_tagged_fields"`
+}
+
+type DeleteRecordsTopicResult struct {
+	Name         string                         `desc:"name"`
+	Partitions   []DeleteRecordsPartitionResult `desc:"partitions"`
+	TaggedFields types.TaggedFields             `desc:"_tagged_fields"`
+}
+
+type DeleteRecordsPartitionResult struct {
+	PartitionIndex int32              `desc:"partition_index"`
+	LowWatermark   int64              `desc:"low_watermark"`
+	ErrorCode      int16              `desc:"error_code"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *DeleteRecords) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 2)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Topics, func(e *types.Encoder, t DeleteRecordsTopicResult) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p DeleteRecordsPartitionResult) {
+			e.Int32(p.PartitionIndex)
+			e.Int64(p.LowWatermark)
+			e.Int16(p.ErrorCode)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,45 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DeleteTopics covers versions 1 through 6; version 4 onwards is flexible.
+type DeleteTopics struct {
+	Version        int16                  `desc:"-"`
+	ThrottleTimeMs int32                  `desc:"throttle_time_ms"`
+	Responses      []DeletableTopicResult `desc:"responses"`
+	TaggedFields   types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+type DeletableTopicResult struct {
+	Name         *string            `desc:"name"`
+	TopicID      types.UUID         `desc:"topic_id"`
+	ErrorCode    int16              `desc:"error_code"`
+	ErrorMessage *string            `desc:"error_message"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *DeleteTopics) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 4)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Responses, func(e *types.Encoder, t DeletableTopicResult) {
+		if r.Version >= 6 {
+			e.NullableString(t.Name)
+			e.UUID(t.TopicID)
+		} else if t.Name != nil {
+			e.String(*t.Name)
+		} else {
+			e.String("")
+		}
+		e.Int16(t.ErrorCode)
+		if r.Version >= 5 {
+			e.NullableString(t.ErrorMessage)
+		}
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,85 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DescribeConfigs covers versions 0 through 4; version 4 onwards is
+// flexible.
+type DescribeConfigs struct {
+	Version        int16                   `desc:"-"`
+	ThrottleTimeMs int32                   `desc:"throttle_time_ms"`
+	Results        []DescribeConfigsResult `desc:"results"`
+	TaggedFields   types.TaggedFields      `desc:"_tagged_fields"`
+}
+
+type DescribeConfigsResult struct {
+	ErrorCode    int16                           `desc:"error_code"`
+	ErrorMessage *string                         `desc:"error_message"`
+	ResourceType int8                            `desc:"resource_type"`
+	ResourceName string                          `desc:"resource_name"`
+	Configs      []DescribeConfigsResourceResult `desc:"configs"`
+	TaggedFields types.TaggedFields              `desc:"_tagged_fields"`
+}
+
+// DescribeConfigsResourceResult reports IsDefault in version 0 and the
+// finer grained ConfigSource from version 1.
+type DescribeConfigsResourceResult struct {
+	Name          string                   `desc:"name"`
+	Value         *string                  `desc:"value"`
+	ReadOnly      bool                     `desc:"read_only"`
+	IsDefault     bool                     `desc:"is_default"`
+	ConfigSource  int8                     `desc:"config_source"`
+	IsSensitive   bool                     `desc:"is_sensitive"`
+	Synonyms      []DescribeConfigsSynonym `desc:"synonyms"`
+	ConfigType    int8                     `desc:"config_type"`
+	Documentation *string                  `desc:"documentation"`
+	TaggedFields  types.TaggedFields       `desc:"_tagged_fields"`
+}
+
+type DescribeConfigsSynonym struct {
+	Name         string             `desc:"name"`
+	Value        *string            `desc:"value"`
+	Source       int8               `desc:"source"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *DescribeConfigs) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 4)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Results, func(e *types.Encoder, res DescribeConfigsResult) {
+		e.Int16(res.ErrorCode)
+		e.NullableString(res.ErrorMessage)
+		e.Int8(res.ResourceType)
+		e.String(res.ResourceName)
+		types.EncodeArray(e, res.Configs, func(e *types.Encoder, c DescribeConfigsResourceResult) {
+			e.String(c.Name)
+			e.NullableString(c.Value)
+			e.Bool(c.ReadOnly)
+			if r.Version == 0 {
+				e.Bool(c.IsDefault)
+			} else {
+				e.Int8(c.ConfigSource)
+			}
+			e.Bool(c.IsSensitive)
+			if r.Version >= 1 {
+				types.EncodeArray(e, c.Synonyms, func(e *types.Encoder, s DescribeConfigsSynonym) {
+					e.String(s.Name)
+					e.NullableString(s.Value)
+					e.Int8(s.Source)
+					e.TaggedFields(s.TaggedFields)
+				})
+			}
+			if r.Version >= 3 {
+				e.Int8(c.ConfigType)
+				e.NullableString(c.Documentation)
+			}
+			e.TaggedFields(c.TaggedFields)
+		})
+		e.TaggedFields(res.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
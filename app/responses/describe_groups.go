@@ -0,0 +1,67 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// DescribeGroups covers versions 0 through 5; version 5 is flexible.
+type DescribeGroups struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	Groups         []DescribedGroup   `desc:"groups"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type DescribedGroup struct {
+	ErrorCode            int16                  `desc:"error_code"`
+	GroupID              string                 `desc:"group_id"`
+	GroupState           string                 `desc:"group_state"`
+	ProtocolType         string                 `desc:"protocol_type"`
+	ProtocolData         string                 `desc:"protocol_data"`
+	Members              []DescribedGroupMember `desc:"members"`
+	AuthorizedOperations int32                  `desc:"authorized_operations"`
+	TaggedFields         types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+type DescribedGroupMember struct {
+	MemberID         string             `desc:"member_id"`
+	GroupInstanceID  *string            `desc:"group_instance_id"`
+	ClientID         string             `desc:"client_id"`
+	ClientHost       string             `desc:"client_host"`
+	MemberMetadata   []byte             `desc:"member_metadata"`
+	MemberAssignment []byte             `desc:"member_assignment"`
+	TaggedFields     types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *DescribeGroups) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 5)
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	types.EncodeArray(e, r.Groups, func(e *types.Encoder, g DescribedGroup) {
+		e.Int16(g.ErrorCode)
+		e.String(g.GroupID)
+		e.String(g.GroupState)
+		e.String(g.ProtocolType)
+		e.String(g.ProtocolData)
+		types.EncodeArray(e, g.Members, func(e *types.Encoder, m DescribedGroupMember) {
+			e.String(m.MemberID)
+			if r.Version >= 4 {
+				e.NullableString(m.GroupInstanceID)
+			}
+			e.String(m.ClientID)
+			e.String(m.ClientHost)
+			e.Bytes(m.MemberMetadata)
+			e.Bytes(m.MemberAssignment)
+			e.TaggedFields(m.TaggedFields)
+		})
+		if r.Version >= 3 {
+			e.Int32(g.AuthorizedOperations)
+		}
+		e.TaggedFields(g.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
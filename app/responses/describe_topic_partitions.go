@@ -27,10 +27,12 @@ func (n *Nodes) Write(w io.Writer) error {
 type DescribeTopicPartitionsV0 struct {
 	ThrottleTime int32              `desc:"throttle_time"`
 	Topics       []Topic            `desc:"topics"`
-	NextCursor   Cursor             `desc:"next_cursor"`
+	NextCursor   *Cursor            `desc:"next_cursor"`
 	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
 }
 
+// Cursor is a nullable struct: -1 when there is nothing left to page
+// through, 1 followed by the fields otherwise.
 type Cursor struct {
 	TopicName      types.CompactString `desc:"topic_name"`
 	PartitionIndex int32               `desc:"partition_index"`
@@ -38,6 +40,12 @@ type Cursor struct {
 }
 
 func (c *Cursor) Write(w io.Writer) error {
+	if c == nil {
+		return binary.Write(w, binary.BigEndian, int8(-1))
+	}
+	if err := binary.Write(w, binary.BigEndian, int8(1)); err != nil {
+		return err
+	}
 	if err := c.TopicName.Write(w); err != nil {
 		return err
 	}
@@ -58,8 +66,7 @@ type Topic struct {
 }
 
 type Partitions struct {
-	Partitions   []Partition        `desc:"partitions"`
-	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+	Partitions []Partition `desc:"partitions"`
 }
 
 func (p *Partitions) Write(w io.Writer) error {
@@ -72,7 +79,7 @@ func (p *Partitions) Write(w io.Writer) error {
 			return err
 		}
 	}
-	return p.TaggedFields.Write(w)
+	return nil
 }
 
 func (t *Topic) Write(w io.Writer) error {
@@ -98,15 +105,16 @@ func (t *Topic) Write(w io.Writer) error {
 }
 
 type Partition struct {
-	ErrorCode              int16 `desc:"error_code"`
-	PartitionIndex         int32 `desc:"partition_index"`
-	LeaderID               int32 `desc:"leader_id"`
-	LeaderEpoch            int32 `desc:"leader_epoch"`
-	ReplicaNodes           Nodes `desc:"replica_nodes"`
-	ISRNodes               Nodes `desc:"isr_nodes"`
-	EligibleLeaderReplicas Nodes `desc:"eligible_leader_replicas"`
-	LastKnownELRs          Nodes `desc:"last_known_eligible_leader_replicas"`
-	OfflineReplicas        Nodes `desc:"offline_replicas"`
+	ErrorCode              int16              `desc:"error_code"`
+	PartitionIndex         int32              `desc:"partition_index"`
+	LeaderID               int32              `desc:"leader_id"`
+	LeaderEpoch            int32              `desc:"leader_epoch"`
+	ReplicaNodes           Nodes              `desc:"replica_nodes"`
+	ISRNodes               Nodes              `desc:"isr_nodes"`
+	EligibleLeaderReplicas Nodes              `desc:"eligible_leader_replicas"`
+	LastKnownELRs          Nodes              `desc:"last_known_eligible_leader_replicas"`
+	OfflineReplicas        Nodes              `desc:"offline_replicas"`
+	TaggedFields           types.TaggedFields `desc:"_tagged_fields"`
 }
 
 func (p *Partition) Write(w io.Writer) error {
@@ -135,7 +143,10 @@ func (p *Partition) Write(w io.Writer) error {
 	if err := p.LastKnownELRs.Write(w); err != nil {
 		return err
 	}
-	return p.OfflineReplicas.Write(w)
+	if err := p.OfflineReplicas.Write(w); err != nil {
+		return err
+	}
+	return p.TaggedFields.Write(w)
 }
 
 func (r *DescribeTopicPartitionsV0) Write(w io.Writer) error {
@@ -0,0 +1,85 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Fetch covers versions 4 through 16. Version 12 onwards is flexible and
+// version 13 onwards names topics by id instead of by name.
+type Fetch struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	ErrorCode      int16              `desc:"error_code"`
+	SessionID      int32              `desc:"session_id"`
+	Responses      []FetchTopic       `desc:"responses"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type FetchTopic struct {
+	Topic        string             `desc:"topic"`
+	TopicID      types.UUID         `desc:"topic_id"`
+	Partitions   []FetchPartition   `desc:"partitions"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type FetchPartition struct {
+	PartitionIndex       int32                     `desc:"partition_index"`
+	ErrorCode            int16                     `desc:"error_code"`
+	HighWatermark        int64                     `desc:"high_watermark"`
+	LastStableOffset     int64                     `desc:"last_stable_offset"`
+	LogStartOffset       int64                     `desc:"log_start_offset"`
+	AbortedTransactions  []FetchAbortedTransaction `desc:"aborted_transactions"`
+	PreferredReadReplica int32                     `desc:"preferred_read_replica"`
+	Records              []byte                    `desc:"records"`
+	TaggedFields         types.TaggedFields        `desc:"_tagged_fields"`
+}
+
+type FetchAbortedTransaction struct {
+	ProducerID   int64              `desc:"producer_id"`
+	FirstOffset  int64              `desc:"first_offset"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *Fetch) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 12)
+	e.Int32(r.ThrottleTimeMs)
+	if r.Version >= 7 {
+		e.Int16(r.ErrorCode)
+		e.Int32(r.SessionID)
+	}
+	types.EncodeArray(e, r.Responses, func(e *types.Encoder, t FetchTopic) {
+		if r.Version >= 13 {
+			e.UUID(t.TopicID)
+		} else {
+			e.String(t.Topic)
+		}
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p FetchPartition) {
+			e.Int32(p.PartitionIndex)
+			e.Int16(p.ErrorCode)
+			e.Int64(p.HighWatermark)
+			e.Int64(p.LastStableOffset)
+			if r.Version >= 5 {
+				e.Int64(p.LogStartOffset)
+			}
+			if p.AbortedTransactions == nil {
+				e.ArrayLength(-1)
+			} else {
+				types.EncodeArray(e, p.AbortedTransactions, func(e *types.Encoder, a FetchAbortedTransaction) {
+					e.Int64(a.ProducerID)
+					e.Int64(a.FirstOffset)
+					e.TaggedFields(a.TaggedFields)
+				})
+			}
+			if r.Version >= 11 {
+				e.Int32(p.PreferredReadReplica)
+			}
+			e.NullableBytes(p.Records)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
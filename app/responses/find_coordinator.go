@@ -0,0 +1,60 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// FindCoordinator covers versions 0 through 5; version 3 onwards is
+// flexible. Versions 4 and later answer with one coordinator per key,
+// earlier versions with the top-level fields.
+type FindCoordinator struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	ErrorCode      int16              `desc:"error_code"`
+	ErrorMessage   *string            `desc:"error_message"`
+	NodeID         int32              `desc:"node_id"`
+	Host           string             `desc:"host"`
+	Port           int32              `desc:"port"`
+	Coordinators   []Coordinator      `desc:"coordinators"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type Coordinator struct {
+	Key          string             `desc:"key"`
+	NodeID       int32              `desc:"node_id"`
+	Host         string             `desc:"host"`
+	Port         int32              `desc:"port"`
+	ErrorCode    int16              `desc:"error_code"`
+	ErrorMessage *string            `desc:"error_message"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *FindCoordinator) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 3)
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	if r.Version >= 4 {
+		types.EncodeArray(e, r.Coordinators, func(e *types.Encoder, c Coordinator) {
+			e.String(c.Key)
+			e.Int32(c.NodeID)
+			e.String(c.Host)
+			e.Int32(c.Port)
+			e.Int16(c.ErrorCode)
+			e.NullableString(c.ErrorMessage)
+			e.TaggedFields(c.TaggedFields)
+		})
+	} else {
+		e.Int16(r.ErrorCode)
+		if r.Version >= 1 {
+			e.NullableString(r.ErrorMessage)
+		}
+		e.Int32(r.NodeID)
+		e.String(r.Host)
+		e.Int32(r.Port)
+	}
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
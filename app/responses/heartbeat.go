@@ -0,0 +1,25 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Heartbeat covers versions 0 through 4; version 4 is flexible.
+type Heartbeat struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	ErrorCode      int16              `desc:"error_code"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *Heartbeat) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 4)
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	e.Int16(r.ErrorCode)
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,19 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// IncrementalAlterConfigs covers versions 0 and 1; version 1 is flexible.
+type IncrementalAlterConfigs struct {
+	Version        int16                          `desc:"-"`
+	ThrottleTimeMs int32                          `desc:"throttle_time_ms"`
+	Responses      []AlterConfigsResourceResponse `desc:"responses"`
+	TaggedFields   types.TaggedFields             `desc:"_tagged_fields"`
+}
+
+func (r *IncrementalAlterConfigs) Write(w io.Writer) error {
+	return writeAlterConfigsResponses(types.NewEncoder(w, r.Version >= 1), r.ThrottleTimeMs, r.Responses, r.TaggedFields)
+}
@@ -0,0 +1,61 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// JoinGroup covers versions 0 through 9; version 6 onwards is flexible.
+type JoinGroup struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	ErrorCode      int16              `desc:"error_code"`
+	GenerationID   int32              `desc:"generation_id"`
+	ProtocolType   *string            `desc:"protocol_type"`
+	ProtocolName   *string            `desc:"protocol_name"`
+	Leader         string             `desc:"leader"`
+	SkipAssignment bool               `desc:"skip_assignment"`
+	MemberID       string             `desc:"member_id"`
+	Members        []JoinGroupMember  `desc:"members"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type JoinGroupMember struct {
+	MemberID        string             `desc:"member_id"`
+	GroupInstanceID *string            `desc:"group_instance_id"`
+	Metadata        []byte             `desc:"metadata"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *JoinGroup) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 6)
+	if r.Version >= 2 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	e.Int16(r.ErrorCode)
+	e.Int32(r.GenerationID)
+	if r.Version >= 7 {
+		e.NullableString(r.ProtocolType)
+		e.NullableString(r.ProtocolName)
+	} else if r.ProtocolName != nil {
+		e.String(*r.ProtocolName)
+	} else {
+		e.String("")
+	}
+	e.String(r.Leader)
+	if r.Version >= 9 {
+		e.Bool(r.SkipAssignment)
+	}
+	e.String(r.MemberID)
+	types.EncodeArray(e, r.Members, func(e *types.Encoder, m JoinGroupMember) {
+		e.String(m.MemberID)
+		if r.Version >= 5 {
+			e.NullableString(m.GroupInstanceID)
+		}
+		e.Bytes(m.Metadata)
+		e.TaggedFields(m.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
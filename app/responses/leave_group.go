@@ -0,0 +1,42 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// LeaveGroup covers versions 0 through 5; version 4 onwards is flexible.
+// Versions 3 and later report on every member.
+type LeaveGroup struct {
+	Version        int16                    `desc:"-"`
+	ThrottleTimeMs int32                    `desc:"throttle_time_ms"`
+	ErrorCode      int16                    `desc:"error_code"`
+	Members        []LeaveGroupMemberResult `desc:"members"`
+	TaggedFields   types.TaggedFields       `desc:"_tagged_fields"`
+}
+
+type LeaveGroupMemberResult struct {
+	MemberID        string             `desc:"member_id"`
+	GroupInstanceID *string            `desc:"group_instance_id"`
+	ErrorCode       int16              `desc:"error_code"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *LeaveGroup) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 4)
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	e.Int16(r.ErrorCode)
+	if r.Version >= 3 {
+		types.EncodeArray(e, r.Members, func(e *types.Encoder, m LeaveGroupMemberResult) {
+			e.String(m.MemberID)
+			e.NullableString(m.GroupInstanceID)
+			e.Int16(m.ErrorCode)
+			e.TaggedFields(m.TaggedFields)
+		})
+	}
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
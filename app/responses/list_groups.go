@@ -0,0 +1,45 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// ListGroups covers versions 0 through 5; version 3 onwards is flexible.
+type ListGroups struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	ErrorCode      int16              `desc:"error_code"`
+	Groups         []ListedGroup      `desc:"groups"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ListedGroup struct {
+	GroupID      string             `desc:"group_id"`
+	ProtocolType string             `desc:"protocol_type"`
+	GroupState   string             `desc:"group_state"`
+	GroupType    string             `desc:"group_type"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *ListGroups) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 3)
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	e.Int16(r.ErrorCode)
+	types.EncodeArray(e, r.Groups, func(e *types.Encoder, g ListedGroup) {
+		e.String(g.GroupID)
+		e.String(g.ProtocolType)
+		if r.Version >= 4 {
+			e.String(g.GroupState)
+		}
+		if r.Version >= 5 {
+			e.String(g.GroupType)
+		}
+		e.TaggedFields(g.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
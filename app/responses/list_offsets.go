@@ -0,0 +1,53 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// ListOffsets covers versions 1 through 9; version 6 onwards is flexible.
+type ListOffsets struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	Topics         []ListOffsetsTopic `desc:"topics"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ListOffsetsTopic struct {
+	Name         string                 `desc:"name"`
+	Partitions   []ListOffsetsPartition `desc:"partitions"`
+	TaggedFields types.TaggedFields     `desc:"_tagged_fields"`
+}
+
+type ListOffsetsPartition struct {
+	PartitionIndex int32              `desc:"partition_index"`
+	ErrorCode      int16              `desc:"error_code"`
+	Timestamp      int64              `desc:"timestamp"`
+	Offset         int64              `desc:"offset"`
+	LeaderEpoch    int32              `desc:"leader_epoch"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *ListOffsets) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 6)
+	if r.Version >= 2 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	types.EncodeArray(e, r.Topics, func(e *types.Encoder, t ListOffsetsTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p ListOffsetsPartition) {
+			e.Int32(p.PartitionIndex)
+			e.Int16(p.ErrorCode)
+			e.Int64(p.Timestamp)
+			e.Int64(p.Offset)
+			if r.Version >= 4 {
+				e.Int32(p.LeaderEpoch)
+			}
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,109 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Metadata covers versions 0 through 12; version 9 onwards is flexible.
+type Metadata struct {
+	Version                     int16              `desc:"-"`
+	ThrottleTimeMs              int32              `desc:"throttle_time_ms"`
+	Brokers                     []MetadataBroker   `desc:"brokers"`
+	ClusterID                   *string            `desc:"cluster_id"`
+	ControllerID                int32              `desc:"controller_id"`
+	Topics                      []MetadataTopic    `desc:"topics"`
+	ClusterAuthorizedOperations int32              `desc:"cluster_authorized_operations"`
+	TaggedFields                types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type MetadataBroker struct {
+	NodeID       int32              `desc:"node_id"`
+	Host         string             `desc:"host"`
+	Port         int32              `desc:"port"`
+	Rack         *string            `desc:"rack"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type MetadataTopic struct {
+	ErrorCode                 int16               `desc:"error_code"`
+	Name                      *string             `desc:"name"`
+	TopicID                   types.UUID          `desc:"topic_id"`
+	IsInternal                bool                `desc:"is_internal"`
+	Partitions                []MetadataPartition `desc:"partitions"`
+	TopicAuthorizedOperations int32               `desc:"topic_authorized_operations"`
+	TaggedFields              types.TaggedFields  `desc:"_tagged_fields"`
+}
+
+type MetadataPartition struct {
+	ErrorCode       int16              `desc:"error_code"`
+	PartitionIndex  int32              `desc:"partition_index"`
+	LeaderID        int32              `desc:"leader_id"`
+	LeaderEpoch     int32              `desc:"leader_epoch"`
+	ReplicaNodes    []int32            `desc:"replica_nodes"`
+	ISRNodes        []int32            `desc:"isr_nodes"`
+	OfflineReplicas []int32            `desc:"offline_replicas"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *Metadata) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 9)
+	if r.Version >= 3 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	types.EncodeArray(e, r.Brokers, func(e *types.Encoder, b MetadataBroker) {
+		e.Int32(b.NodeID)
+		e.String(b.Host)
+		e.Int32(b.Port)
+		if r.Version >= 1 {
+			e.NullableString(b.Rack)
+		}
+		e.TaggedFields(b.TaggedFields)
+	})
+	if r.Version >= 2 {
+		e.NullableString(r.ClusterID)
+	}
+	if r.Version >= 1 {
+		e.Int32(r.ControllerID)
+	}
+	types.EncodeArray(e, r.Topics, func(e *types.Encoder, t MetadataTopic) {
+		e.Int16(t.ErrorCode)
+		if r.Version >= 12 {
+			e.NullableString(t.Name)
+		} else if t.Name != nil {
+			e.String(*t.Name)
+		} else {
+			e.String("")
+		}
+		if r.Version >= 10 {
+			e.UUID(t.TopicID)
+		}
+		if r.Version >= 1 {
+			e.Bool(t.IsInternal)
+		}
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p MetadataPartition) {
+			e.Int16(p.ErrorCode)
+			e.Int32(p.PartitionIndex)
+			e.Int32(p.LeaderID)
+			if r.Version >= 7 {
+				e.Int32(p.LeaderEpoch)
+			}
+			types.EncodeArray(e, p.ReplicaNodes, (*types.Encoder).Int32)
+			types.EncodeArray(e, p.ISRNodes, (*types.Encoder).Int32)
+			if r.Version >= 5 {
+				types.EncodeArray(e, p.OfflineReplicas, (*types.Encoder).Int32)
+			}
+			e.TaggedFields(p.TaggedFields)
+		})
+		if r.Version >= 8 {
+			e.Int32(t.TopicAuthorizedOperations)
+		}
+		e.TaggedFields(t.TaggedFields)
+	})
+	if r.Version >= 8 && r.Version <= 10 {
+		e.Int32(r.ClusterAuthorizedOperations)
+	}
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
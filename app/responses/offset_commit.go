@@ -0,0 +1,45 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetCommit covers versions 2 through 8; version 8 is flexible.
+type OffsetCommit struct {
+	Version        int16                     `desc:"-"`
+	ThrottleTimeMs int32                     `desc:"throttle_time_ms"`
+	Topics         []OffsetCommitTopicResult `desc:"topics"`
+	TaggedFields   types.TaggedFields        `desc:"_tagged_fields"`
+}
+
+type OffsetCommitTopicResult struct {
+	Name         string                        `desc:"name"`
+	Partitions   []OffsetCommitPartitionResult `desc:"partitions"`
+	TaggedFields types.TaggedFields            `desc:"_tagged_fields"`
+}
+
+type OffsetCommitPartitionResult struct {
+	PartitionIndex int32              `desc:"partition_index"`
+	ErrorCode      int16              `desc:"error_code"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *OffsetCommit) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 8)
+	if r.Version >= 3 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	types.EncodeArray(e, r.Topics, func(e *types.Encoder, t OffsetCommitTopicResult) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p OffsetCommitPartitionResult) {
+			e.Int32(p.PartitionIndex)
+			e.Int16(p.ErrorCode)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
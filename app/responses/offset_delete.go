@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetDelete covers version 0, which is not flexible.
+type OffsetDelete struct {
+	Version        int16                     `desc:"-"`
+	ErrorCode      int16                     `desc:"error_code"`
+	ThrottleTimeMs int32                     `desc:"throttle_time_ms"`
+	Topics         []OffsetDeleteTopicResult `desc:"topics"`
+}
+
+type OffsetDeleteTopicResult struct {
+	Name       string                        `desc:"name"`
+	Partitions []OffsetDeletePartitionResult `desc:"partitions"`
+}
+
+type OffsetDeletePartitionResult struct {
+	PartitionIndex int32 `desc:"partition_index"`
+	ErrorCode      int16 `desc:"error_code"`
+}
+
+func (r *OffsetDelete) Write(w io.Writer) error {
+	e := types.NewEncoder(w, false)
+	e.Int16(r.ErrorCode)
+	e.Int32(r.ThrottleTimeMs)
+	types.EncodeArray(e, r.Topics, func(e *types.Encoder, t OffsetDeleteTopicResult) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p OffsetDeletePartitionResult) {
+			e.Int32(p.PartitionIndex)
+			e.Int16(p.ErrorCode)
+		})
+	})
+	return e.Err()
+}
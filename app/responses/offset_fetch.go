@@ -0,0 +1,80 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// OffsetFetch covers versions 1 through 8; version 6 onwards is flexible.
+// Versions 8 and later answer per group, earlier versions with Topics and
+// ErrorCode.
+type OffsetFetch struct {
+	Version        int16                    `desc:"-"`
+	ThrottleTimeMs int32                    `desc:"throttle_time_ms"`
+	Topics         []OffsetFetchTopicResult `desc:"topics"`
+	ErrorCode      int16                    `desc:"error_code"`
+	Groups         []OffsetFetchGroupResult `desc:"groups"`
+	TaggedFields   types.TaggedFields       `desc:"_tagged_fields"`
+}
+
+type OffsetFetchGroupResult struct {
+	GroupID      string                   `desc:"group_id"`
+	Topics       []OffsetFetchTopicResult `desc:"topics"`
+	ErrorCode    int16                    `desc:"error_code"`
+	TaggedFields types.TaggedFields       `desc:"_tagged_fields"`
+}
+
+type OffsetFetchTopicResult struct {
+	Name         string                       `desc:"name"`
+	Partitions   []OffsetFetchPartitionResult `desc:"partitions"`
+	TaggedFields types.TaggedFields           `desc:"_tagged_fields"`
+}
+
+type OffsetFetchPartitionResult struct {
+	PartitionIndex       int32              `desc:"partition_index"`
+	CommittedOffset      int64              `desc:"committed_offset"`
+	CommittedLeaderEpoch int32              `desc:"committed_leader_epoch"`
+	Metadata             *string            `desc:"metadata"`
+	ErrorCode            int16              `desc:"error_code"`
+	TaggedFields         types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *OffsetFetch) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 6)
+	if r.Version >= 3 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	if r.Version < 8 {
+		r.encodeTopics(e, r.Topics)
+		if r.Version >= 2 {
+			e.Int16(r.ErrorCode)
+		}
+	} else {
+		types.EncodeArray(e, r.Groups, func(e *types.Encoder, g OffsetFetchGroupResult) {
+			e.String(g.GroupID)
+			r.encodeTopics(e, g.Topics)
+			e.Int16(g.ErrorCode)
+			e.TaggedFields(g.TaggedFields)
+		})
+	}
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
+
+func (r *OffsetFetch) encodeTopics(e *types.Encoder, topics []OffsetFetchTopicResult) {
+	types.EncodeArray(e, topics, func(e *types.Encoder, t OffsetFetchTopicResult) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p OffsetFetchPartitionResult) {
+			e.Int32(p.PartitionIndex)
+			e.Int64(p.CommittedOffset)
+			if r.Version >= 5 {
+				e.Int32(p.CommittedLeaderEpoch)
+			}
+			e.NullableString(p.Metadata)
+			e.Int16(p.ErrorCode)
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+}
@@ -0,0 +1,67 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// Produce covers versions 3 through 11; version 9 onwards is flexible.
+type Produce struct {
+	Version        int16              `desc:"-"`
+	Responses      []ProduceTopic     `desc:"responses"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ProduceTopic struct {
+	Name         string             `desc:"name"`
+	Partitions   []ProducePartition `desc:"partition_responses"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type ProducePartition struct {
+	Index           int32              `desc:"index"`
+	ErrorCode       int16              `desc:"error_code"`
+	BaseOffset      int64              `desc:"base_offset"`
+	LogAppendTimeMs int64              `desc:"log_append_time_ms"`
+	LogStartOffset  int64              `desc:"log_start_offset"`
+	RecordErrors    []RecordError      `desc:"record_errors"`
+	ErrorMessage    *string            `desc:"error_message"`
+	TaggedFields    types.TaggedFields `desc:"_tagged_fields"`
+}
+
+type RecordError struct {
+	BatchIndex             int32              `desc:"batch_index"`
+	BatchIndexErrorMessage *string            `desc:"batch_index_error_message"`
+	TaggedFields           types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *Produce) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 9)
+	types.EncodeArray(e, r.Responses, func(e *types.Encoder, t ProduceTopic) {
+		e.String(t.Name)
+		types.EncodeArray(e, t.Partitions, func(e *types.Encoder, p ProducePartition) {
+			e.Int32(p.Index)
+			e.Int16(p.ErrorCode)
+			e.Int64(p.BaseOffset)
+			e.Int64(p.LogAppendTimeMs)
+			if r.Version >= 5 {
+				e.Int64(p.LogStartOffset)
+			}
+			if r.Version >= 8 {
+				types.EncodeArray(e, p.RecordErrors, func(e *types.Encoder, re RecordError) {
+					e.Int32(re.BatchIndex)
+					e.NullableString(re.BatchIndexErrorMessage)
+					e.TaggedFields(re.TaggedFields)
+				})
+				e.NullableString(p.ErrorMessage)
+			}
+			e.TaggedFields(p.TaggedFields)
+		})
+		e.TaggedFields(t.TaggedFields)
+	})
+	e.Int32(r.ThrottleTimeMs)
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// SyncGroup covers versions 0 through 5; version 4 onwards is flexible.
+type SyncGroup struct {
+	Version        int16              `desc:"-"`
+	ThrottleTimeMs int32              `desc:"throttle_time_ms"`
+	ErrorCode      int16              `desc:"error_code"`
+	ProtocolType   *string            `desc:"protocol_type"`
+	ProtocolName   *string            `desc:"protocol_name"`
+	Assignment     []byte             `desc:"assignment"`
+	TaggedFields   types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *SyncGroup) Write(w io.Writer) error {
+	e := types.NewEncoder(w, r.Version >= 4)
+	if r.Version >= 1 {
+		e.Int32(r.ThrottleTimeMs)
+	}
+	e.Int16(r.ErrorCode)
+	if r.Version >= 5 {
+		e.NullableString(r.ProtocolType)
+		e.NullableString(r.ProtocolName)
+	}
+	e.Bytes(r.Assignment)
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
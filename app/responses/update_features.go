@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// UpdateFeatures covers versions 0 and 1, which share one flexible format.
+type UpdateFeatures struct {
+	Version        int16                    `desc:"-"`
+	ThrottleTimeMs int32                    `desc:"throttle_time_ms"`
+	ErrorCode      int16                    `desc:"error_code"`
+	ErrorMessage   *string                  `desc:"error_message"`
+	Results        []UpdatableFeatureResult `desc:"results"`
+	TaggedFields   types.TaggedFields       `desc:"_tagged_fields"`
+}
+
+type UpdatableFeatureResult struct {
+	Feature      string             `desc:"feature"`
+	ErrorCode    int16              `desc:"error_code"`
+	ErrorMessage *string            `desc:"error_message"`
+	TaggedFields types.TaggedFields `desc:"_tagged_fields"`
+}
+
+func (r *UpdateFeatures) Write(w io.Writer) error {
+	e := types.NewEncoder(w, true)
+	e.Int32(r.ThrottleTimeMs)
+	e.Int16(r.ErrorCode)
+	e.NullableString(r.ErrorMessage)
+	types.EncodeArray(e, r.Results, func(e *types.Encoder, f UpdatableFeatureResult) {
+		e.String(f.Feature)
+		e.Int16(f.ErrorCode)
+		e.NullableString(f.ErrorMessage)
+		e.TaggedFields(f.TaggedFields)
+	})
+	e.TaggedFields(r.TaggedFields)
+	return e.Err()
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/group"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type SyncGroupHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewSyncGroupHandler(broker *Broker) *SyncGroupHandler {
+	return &SyncGroupHandler{FlexibleSince: 4, broker: broker}
+}
+
+func (h *SyncGroupHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseSyncGroup(r, version)
+}
+
+// Handle returns the assignment of the member, waiting for the leader to
+// send the assignments when the group is still completing its rebalance.
+func (h *SyncGroupHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.SyncGroup)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	sr := group.SyncRequest{
+		GroupID:      rb.GroupID,
+		MemberID:     rb.MemberID,
+		InstanceID:   rb.GroupInstanceID,
+		Generation:   rb.GenerationID,
+		ProtocolType: rb.ProtocolType,
+		ProtocolName: rb.ProtocolName,
+		Assignments:  make(map[string][]byte, len(rb.Assignments)),
+	}
+	for _, a := range rb.Assignments {
+		sr.Assignments[a.MemberID] = a.Assignment
+	}
+	var result group.SyncResult
+	select {
+	case result = <-h.broker.Groups.Sync(sr):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	resp := &responses.SyncGroup{
+		Version:      rb.Version,
+		ErrorCode:    groupErrorCode(result.Err),
+		ProtocolType: result.ProtocolType,
+		ProtocolName: result.ProtocolName,
+		Assignment:   result.Assignment,
+	}
+	if resp.Assignment == nil {
+		resp.Assignment = []byte{}
+	}
+	return resp, nil
+}
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nabinkhanal00/kafka/app/log"
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/types"
+)
+
+// maxTopicNameLength leaves room for the partition suffix and the
+// "-delete" marker in the directory name.
+const maxTopicNameLength = 249
+
+var (
+	errInvalidTopic             = errors.New("invalid topic")
+	errTopicExists              = errors.New("topic already exists")
+	errInvalidPartitions        = errors.New("invalid number of partitions")
+	errInvalidReplicationFactor = errors.New("invalid replication factor")
+	errInvalidReplicaAssignment = errors.New("invalid replica assignment")
+	errTopicDeletionDisabled    = errors.New("topic deletion is disabled")
+	errUnknownTopicID           = errors.New("unknown topic id")
+	errUnknownTopic             = errors.New("unknown topic")
+	errReassignmentInProgress   = errors.New("reassignment in progress")
+)
+
+// validateTopicName applies upstream's naming rules: ASCII letters, digits,
+// '.', '_' and '-', at most 249 characters and not "." or "..".
+func validateTopicName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: topic name is empty", errInvalidTopic)
+	case name == "." || name == "..":
+		return fmt.Errorf("%w: topic name cannot be %q", errInvalidTopic, name)
+	case len(name) > maxTopicNameLength:
+		return fmt.Errorf("%w: topic name is longer than %d characters", errInvalidTopic, maxTopicNameLength)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("._-", c)) {
+			return fmt.Errorf("%w: topic name %q contains illegal character %q", errInvalidTopic, name, c)
+		}
+	}
+	return nil
+}
+
+// topicErrorCode maps the errors of the topic operations to protocol error codes.
+func topicErrorCode(err error) int16 {
+	switch {
+	case err == nil:
+		return NONE
+	case errors.Is(err, errInvalidTopic):
+		return INVALID_TOPIC_EXCEPTION
+	case errors.Is(err, errTopicExists):
+		return TOPIC_ALREADY_EXISTS
+	case errors.Is(err, errInvalidPartitions):
+		return INVALID_PARTITIONS
+	case errors.Is(err, errInvalidReplicationFactor):
+		return INVALID_REPLICATION_FACTOR
+	case errors.Is(err, errInvalidReplicaAssignment):
+		return INVALID_REPLICA_ASSIGNMENT
+	case errors.Is(err, errInvalidConfig):
+		return INVALID_CONFIG
+	case errors.Is(err, errInvalidRequest):
+		return INVALID_REQUEST
+	case errors.Is(err, errTopicDeletionDisabled):
+		return TOPIC_DELETION_DISABLED
+	case errors.Is(err, errUnknownTopicID):
+		return UNKNOWN_TOPIC_ID
+	case errors.Is(err, errUnknownTopic):
+		return UNKNOWN_TOPIC_OR_PARTITION
+	case errors.Is(err, errReassignmentInProgress):
+		return REASSIGNMENT_IN_PROGRESS
+	}
+	return UNKNOWN_SERVER_ERROR
+}
+
+// createTopic records a new topic in the metadata log with every replica
+// on this broker and opens its partition logs.
+func (b *Broker) createTopic(name string, partitions int32, replicationFactor int16) (metadata.Topic, error) {
+	assignments, err := b.assignReplicas(partitions, replicationFactor)
+	if err != nil {
+		return metadata.Topic{}, err
+	}
+	return b.writeTopic(name, assignments, nil, false)
+}
+
+// assignReplicas places the replicas of every partition. With a single
+// broker there is nothing to balance: each partition gets this node.
+func (b *Broker) assignReplicas(partitions int32, replicationFactor int16) ([][]int32, error) {
+	if partitions < 1 {
+		return nil, fmt.Errorf("%w: %d is below 1", errInvalidPartitions, partitions)
+	}
+	if replicationFactor < 1 {
+		return nil, fmt.Errorf("%w: %d is below 1", errInvalidReplicationFactor, replicationFactor)
+	}
+	if replicationFactor > 1 {
+		return nil, fmt.Errorf("%w: %d is larger than the 1 available broker", errInvalidReplicationFactor, replicationFactor)
+	}
+	assignments := make([][]int32, partitions)
+	for i := range assignments {
+		assignments[i] = []int32{b.Config.NodeID}
+	}
+	return assignments, nil
+}
+
+// validateAssignment checks the replicas of partitions first through
+// first+len(assignments)-1: each needs at least one replica, no broker twice
+// and only brokers of the cluster.
+func (b *Broker) validateAssignment(first int32, assignments [][]int32) error {
+	for i, replicas := range assignments {
+		partition := first + int32(i)
+		if len(replicas) == 0 {
+			return fmt.Errorf("%w: partition %d has no replicas", errInvalidReplicaAssignment, partition)
+		}
+		for j, id := range replicas {
+			if id != b.Config.NodeID {
+				return fmt.Errorf("%w: partition %d is assigned to unknown broker %d", errInvalidReplicaAssignment, partition, id)
+			}
+			if slices.Contains(replicas[:j], id) {
+				return fmt.Errorf("%w: partition %d lists broker %d twice", errInvalidReplicaAssignment, partition, id)
+			}
+		}
+	}
+	return nil
+}
+
+// writeTopic records a topic with the given replica assignment and config
+// overrides, then opens its partition logs. With validateOnly nothing is
+// written and the zero Topic is returned once every check has passed.
+func (b *Broker) writeTopic(name string, assignments [][]int32, configs map[string]string, validateOnly bool) (metadata.Topic, error) {
+	if err := validateTopicName(name); err != nil {
+		return metadata.Topic{}, err
+	}
+	if len(assignments) == 0 {
+		return metadata.Topic{}, fmt.Errorf("%w: 0 is below 1", errInvalidPartitions)
+	}
+	if err := b.validateAssignment(0, assignments); err != nil {
+		return metadata.Topic{}, err
+	}
+	if err := validateTopicConfigs(configs); err != nil {
+		return metadata.Topic{}, err
+	}
+	id := types.NewUUID()
+	err := b.Metadata.Update(func() ([]metadata.Record, error) {
+		if _, ok := b.Metadata.Topic(name); ok {
+			return nil, fmt.Errorf("%w: %s", errTopicExists, name)
+		}
+		if validateOnly {
+			return nil, nil
+		}
+		records := []metadata.Record{metadata.TopicRecord{Name: name, TopicID: id}}
+		for _, key := range slices.Sorted(maps.Keys(configs)) {
+			records = append(records, metadata.ConfigRecord{
+				ResourceType: metadata.TopicResource,
+				ResourceName: name,
+				Name:         key,
+				Value:        types.NullableStringOf(configs[key]),
+			})
+		}
+		for i, replicas := range assignments {
+			records = append(records, b.partitionRecord(id, int32(i), replicas))
+		}
+		return records, nil
+	})
+	if err != nil || validateOnly {
+		return metadata.Topic{}, err
+	}
+	b.invalidateTopicConfigs(name)
+	for i := range assignments {
+		l, err := b.Logs.GetOrCreate(log.TopicPartition{Topic: name, Partition: int32(i)})
+		if err != nil {
+			return metadata.Topic{}, err
+		}
+		if err := l.SetTopicID(id); err != nil {
+			return metadata.Topic{}, err
+		}
+	}
+	t, _ := b.Metadata.Topic(name)
+	return t, nil
+}
+
+// partitionRecord describes a new partition led by its first replica.
+func (b *Broker) partitionRecord(id types.UUID, partition int32, replicas []int32) metadata.PartitionRecord {
+	// Replicas are not placed on a specific directory: the zero id stands
+	// for unassigned.
+	var directories []types.UUID
+	if b.featureLevel(MetadataVersionFeature) >= metadataVersionDirectoryAssignment {
+		directories = make([]types.UUID, len(replicas))
+	}
+	return metadata.PartitionRecord{
+		PartitionID: partition,
+		TopicID:     id,
+		Replicas:    replicas,
+		ISR:         replicas,
+		Leader:      replicas[0],
+		Directories: directories,
+	}
+}
+
+// deleteTopic records the removal of a topic in the metadata log, which
+// also drops its configs, and then deletes its partition logs.
+func (b *Broker) deleteTopic(id types.UUID) error {
+	if !b.Config.DeleteTopicEnable {
+		return errTopicDeletionDisabled
+	}
+	var removed metadata.Topic
+	err := b.Metadata.Update(func() ([]metadata.Record, error) {
+		t, ok := b.Metadata.TopicByID(id)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownTopicID, id)
+		}
+		removed = t
+		return []metadata.Record{metadata.RemoveTopicRecord{TopicID: id}}, nil
+	})
+	if err != nil {
+		return err
+	}
+	b.invalidateTopicConfigs(removed.Name)
+	for _, p := range removed.Partitions {
+		err = errors.Join(err, b.Logs.Delete(log.TopicPartition{Topic: removed.Name, Partition: p.Index}))
+	}
+	return err
+}
+
+// createPartitions grows a topic to count partitions. The new partitions
+// take assignments when given and otherwise the replication factor of
+// the existing ones. Partitions being reassigned block the change.
+func (b *Broker) createPartitions(name string, count int32, assignments [][]int32, validateOnly bool) error {
+	var (
+		id    types.UUID
+		added [][]int32
+	)
+	err := b.Metadata.Update(func() ([]metadata.Record, error) {
+		t, ok := b.Metadata.Topic(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownTopic, name)
+		}
+		current := int32(len(t.Partitions))
+		if count <= current {
+			return nil, fmt.Errorf("%w: topic %s has %d partitions, which is not fewer than the requested %d",
+				errInvalidPartitions, name, current, count)
+		}
+		for _, p := range t.Partitions {
+			if len(p.AddingReplicas) > 0 || len(p.RemovingReplicas) > 0 {
+				return nil, fmt.Errorf("%w: partition %d of topic %s", errReassignmentInProgress, p.Index, name)
+			}
+		}
+		if assignments != nil {
+			if int32(len(assignments)) != count-current {
+				return nil, fmt.Errorf("%w: %d assignments given for %d new partitions",
+					errInvalidReplicaAssignment, len(assignments), count-current)
+			}
+			if err := b.validateAssignment(current, assignments); err != nil {
+				return nil, err
+			}
+			added = assignments
+		} else {
+			replicationFactor := int16(1)
+			if current > 0 {
+				replicationFactor = int16(len(t.Partitions[0].Replicas))
+			}
+			var err error
+			if added, err = b.assignReplicas(count-current, replicationFactor); err != nil {
+				return nil, err
+			}
+		}
+		if validateOnly {
+			return nil, nil
+		}
+		id = t.ID
+		records := make([]metadata.Record, 0, len(added))
+		for i, replicas := range added {
+			records = append(records, b.partitionRecord(id, current+int32(i), replicas))
+		}
+		return records, nil
+	})
+	if err != nil || validateOnly {
+		return err
+	}
+	for i := count - int32(len(added)); i < count; i++ {
+		l, err := b.Logs.GetOrCreate(log.TopicPartition{Topic: name, Partition: i})
+		if err != nil {
+			return err
+		}
+		if err := l.SetTopicID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,400 @@
+package types
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// UUID is the 16 byte identifier used for topic ids and the like.
+type UUID [16]byte
+
+// String renders the id the way upstream does: URL-safe base64, no padding.
+func (u UUID) String() string {
+	return base64.RawURLEncoding.EncodeToString(u[:])
+}
+
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// NewUUID returns a random version 4 id. Like upstream it never returns
+// the zero id or one whose string form starts with a dash, which would
+// read as a command line flag.
+func NewUUID() UUID {
+	for {
+		var u UUID
+		rand.Read(u[:])
+		u[6] = u[6]&0x0f | 0x40
+		u[8] = u[8]&0x3f | 0x80
+		if !u.IsZero() && u.String()[0] != '-' {
+			return u
+		}
+	}
+}
+
+func ParseUUIDString(s string) (UUID, error) {
+	var u UUID
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return u, fmt.Errorf("invalid uuid %q: %w", s, err)
+	}
+	if len(b) != len(u) {
+		return u, fmt.Errorf("invalid uuid %q: expected 16 bytes, got %d", s, len(b))
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+var errNegativeLength = errors.New("negative length")
+
+// Decoder reads protocol fields from a message body. Flexible selects the
+// compact encodings that flexible versions use for strings, bytes and
+// arrays, and enables tagged field sections. The first error sticks and
+// every later read returns a zero value, so a whole structure can be read
+// before checking Err once.
+type Decoder struct {
+	r        *bytes.Reader
+	Flexible bool
+	err      error
+}
+
+func NewDecoder(r *bytes.Reader, flexible bool) *Decoder {
+	return &Decoder{r: r, Flexible: flexible}
+}
+
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Remaining is the number of unread bytes.
+func (d *Decoder) Remaining() int {
+	return d.r.Len()
+}
+
+func (d *Decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *Decoder) read(v any) {
+	if d.err != nil {
+		return
+	}
+	if err := binary.Read(d.r, binary.BigEndian, v); err != nil {
+		d.fail(err)
+	}
+}
+
+func (d *Decoder) Int8() (v int8) {
+	d.read(&v)
+	return
+}
+
+func (d *Decoder) Int16() (v int16) {
+	d.read(&v)
+	return
+}
+
+func (d *Decoder) Int32() (v int32) {
+	d.read(&v)
+	return
+}
+
+func (d *Decoder) Int64() (v int64) {
+	d.read(&v)
+	return
+}
+
+func (d *Decoder) Uint16() (v uint16) {
+	d.read(&v)
+	return
+}
+
+func (d *Decoder) Uint32() (v uint32) {
+	d.read(&v)
+	return
+}
+
+func (d *Decoder) Float64() float64 {
+	var bits uint64
+	d.read(&bits)
+	return math.Float64frombits(bits)
+}
+
+func (d *Decoder) Bool() bool {
+	return d.Int8() != 0
+}
+
+func (d *Decoder) UUID() (u UUID) {
+	d.read(&u)
+	return
+}
+
+func (d *Decoder) Uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.fail(err)
+	}
+	return v
+}
+
+func (d *Decoder) Varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.fail(err)
+	}
+	return v
+}
+
+// length reads a nullable length: int16 or int32 in classic encodings,
+// unsigned varint plus one in compact ones. Null is reported as -1.
+func (d *Decoder) length(wide bool) int {
+	if d.Flexible {
+		return int(d.Uvarint()) - 1
+	}
+	if wide {
+		return int(d.Int32())
+	}
+	return int(d.Int16())
+}
+
+func (d *Decoder) raw(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > d.r.Len() {
+		d.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.fail(err)
+		return nil
+	}
+	return b
+}
+
+// String reads a STRING or COMPACT_STRING.
+func (d *Decoder) String() string {
+	n := d.length(false)
+	if n < 0 {
+		d.fail(fmt.Errorf("non-nullable string: %w", errNegativeLength))
+		return ""
+	}
+	return string(d.raw(n))
+}
+
+// NullableString reads a NULLABLE_STRING or COMPACT_NULLABLE_STRING.
+func (d *Decoder) NullableString() *string {
+	n := d.length(false)
+	if n < 0 || d.err != nil {
+		return nil
+	}
+	s := string(d.raw(n))
+	return &s
+}
+
+// Bytes reads a BYTES or COMPACT_BYTES field.
+func (d *Decoder) Bytes() []byte {
+	n := d.length(true)
+	if n < 0 {
+		d.fail(fmt.Errorf("non-nullable bytes: %w", errNegativeLength))
+		return nil
+	}
+	return d.raw(n)
+}
+
+// NullableBytes reads NULLABLE_BYTES, RECORDS or their compact forms.
+// Null is returned as a nil slice.
+func (d *Decoder) NullableBytes() []byte {
+	n := d.length(true)
+	if n < 0 {
+		return nil
+	}
+	b := d.raw(n)
+	if b == nil {
+		b = []byte{}
+	}
+	return b
+}
+
+// ArrayLength reads the element count of an ARRAY or COMPACT_ARRAY, -1
+// meaning null.
+func (d *Decoder) ArrayLength() int {
+	n := d.length(true)
+	if n > d.r.Len() {
+		// Every element occupies at least a byte.
+		d.fail(fmt.Errorf("array length %d exceeds remaining %d bytes", n, d.r.Len()))
+		return 0
+	}
+	return n
+}
+
+// TaggedFields reads the tagged field section of flexible versions and
+// returns an empty set otherwise.
+func (d *Decoder) TaggedFields() TaggedFields {
+	if !d.Flexible || d.err != nil {
+		return TaggedFields{}
+	}
+	tfs, err := ParseTaggedFields(d.r)
+	if err != nil {
+		d.fail(err)
+		return TaggedFields{}
+	}
+	return *tfs
+}
+
+// DecodeArray reads an array whose elements are decoded by f.
+func DecodeArray[T any](d *Decoder, f func(*Decoder) T) []T {
+	n := d.ArrayLength()
+	if n < 0 || d.err != nil {
+		return nil
+	}
+	items := make([]T, 0, n)
+	for range n {
+		items = append(items, f(d))
+		if d.err != nil {
+			return nil
+		}
+	}
+	return items
+}
+
+// Encoder is the writing counterpart of Decoder.
+type Encoder struct {
+	w        io.Writer
+	Flexible bool
+	err      error
+}
+
+func NewEncoder(w io.Writer, flexible bool) *Encoder {
+	return &Encoder{w: w, Flexible: flexible}
+}
+
+func (e *Encoder) Err() error {
+	return e.err
+}
+
+func (e *Encoder) write(v any) {
+	if e.err != nil {
+		return
+	}
+	e.err = binary.Write(e.w, binary.BigEndian, v)
+}
+
+func (e *Encoder) Raw(b []byte) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(b)
+}
+
+func (e *Encoder) Int8(v int8)     { e.write(v) }
+func (e *Encoder) Int16(v int16)   { e.write(v) }
+func (e *Encoder) Int32(v int32)   { e.write(v) }
+func (e *Encoder) Int64(v int64)   { e.write(v) }
+func (e *Encoder) Uint16(v uint16) { e.write(v) }
+func (e *Encoder) Uint32(v uint32) { e.write(v) }
+func (e *Encoder) UUID(u UUID)     { e.write(u) }
+
+func (e *Encoder) Float64(v float64) {
+	e.write(math.Float64bits(v))
+}
+
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.Int8(1)
+	} else {
+		e.Int8(0)
+	}
+}
+
+func (e *Encoder) Uvarint(v uint64) {
+	if e.err != nil {
+		return
+	}
+	e.err = WriteUvarint(e.w, v)
+}
+
+func (e *Encoder) Varint(v int64) {
+	if e.err != nil {
+		return
+	}
+	e.err = WriteVarint(e.w, v)
+}
+
+func (e *Encoder) length(n int, wide bool) {
+	switch {
+	case e.Flexible:
+		e.Uvarint(uint64(n + 1))
+	case wide:
+		e.Int32(int32(n))
+	default:
+		e.Int16(int16(n))
+	}
+}
+
+func (e *Encoder) String(s string) {
+	e.length(len(s), false)
+	e.Raw([]byte(s))
+}
+
+func (e *Encoder) NullableString(s *string) {
+	if s == nil {
+		e.length(-1, false)
+		return
+	}
+	e.String(*s)
+}
+
+func (e *Encoder) Bytes(b []byte) {
+	e.length(len(b), true)
+	e.Raw(b)
+}
+
+// NullableBytes writes b, encoding a nil slice as null.
+func (e *Encoder) NullableBytes(b []byte) {
+	if b == nil {
+		e.length(-1, true)
+		return
+	}
+	e.Bytes(b)
+}
+
+// ArrayLength writes an element count, -1 meaning null.
+func (e *Encoder) ArrayLength(n int) {
+	e.length(n, true)
+}
+
+func (e *Encoder) TaggedFields(tfs TaggedFields) {
+	if !e.Flexible || e.err != nil {
+		return
+	}
+	e.err = tfs.Write(e.w)
+}
+
+// EncodeArray writes items using f for every element.
+func EncodeArray[T any](e *Encoder, items []T, f func(*Encoder, T)) {
+	e.ArrayLength(len(items))
+	for _, item := range items {
+		f(e, item)
+	}
+}
+
+// NullableStringOf is a small helper for optional string fields.
+func NullableStringOf(s string) *string {
+	return &s
+}
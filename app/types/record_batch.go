@@ -0,0 +1,379 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/nabinkhanal00/kafka/app/compression"
+)
+
+// Attribute bits of a v2 record batch.
+const (
+	CompressionCodecMask   int16 = 0x07
+	TimestampTypeMask      int16 = 0x08
+	TransactionalFlagMask  int16 = 0x10
+	ControlFlagMask        int16 = 0x20
+	DeleteHorizonFlagMask  int16 = 0x40
+	RecordBatchMagic       int8  = 2
+	RecordBatchOverhead          = 61
+	recordBatchLengthStart       = 12
+	recordBatchCRCStart          = 21
+)
+
+// Control record types, stored in the key of control batches.
+const (
+	ControlTypeAbort  int16 = 0
+	ControlTypeCommit int16 = 1
+)
+
+var (
+	ErrInvalidRecordBatch     = errors.New("invalid record batch")
+	ErrRecordBatchCRC         = errors.New("record batch crc mismatch")
+	ErrUnsupportedCompression = errors.New("unsupported compression codec")
+)
+
+// MaxDecompressedSize bounds the records of a compressed batch once
+// decompressed, so that a batch of a few KiB cannot expand into
+// gigabytes. The broker sets it to socket.request.max.bytes: no batch may
+// grow beyond what a client could have sent uncompressed.
+var MaxDecompressedSize = 100 * 1024 * 1024
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// RecordHeader is a single key/value header of a record.
+type RecordHeader struct {
+	Key   string `desc:"headerKey"`
+	Value []byte `desc:"headerValue"`
+}
+
+// Record is one entry of a v2 batch. Key and Value are nil when null.
+type Record struct {
+	Attributes     int8           `desc:"attributes"`
+	TimestampDelta int64          `desc:"timestampDelta"`
+	OffsetDelta    int32          `desc:"offsetDelta"`
+	Key            []byte         `desc:"key"`
+	Value          []byte         `desc:"value"`
+	Headers        []RecordHeader `desc:"headers"`
+}
+
+// RecordBatch is the magic v2 on-disk and wire format.
+type RecordBatch struct {
+	BaseOffset           int64    `desc:"baseOffset"`
+	BatchLength          int32    `desc:"batchLength"`
+	PartitionLeaderEpoch int32    `desc:"partitionLeaderEpoch"`
+	Magic                int8     `desc:"magic"`
+	CRC                  uint32   `desc:"crc"`
+	Attributes           int16    `desc:"attributes"`
+	LastOffsetDelta      int32    `desc:"lastOffsetDelta"`
+	BaseTimestamp        int64    `desc:"baseTimestamp"`
+	MaxTimestamp         int64    `desc:"maxTimestamp"`
+	ProducerID           int64    `desc:"producerId"`
+	ProducerEpoch        int16    `desc:"producerEpoch"`
+	BaseSequence         int32    `desc:"baseSequence"`
+	Records              []Record `desc:"records"`
+}
+
+func (b *RecordBatch) Compression() int16 {
+	return b.Attributes & CompressionCodecMask
+}
+
+// IsLogAppendTime reports whether MaxTimestamp was set by the broker.
+func (b *RecordBatch) IsLogAppendTime() bool {
+	return b.Attributes&TimestampTypeMask != 0
+}
+
+func (b *RecordBatch) IsTransactional() bool {
+	return b.Attributes&TransactionalFlagMask != 0
+}
+
+func (b *RecordBatch) IsControl() bool {
+	return b.Attributes&ControlFlagMask != 0
+}
+
+func (b *RecordBatch) LastOffset() int64 {
+	return b.BaseOffset + int64(b.LastOffsetDelta)
+}
+
+// Offset returns the absolute offset of a record of the batch.
+func (b *RecordBatch) Offset(r Record) int64 {
+	return b.BaseOffset + int64(r.OffsetDelta)
+}
+
+// Timestamp returns the absolute timestamp of a record of the batch.
+func (b *RecordBatch) Timestamp(r Record) int64 {
+	if b.IsLogAppendTime() {
+		return b.MaxTimestamp
+	}
+	return b.BaseTimestamp + r.TimestampDelta
+}
+
+// ControlType decodes the key of a control record: a version followed by
+// the control type.
+func ControlType(r Record) (int16, error) {
+	if len(r.Key) < 4 {
+		return 0, fmt.Errorf("%w: control record key too short", ErrInvalidRecordBatch)
+	}
+	return int16(binary.BigEndian.Uint16(r.Key[2:])), nil
+}
+
+// ParseRecordBatch reads one batch from r and verifies its CRC-32C.
+func ParseRecordBatch(r *bytes.Reader) (*RecordBatch, error) {
+	var header [recordBatchLengthStart]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := int32(binary.BigEndian.Uint32(header[8:]))
+	if length < RecordBatchOverhead-recordBatchLengthStart || int64(length) > int64(r.Len()) {
+		return nil, fmt.Errorf("%w: batch length %d with %d bytes available", ErrInvalidRecordBatch, length, r.Len())
+	}
+	data := make([]byte, recordBatchLengthStart+int(length))
+	copy(data, header[:])
+	if _, err := io.ReadFull(r, data[recordBatchLengthStart:]); err != nil {
+		return nil, err
+	}
+	return DecodeRecordBatch(data)
+}
+
+// DecodeRecordBatch decodes exactly one batch held in data.
+func DecodeRecordBatch(data []byte) (*RecordBatch, error) {
+	if len(data) < RecordBatchOverhead {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the batch header", ErrInvalidRecordBatch, len(data))
+	}
+	d := NewDecoder(bytes.NewReader(data), false)
+	b := &RecordBatch{}
+	b.BaseOffset = d.Int64()
+	b.BatchLength = d.Int32()
+	b.PartitionLeaderEpoch = d.Int32()
+	b.Magic = d.Int8()
+	b.CRC = d.Uint32()
+	b.Attributes = d.Int16()
+	b.LastOffsetDelta = d.Int32()
+	b.BaseTimestamp = d.Int64()
+	b.MaxTimestamp = d.Int64()
+	b.ProducerID = d.Int64()
+	b.ProducerEpoch = d.Int16()
+	b.BaseSequence = d.Int32()
+	count := d.Int32()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if b.Magic != RecordBatchMagic {
+		return nil, fmt.Errorf("%w: unsupported magic %d", ErrInvalidRecordBatch, b.Magic)
+	}
+	if int(b.BatchLength)+recordBatchLengthStart != len(data) {
+		return nil, fmt.Errorf("%w: batch length %d does not match %d bytes", ErrInvalidRecordBatch, b.BatchLength, len(data))
+	}
+	if crc := crc32.Checksum(data[recordBatchCRCStart:], castagnoli); crc != b.CRC {
+		return nil, fmt.Errorf("%w: expected %08x, got %08x", ErrRecordBatchCRC, b.CRC, crc)
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("%w: negative record count %d", ErrInvalidRecordBatch, count)
+	}
+
+	codec := compression.Codec(b.Compression())
+	if !codec.Valid() {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedCompression, codec)
+	}
+	records, err := compression.Decompress(codec, data[RecordBatchOverhead:], MaxDecompressedSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s records: %w", ErrInvalidRecordBatch, codec, err)
+	}
+	rd := NewDecoder(bytes.NewReader(records), false)
+	if int64(count) > int64(len(records)) {
+		return nil, fmt.Errorf("%w: record count %d exceeds records size", ErrInvalidRecordBatch, count)
+	}
+	b.Records = make([]Record, 0, count)
+	for range count {
+		rec, err := decodeRecord(rd)
+		if err != nil {
+			return nil, err
+		}
+		b.Records = append(b.Records, rec)
+	}
+	if rd.Remaining() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes after %d records", ErrInvalidRecordBatch, rd.Remaining(), count)
+	}
+	return b, nil
+}
+
+// varBytes reads a varint length prefixed byte slice, -1 meaning null.
+func varBytes(d *Decoder) []byte {
+	n := d.Varint()
+	if n < 0 || d.Err() != nil {
+		return nil
+	}
+	if n > int64(d.Remaining()) {
+		d.fail(fmt.Errorf("%w: field length %d exceeds record", ErrInvalidRecordBatch, n))
+		return nil
+	}
+	b := d.raw(int(n))
+	if b == nil {
+		b = []byte{}
+	}
+	return b
+}
+
+func decodeRecord(d *Decoder) (Record, error) {
+	var rec Record
+	length := d.Varint()
+	if err := d.Err(); err != nil {
+		return rec, err
+	}
+	if length < 0 || length > int64(d.Remaining()) {
+		return rec, fmt.Errorf("%w: record length %d", ErrInvalidRecordBatch, length)
+	}
+	body := NewDecoder(bytes.NewReader(d.raw(int(length))), false)
+	rec.Attributes = body.Int8()
+	rec.TimestampDelta = body.Varint()
+	rec.OffsetDelta = int32(body.Varint())
+	rec.Key = varBytes(body)
+	rec.Value = varBytes(body)
+	headerCount := body.Varint()
+	if headerCount < 0 || headerCount > int64(body.Remaining()) {
+		return rec, fmt.Errorf("%w: header count %d", ErrInvalidRecordBatch, headerCount)
+	}
+	for range headerCount {
+		key := varBytes(body)
+		if key == nil {
+			return rec, fmt.Errorf("%w: null header key", ErrInvalidRecordBatch)
+		}
+		rec.Headers = append(rec.Headers, RecordHeader{Key: string(key), Value: varBytes(body)})
+	}
+	if err := body.Err(); err != nil {
+		return rec, fmt.Errorf("%w: %v", ErrInvalidRecordBatch, err)
+	}
+	if body.Remaining() != 0 {
+		return rec, fmt.Errorf("%w: %d trailing bytes in record", ErrInvalidRecordBatch, body.Remaining())
+	}
+	return rec, nil
+}
+
+// ParseRecordBatches decodes every batch of a record set.
+func ParseRecordBatches(data []byte) ([]*RecordBatch, error) {
+	r := bytes.NewReader(data)
+	var batches []*RecordBatch
+	for r.Len() > 0 {
+		b, err := ParseRecordBatch(r)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, nil
+}
+
+func writeVarBytes(e *Encoder, b []byte) {
+	if b == nil {
+		e.Varint(-1)
+		return
+	}
+	e.Varint(int64(len(b)))
+	e.Raw(b)
+}
+
+func encodeRecord(e *Encoder, rec Record) {
+	var body bytes.Buffer
+	be := NewEncoder(&body, false)
+	be.Int8(rec.Attributes)
+	be.Varint(rec.TimestampDelta)
+	be.Varint(int64(rec.OffsetDelta))
+	writeVarBytes(be, rec.Key)
+	writeVarBytes(be, rec.Value)
+	be.Varint(int64(len(rec.Headers)))
+	for _, h := range rec.Headers {
+		writeVarBytes(be, []byte(h.Key))
+		writeVarBytes(be, h.Value)
+	}
+	e.Varint(int64(body.Len()))
+	e.Raw(body.Bytes())
+}
+
+// Encode serializes the batch, compressing the records with the codec
+// selected by Attributes and filling in BatchLength and CRC.
+func (b *RecordBatch) Encode() ([]byte, error) {
+	var records bytes.Buffer
+	re := NewEncoder(&records, false)
+	for _, rec := range b.Records {
+		encodeRecord(re, rec)
+	}
+	if err := re.Err(); err != nil {
+		return nil, err
+	}
+	codec := compression.Codec(b.Compression())
+	if !codec.Valid() {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedCompression, codec)
+	}
+	payload, err := compression.Compress(codec, records.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, false)
+	e.Int64(b.BaseOffset)
+	e.Int32(int32(RecordBatchOverhead - recordBatchLengthStart + len(payload)))
+	e.Int32(b.PartitionLeaderEpoch)
+	e.Int8(RecordBatchMagic)
+	e.Uint32(0)
+	e.Int16(b.Attributes)
+	e.Int32(b.LastOffsetDelta)
+	e.Int64(b.BaseTimestamp)
+	e.Int64(b.MaxTimestamp)
+	e.Int64(b.ProducerID)
+	e.Int16(b.ProducerEpoch)
+	e.Int32(b.BaseSequence)
+	e.Int32(int32(len(b.Records)))
+	e.Raw(payload)
+	if err := e.Err(); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	b.Magic = RecordBatchMagic
+	b.BatchLength = int32(len(data) - recordBatchLengthStart)
+	b.CRC = crc32.Checksum(data[recordBatchCRCStart:], castagnoli)
+	binary.BigEndian.PutUint32(data[17:], b.CRC)
+	return data, nil
+}
+
+func (b *RecordBatch) Write(w io.Writer) error {
+	data, err := b.Encode()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// NewRecordBatch builds an uncompressed batch for records, deriving the
+// offset and timestamp deltas from their position and timestamps.
+func NewRecordBatch(baseOffset int64, timestamps []int64, records []Record) *RecordBatch {
+	b := &RecordBatch{
+		BaseOffset:    baseOffset,
+		Magic:         RecordBatchMagic,
+		ProducerID:    -1,
+		ProducerEpoch: -1,
+		BaseSequence:  -1,
+		BaseTimestamp: -1,
+		MaxTimestamp:  -1,
+	}
+	if len(timestamps) > 0 {
+		b.BaseTimestamp = timestamps[0]
+		b.MaxTimestamp = timestamps[0]
+	}
+	for i := range records {
+		records[i].OffsetDelta = int32(i)
+		if i < len(timestamps) {
+			records[i].TimestampDelta = timestamps[i] - b.BaseTimestamp
+			b.MaxTimestamp = max(b.MaxTimestamp, timestamps[i])
+		}
+	}
+	b.Records = records
+	if len(records) > 0 {
+		b.LastOffsetDelta = int32(len(records) - 1)
+	}
+	return b
+}
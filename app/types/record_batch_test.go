@@ -0,0 +1,216 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"reflect"
+	"testing"
+)
+
+func TestRecordBatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		batch RecordBatch
+	}{
+		{
+			name: "plain",
+			batch: RecordBatch{
+				BaseOffset:           42,
+				PartitionLeaderEpoch: 3,
+				LastOffsetDelta:      1,
+				BaseTimestamp:        1000,
+				MaxTimestamp:         1005,
+				ProducerID:           -1,
+				ProducerEpoch:        -1,
+				BaseSequence:         -1,
+				Records: []Record{
+					{Key: []byte("k1"), Value: []byte("v1")},
+					{TimestampDelta: 5, OffsetDelta: 1, Key: []byte("k2"), Value: []byte("v2")},
+				},
+			},
+		},
+		{
+			name: "null key and value",
+			batch: RecordBatch{
+				LastOffsetDelta: 2,
+				ProducerID:      -1,
+				ProducerEpoch:   -1,
+				BaseSequence:    -1,
+				Records: []Record{
+					{Value: []byte("no key")},
+					{OffsetDelta: 1, Key: []byte("tombstone")},
+					{OffsetDelta: 2, Key: []byte{}, Value: []byte{}},
+				},
+			},
+		},
+		{
+			name: "headers",
+			batch: RecordBatch{
+				ProducerID:    -1,
+				ProducerEpoch: -1,
+				BaseSequence:  -1,
+				Records: []Record{{
+					Key:   []byte("k"),
+					Value: []byte("v"),
+					Headers: []RecordHeader{
+						{Key: "trace", Value: []byte("abc")},
+						{Key: "null", Value: nil},
+						{Key: "", Value: []byte{}},
+					},
+				}},
+			},
+		},
+		{
+			name: "transactional control",
+			batch: RecordBatch{
+				Attributes:    TransactionalFlagMask | ControlFlagMask,
+				ProducerID:    7,
+				ProducerEpoch: 2,
+				BaseSequence:  -1,
+				Records:       []Record{{Key: []byte{0, 0, 0, byte(ControlTypeCommit)}, Value: []byte{0, 0, 0, 0, 0, 0}}},
+			},
+		},
+		{
+			name: "log append time",
+			batch: RecordBatch{
+				Attributes:    TimestampTypeMask,
+				BaseTimestamp: 10,
+				MaxTimestamp:  99,
+				ProducerID:    -1,
+				ProducerEpoch: -1,
+				BaseSequence:  -1,
+				Records:       []Record{{Value: []byte("x")}},
+			},
+		},
+		{
+			name: "gzip",
+			batch: RecordBatch{
+				Attributes:      1,
+				LastOffsetDelta: 1,
+				ProducerID:      -1,
+				ProducerEpoch:   -1,
+				BaseSequence:    -1,
+				Records: []Record{
+					{Key: []byte("k"), Value: bytes.Repeat([]byte("a"), 1000)},
+					{OffsetDelta: 1, Value: bytes.Repeat([]byte("b"), 1000)},
+				},
+			},
+		},
+		{
+			name: "zstd",
+			batch: RecordBatch{
+				Attributes:    4,
+				ProducerID:    -1,
+				ProducerEpoch: -1,
+				BaseSequence:  -1,
+				Records:       []Record{{Value: []byte("compressed")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.batch
+			data, err := want.Encode()
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			got, err := DecodeRecordBatch(data)
+			if err != nil {
+				t.Fatalf("DecodeRecordBatch: %v", err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Fatalf("round trip mismatch:\n got  %+v\n want %+v", *got, want)
+			}
+			if got.IsTransactional() != (want.Attributes&TransactionalFlagMask != 0) ||
+				got.IsControl() != (want.Attributes&ControlFlagMask != 0) ||
+				got.IsLogAppendTime() != (want.Attributes&TimestampTypeMask != 0) {
+				t.Fatalf("attribute bits of %#x decoded wrongly", got.Attributes)
+			}
+		})
+	}
+}
+
+func TestRecordBatchTimestamps(t *testing.T) {
+	b := NewRecordBatch(10, []int64{100, 90, 130}, []Record{{}, {}, {}})
+	if b.BaseTimestamp != 100 || b.MaxTimestamp != 130 || b.LastOffsetDelta != 2 {
+		t.Fatalf("NewRecordBatch: base %d max %d last delta %d", b.BaseTimestamp, b.MaxTimestamp, b.LastOffsetDelta)
+	}
+	if ts := b.Timestamp(b.Records[1]); ts != 90 {
+		t.Fatalf("Timestamp = %d, want 90", ts)
+	}
+	if off := b.Offset(b.Records[2]); off != 12 || b.LastOffset() != 12 {
+		t.Fatalf("Offset = %d, LastOffset = %d, want 12", off, b.LastOffset())
+	}
+	b.Attributes |= TimestampTypeMask
+	if ts := b.Timestamp(b.Records[1]); ts != 130 {
+		t.Fatalf("Timestamp with log append time = %d, want 130", ts)
+	}
+}
+
+func encodedBatch(t *testing.T) []byte {
+	t.Helper()
+	b := NewRecordBatch(0, []int64{1}, []Record{{Key: []byte("k"), Value: []byte("v")}})
+	data, err := b.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return data
+}
+
+func TestDecodeRecordBatchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func([]byte) []byte
+		want   error
+	}{
+		{"crc mismatch", func(d []byte) []byte { d[len(d)-1] ^= 1; return d }, ErrRecordBatchCRC},
+		{"bad crc field", func(d []byte) []byte { d[recordBatchCRCStart-1] ^= 1; return d }, ErrRecordBatchCRC},
+		{"truncated", func(d []byte) []byte { return d[:RecordBatchOverhead-1] }, ErrInvalidRecordBatch},
+		{"length mismatch", func(d []byte) []byte { return append(d, 0) }, ErrInvalidRecordBatch},
+		{"magic", func(d []byte) []byte { d[16] = 1; return d }, ErrInvalidRecordBatch},
+		{"codec", func(d []byte) []byte { return withAttributes(d, 7) }, ErrUnsupportedCompression},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeRecordBatch(tt.mutate(encodedBatch(t)))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// withAttributes rewrites the attributes of an encoded batch, keeping its
+// CRC valid.
+func withAttributes(data []byte, attributes int16) []byte {
+	binary.BigEndian.PutUint16(data[recordBatchCRCStart:], uint16(attributes))
+	binary.BigEndian.PutUint32(data[17:], crc32.Checksum(data[recordBatchCRCStart:], castagnoli))
+	return data
+}
+
+func TestDecodeRecordBatchTooLarge(t *testing.T) {
+	b := NewRecordBatch(0, []int64{1}, []Record{{Value: make([]byte, 1<<16)}})
+	b.Attributes = 1
+	data, err := b.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	defer func(limit int) { MaxDecompressedSize = limit }(MaxDecompressedSize)
+	MaxDecompressedSize = 1 << 10
+	if _, err := DecodeRecordBatch(data); !errors.Is(err, ErrInvalidRecordBatch) {
+		t.Fatalf("got %v, want ErrInvalidRecordBatch", err)
+	}
+}
+
+func TestParseRecordBatches(t *testing.T) {
+	first, second := encodedBatch(t), encodedBatch(t)
+	batches, err := ParseRecordBatches(append(first, second...))
+	if err != nil || len(batches) != 2 {
+		t.Fatalf("ParseRecordBatches = %d batches, %v", len(batches), err)
+	}
+	if _, err := ParseRecordBatches(append(first, second[:20]...)); !errors.Is(err, ErrInvalidRecordBatch) {
+		t.Fatalf("trailing partial batch: got %v, want ErrInvalidRecordBatch", err)
+	}
+}
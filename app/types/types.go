@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"slices"
 )
 
 type NullableString string
@@ -45,8 +46,14 @@ func ParseCompactString(r *bytes.Reader) (*CompactString, error) {
 	if length, err = binary.ReadUvarint(r); err != nil {
 		return nil, fmt.Errorf("unable to read compact string length: %w", err)
 	}
+	if length == 0 {
+		return nil, fmt.Errorf("non-nullable compact string: %w", errNegativeLength)
+	}
 	var cs CompactString
 	length -= 1
+	if length > uint64(r.Len()) {
+		return nil, fmt.Errorf("unable to read compact string data: %w", io.ErrUnexpectedEOF)
+	}
 	if length != 0 {
 		characters := make([]byte, length)
 		if n, err := io.ReadFull(r, characters); err != nil {
@@ -64,6 +71,12 @@ func ParseNullableString(r *bytes.Reader) (*NullableString, error) {
 	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
 		return nil, fmt.Errorf("unable to read nullable string length: %w", err)
 	}
+	if length < -1 {
+		return nil, fmt.Errorf("nullable string length %d: %w", length, errNegativeLength)
+	}
+	if int(length) > r.Len() {
+		return nil, fmt.Errorf("unable to read nullable string data: %w", io.ErrUnexpectedEOF)
+	}
 	var ns NullableString
 	if length != -1 {
 		characters := make([]byte, length)
@@ -78,7 +91,7 @@ func ParseNullableString(r *bytes.Reader) (*NullableString, error) {
 }
 
 func (ns *NullableString) Write(w io.Writer) error {
-	if err := binary.Write(w, binary.BigEndian, int32(len(*ns))); err != nil {
+	if err := binary.Write(w, binary.BigEndian, int16(len(*ns))); err != nil {
 		return err
 	}
 	if len(*ns) > 0 {
@@ -142,6 +155,9 @@ func ParseTaggedFields(r *bytes.Reader) (*TaggedFields, error) {
 			return nil, fmt.Errorf("error reading tag length: %w", err)
 		}
 
+		if length > uint64(r.Len()) {
+			return nil, fmt.Errorf("error reading tag value: %w", io.ErrUnexpectedEOF)
+		}
 		value := make([]byte, length)
 		if _, err := io.ReadFull(r, value); err != nil {
 			return nil, fmt.Errorf("error reading tag value: %w", err)
@@ -162,7 +178,14 @@ func (t *TaggedFields) Write(w io.Writer) error {
 		return err
 	}
 
-	for tagID, value := range t.Fields {
+	// Tags must be written in ascending order.
+	tagIDs := make([]uint64, 0, len(t.Fields))
+	for tagID := range t.Fields {
+		tagIDs = append(tagIDs, tagID)
+	}
+	slices.Sort(tagIDs)
+	for _, tagID := range tagIDs {
+		value := t.Fields[tagID]
 		if err := WriteUvarint(buf, tagID); err != nil {
 			return err
 		}
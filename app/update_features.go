@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nabinkhanal00/kafka/app/metadata"
+	"github.com/nabinkhanal00/kafka/app/requests"
+	"github.com/nabinkhanal00/kafka/app/responses"
+)
+
+type UpdateFeaturesHandler struct {
+	FlexibleSince
+	broker *Broker
+}
+
+func NewUpdateFeaturesHandler(broker *Broker) *UpdateFeaturesHandler {
+	return &UpdateFeaturesHandler{FlexibleSince: 0, broker: broker}
+}
+
+func (h *UpdateFeaturesHandler) ParseRequest(version int16, r *bytes.Reader) (RequestBody, error) {
+	return requests.ParseUpdateFeatures(r, version)
+}
+
+// Handle applies the updates atomically: either every update is valid
+// and written to the metadata log as FeatureLevelRecords, or nothing is
+// and the first failure is reported for the whole request.
+func (h *UpdateFeaturesHandler) Handle(ctx context.Context, req *Request) (ResponseBody, error) {
+	rb, ok := req.Body.(*requests.UpdateFeatures)
+	if !ok {
+		return nil, fmt.Errorf("invalid request body type %T", req.Body)
+	}
+	resp := &responses.UpdateFeatures{Version: rb.Version, Results: []responses.UpdatableFeatureResult{}}
+	fail := func(code int16, message string) *responses.UpdateFeatures {
+		resp.ErrorCode, resp.ErrorMessage = code, &message
+		for i := range resp.Results {
+			resp.Results[i].ErrorCode, resp.Results[i].ErrorMessage = code, &message
+		}
+		return resp
+	}
+	seen := make(map[string]bool, len(rb.FeatureUpdates))
+	for _, u := range rb.FeatureUpdates {
+		resp.Results = append(resp.Results, responses.UpdatableFeatureResult{Feature: u.Feature, ErrorCode: NONE})
+		if u.Feature == "" || seen[u.Feature] {
+			return fail(INVALID_REQUEST, fmt.Sprintf("feature %q is empty or updated more than once", u.Feature)), nil
+		}
+		seen[u.Feature] = true
+	}
+
+	var invalid error
+	err := h.broker.Metadata.Update(func() ([]metadata.Record, error) {
+		var records []metadata.Record
+		for _, u := range rb.FeatureUpdates {
+			current := h.broker.Metadata.FeatureLevel(u.Feature)
+			if invalid = validateFeatureUpdate(u, current); invalid != nil {
+				return nil, nil
+			}
+			if u.MaxVersionLevel != current {
+				records = append(records, metadata.FeatureLevelRecord{Name: u.Feature, FeatureLevel: u.MaxVersionLevel})
+			}
+		}
+		if rb.ValidateOnly {
+			return nil, nil
+		}
+		return records, nil
+	})
+	switch {
+	case invalid != nil:
+		return fail(INVALID_UPDATE_VERSION, invalid.Error()), nil
+	case err != nil:
+		return fail(FEATURE_UPDATE_FAILED, err.Error()), nil
+	}
+	return resp, nil
+}
+
+// validateFeatureUpdate checks an update against the supported range and
+// the current level. Level 0 removes a feature, which metadata.version
+// does not allow; neither does it allow downgrades, since records written
+// at the newer level may not be readable at the older one.
+func validateFeatureUpdate(u requests.FeatureUpdate, current int16) error {
+	supported, ok := supportedFeatures[u.Feature]
+	switch {
+	case !ok:
+		return fmt.Errorf("the broker does not support feature %s", u.Feature)
+	case u.UpgradeType < requests.UpgradeTypeUpgrade || u.UpgradeType > requests.UpgradeTypeUnsafeDowngrade:
+		return fmt.Errorf("unknown upgrade type %d for feature %s", u.UpgradeType, u.Feature)
+	case u.MaxVersionLevel < 0:
+		return fmt.Errorf("invalid level %d for feature %s", u.MaxVersionLevel, u.Feature)
+	case u.MaxVersionLevel != 0 && (u.MaxVersionLevel < supported.Min || u.MaxVersionLevel > supported.Max):
+		return fmt.Errorf("level %d of feature %s is outside the supported range [%d, %d]",
+			u.MaxVersionLevel, u.Feature, supported.Min, supported.Max)
+	case u.MaxVersionLevel < current && u.UpgradeType == requests.UpgradeTypeUpgrade:
+		return fmt.Errorf("cannot downgrade %s from %d to %d without a downgrade upgrade type", u.Feature, current, u.MaxVersionLevel)
+	case u.MaxVersionLevel > current && u.UpgradeType != requests.UpgradeTypeUpgrade:
+		return fmt.Errorf("cannot upgrade %s from %d to %d with a downgrade upgrade type", u.Feature, current, u.MaxVersionLevel)
+	case u.Feature == MetadataVersionFeature && u.MaxVersionLevel == 0:
+		return fmt.Errorf("%s cannot be removed", MetadataVersionFeature)
+	case u.Feature == MetadataVersionFeature && u.MaxVersionLevel < current:
+		return fmt.Errorf("downgrading %s from %d to %d is not supported", MetadataVersionFeature, current, u.MaxVersionLevel)
+	}
+	return nil
+}
@@ -2,134 +2,275 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"io"
 	"net"
 	"os"
+	"runtime/debug"
+	"sync"
+	"time"
 
 	kafka "github.com/nabinkhanal00/kafka/app"
-	"github.com/nabinkhanal00/kafka/app/requests"
-	"github.com/nabinkhanal00/kafka/app/responses"
+	"github.com/nabinkhanal00/kafka/app/config"
+	"github.com/nabinkhanal00/kafka/app/types"
 	"github.com/sirupsen/logrus"
 )
 
-const BUFFER_SIZE = 1024
+// MAX_IN_FLIGHT bounds the number of pipelined requests a single
+// connection may have outstanding before the reader stops pulling frames.
+const MAX_IN_FLIGHT = 64
+
+var socketRequestMaxBytes = kafka.DefaultSocketRequestMaxBytes
+
+var registry *kafka.Registry
+
+func newRegistry(broker *kafka.Broker) *kafka.Registry {
+	registry := kafka.NewRegistry()
+	registry.Register(kafka.Produce, 3, 11, kafka.NewProduceHandler(broker))
+	registry.Register(kafka.Fetch, 4, 16, kafka.NewFetchHandler(broker))
+	registry.Register(kafka.ListOffsets, 1, 9, kafka.NewListOffsetsHandler(broker))
+	registry.Register(kafka.Metadata, 0, 12, kafka.NewMetadataHandler(broker))
+	registry.Register(kafka.ApiVersions, 0, 4, kafka.NewAPIVersionsHandler(registry, broker))
+	registry.Register(kafka.CreateTopics, 2, 7, kafka.NewCreateTopicsHandler(broker))
+	registry.Register(kafka.DeleteTopics, 1, 6, kafka.NewDeleteTopicsHandler(broker))
+	registry.Register(kafka.CreatePartitions, 0, 3, kafka.NewCreatePartitionsHandler(broker))
+	registry.Register(kafka.DeleteRecords, 0, 2, kafka.NewDeleteRecordsHandler(broker))
+	registry.Register(kafka.OffsetCommit, 2, 8, kafka.NewOffsetCommitHandler(broker))
+	registry.Register(kafka.OffsetFetch, 1, 8, kafka.NewOffsetFetchHandler(broker))
+	registry.Register(kafka.FindCoordinator, 0, 5, kafka.NewFindCoordinatorHandler(broker))
+	registry.Register(kafka.JoinGroup, 0, 9, kafka.NewJoinGroupHandler(broker))
+	registry.Register(kafka.SyncGroup, 0, 5, kafka.NewSyncGroupHandler(broker))
+	registry.Register(kafka.Heartbeat, 0, 4, kafka.NewHeartbeatHandler(broker))
+	registry.Register(kafka.LeaveGroup, 0, 5, kafka.NewLeaveGroupHandler(broker))
+	registry.Register(kafka.ListGroups, 0, 5, kafka.NewListGroupsHandler(broker))
+	registry.Register(kafka.DescribeGroups, 0, 5, kafka.NewDescribeGroupsHandler(broker))
+	registry.Register(kafka.DeleteGroups, 0, 2, kafka.NewDeleteGroupsHandler(broker))
+	registry.Register(kafka.OffsetDelete, 0, 0, kafka.NewOffsetDeleteHandler(broker))
+	registry.Register(kafka.DescribeConfigs, 0, 4, kafka.NewDescribeConfigsHandler(broker))
+	registry.Register(kafka.AlterConfigs, 0, 2, kafka.NewAlterConfigsHandler(broker))
+	registry.Register(kafka.IncrementalAlterConfigs, 0, 1, kafka.NewIncrementalAlterConfigsHandler(broker))
+	registry.Register(kafka.UpdateFeatures, 0, 1, kafka.NewUpdateFeaturesHandler(broker))
+	registry.Register(kafka.DescribeTopicPartitions, 0, 0, kafka.NewDescribeTopicPartitionsHandler(broker))
+	return registry
+}
 
 var log = logrus.New()
 
 func main() {
-	var _propFilePath string
+	log.Out = os.Stdout
+	cfg := config.Default()
 	if len(os.Args) == 2 {
-
-		_propFilePath = os.Args[1]
+		var err error
+		if cfg, err = config.Load(os.Args[1]); err != nil {
+			log.Errorf("Invalid configuration: %v", err)
+			os.Exit(1)
+		}
+		for _, name := range cfg.Unknown() {
+			log.Warnf("Ignoring unknown property %s", name)
+		}
 	}
-	_ = _propFilePath
+	socketRequestMaxBytes = cfg.SocketRequestMaxBytes
+	types.MaxDecompressedSize = int(cfg.SocketRequestMaxBytes)
 
-	log.Out = os.Stdout
-	l, err := net.Listen("tcp", "0.0.0.0:9092")
+	broker, err := kafka.NewBroker(cfg)
 	if err != nil {
-		log.Warnln("Failed to bind to port 9092")
+		log.Errorf("Failed to load logs: %v", err)
+		os.Exit(1)
+	}
+	defer broker.Close()
+	log.Infof("Loaded %d topics from the metadata log in %s", len(broker.Metadata.Topics()), cfg.MetadataLogDir)
+	log.Infof("Loaded %d partition logs from %v", len(broker.Logs.Partitions()), cfg.LogDirs)
+	registry = newRegistry(broker)
+	go loadGroups(broker)
+	go enforceRetention(broker, time.Duration(cfg.LogRetentionCheckIntervalMs)*time.Millisecond)
+	if cfg.LogCleanerEnable && cfg.LogCleanerThreads > 0 {
+		go cleanLogs(broker, int(cfg.LogCleanerThreads), time.Duration(cfg.LogCleanerBackoffMs)*time.Millisecond)
+	}
+
+	endpoints := cfg.BrokerListeners()
+	if len(endpoints) == 0 {
+		log.Errorf("No broker listener configured in listeners")
 		os.Exit(1)
 	}
-	log.Infof("Listening on %s\n", l.Addr().String())
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
+		l, err := net.Listen("tcp", endpoint.Address())
+		if err != nil {
+			log.Warnf("Failed to bind to %s: %v", endpoint.Address(), err)
+			os.Exit(1)
+		}
+		log.Infof("Listening on %s (%s)", l.Addr().String(), endpoint.Name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve(l, endpoint.Name)
+		}()
+	}
+	wg.Wait()
+}
+
+// loadGroups reads the committed offsets back from __consumer_offsets while
+// the listeners already serve requests; groups answer
+// COORDINATOR_LOAD_IN_PROGRESS until then.
+func loadGroups(broker *kafka.Broker) {
+	start := time.Now()
+	if err := broker.LoadGroups(); err != nil {
+		log.Errorf("Failed to load groups: %v", err)
+		return
+	}
+	log.Infof("Loaded groups from %s in %v", kafka.OffsetsTopic, time.Since(start))
+}
+
+// enforceRetention deletes the log segments that breach retention every
+// log.retention.check.interval.ms.
+func enforceRetention(broker *kafka.Broker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		n, err := broker.Logs.DeleteOldSegments()
+		if err != nil {
+			log.Errorf("Failed to apply log retention: %v", err)
+		}
+		if n > 0 {
+			log.Infof("Deleted %d log segments past retention", n)
+		}
+	}
+}
+
+// cleanLogs compacts the logs of compacted topics on a pool of threads
+// every log.cleaner.backoff.ms.
+func cleanLogs(broker *kafka.Broker, threads int, backoff time.Duration) {
+	ticker := time.NewTicker(backoff)
+	defer ticker.Stop()
+	for range ticker.C {
+		n, err := broker.Logs.Clean(threads)
+		if err != nil {
+			log.Errorf("Failed to clean logs: %v", err)
+		}
+		if n > 0 {
+			log.Infof("Log cleaner reclaimed %d bytes", n)
+		}
+	}
+}
+
+func serve(l net.Listener, listener string) {
 	for {
 		conn, err := l.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			log.Errorf("Could not accept connection: %v", err)
 			continue
 		}
 		log.Infof("Accepted Connection from %s", conn.RemoteAddr().String())
-		go handleConnection(conn)
+		go handleConnection(conn, listener)
 	}
 }
 
-func handleConnection(c net.Conn) {
+func handleConnection(c net.Conn, listener string) {
 	defer c.Close()
-	buffer := make([]byte, BUFFER_SIZE)
-	conn := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+	// A request that panics while being parsed only costs its connection.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Panic reading from %s: %v\n%s", c.RemoteAddr().String(), r, debug.Stack())
+		}
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	frames := kafka.NewFrameReader(bufio.NewReader(c), socketRequestMaxBytes)
+	clientHost := "/" + c.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(c.RemoteAddr().String()); err == nil {
+		clientHost = "/" + host
+	}
+	writer := bufio.NewWriter(c)
+
+	// Requests are handled one after another, except for parked requests
+	// such as long polling fetches, which run on their own goroutine.
+	// Responses must still leave in the order the requests arrived, so
+	// every request reserves a slot in pending and the writer drains the
+	// slots in order.
+	pending := make(chan chan []byte, MAX_IN_FLIGHT)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for slot := range pending {
+			respBytes, ok := <-slot
+			if !ok {
+				continue
+			}
+			n, err := writer.Write(respBytes)
+			if err == nil && len(pending) == 0 {
+				err = writer.Flush()
+			}
+			if err != nil {
+				log.Errorf("Could not write to %s: %v", c.RemoteAddr().String(), err)
+				c.Close()
+				continue
+			}
+			log.Debugf("Wrote %d bytes to %s", n, c.RemoteAddr().String())
+		}
+		writer.Flush()
+	}()
+	defer func() {
+		// Release parked requests such as long polling fetches before
+		// waiting for the outstanding responses to drain.
+		cancel()
+		close(pending)
+		<-done
+	}()
+
 	for {
-		n, err := conn.Read(buffer)
-		log.Infoln("Read second set of data")
+		frame, err := frames.ReadFrame()
 		if err != nil {
-			log.Errorf("Could not read from %s: %v", c.RemoteAddr().String(), err)
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Errorf("Could not read from %s: %v", c.RemoteAddr().String(), err)
+			}
 			return
 		}
-		log.Infof("Read %d bytes from %s", n, c.RemoteAddr().String())
-		log.Infof("Got Data: %X\n", buffer[:n])
-		request, err := kafka.UnmarshallRequest(buffer[:n])
+		log.Debugf("Read %d bytes from %s", len(frame), c.RemoteAddr().String())
+		request, err := kafka.UnmarshallRequest(frame, registry)
 		if err != nil {
 			log.Errorf("Failed to parse request: %v", err)
 			return
 		}
-		rh, ok := request.Header.(*kafka.RequestHeaderV2)
-		if !ok {
-			log.Errorf("Invalid request header type")
+		request.Listener = listener
+		request.ClientHost = clientHost
+		slot := make(chan []byte, 1)
+		pending <- slot
+		if registry.Parks(&request) {
+			go func() {
+				if !handleRequest(ctx, &request, slot) {
+					c.Close()
+				}
+			}()
+			continue
+		}
+		if !handleRequest(ctx, &request, slot) {
 			return
 		}
+	}
+}
 
-		var response kafka.Response
-		switch rh.RequestAPIKey {
-		case kafka.ApiVersions:
-			errorCode := kafka.NONE
-			if rh.RequestAPIVersion < 0 || rh.RequestAPIVersion > 4 {
-				errorCode = kafka.UNSUPPORTED_VERSION
-			}
-
-			response = kafka.Response{
-				Header: &kafka.ResponseHeaderV0{
-					CorrelationID: rh.CorrelationID,
-				},
-				Body: &responses.APIVersionsV4{
-					ErrorCode: errorCode,
-					APIKeys: []responses.APIKey{
-						{
-							Key:        18,
-							MaxVersion: 4,
-							MinVersion: 0,
-						},
-						{
-							Key:        75,
-							MaxVersion: 0,
-							MinVersion: 0,
-						},
-					},
-				},
-			}
-		case kafka.DescribeTopicPartitions:
-			rb, ok := request.Body.(*requests.DescribeTopicPartitionsV0)
-			if !ok {
-				log.Errorf("Invalid request body type")
-				return
-			}
-			requestTopics := rb.Topics
-			responseTopics := []responses.Topic{}
-			for _, topic := range requestTopics {
-				rt := responses.Topic{}
-				rt.ErrorCode = kafka.UNKNOWN_TOPIC_OR_PARTITION
-				rt.TopicName = topic.Name
-				responseTopics = append(responseTopics, rt)
-			}
-			response = kafka.Response{
-				Header: &kafka.ResponseHeaderV1{
-					CorrelationID: rh.CorrelationID,
-				},
-				Body: &responses.DescribeTopicPartitionsV0{
-					Topics: responseTopics,
-					NextCursor: responses.Cursor{
-						TopicName:      "hello",
-						PartitionIndex: 0,
-					},
-				},
-			}
-
-		default:
-
-		}
-		respBytes := kafka.MarshallResponse(response)
-		n, err = conn.Write(respBytes)
-		if err != nil {
-			log.Errorf("Could not write to %s: %v", c.RemoteAddr().String(), err)
-			return
+// handleRequest handles request and hands its response to slot. It
+// returns false when the request failed or panicked, after which the
+// connection must be closed, as upstream does, rather than leaving the
+// client waiting for a response that never comes.
+func handleRequest(ctx context.Context, request *kafka.Request, slot chan<- []byte) (ok bool) {
+	defer close(slot)
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Panic handling api key %d: %v\n%s", request.Header.GetAPIKey(), r, debug.Stack())
+			ok = false
 		}
-		log.Infof("Wrote %d bytes to %s: %X", n, c.RemoteAddr().String(), respBytes)
-		conn.Flush()
+	}()
+	response, err := registry.Handle(ctx, request)
+	if err != nil {
+		log.Errorf("Failed to handle request: %v", err)
+		return false
+	}
+	if response != nil {
+		slot <- kafka.MarshallResponse(*response)
 	}
+	return true
 }
@@ -2,6 +2,10 @@ module github.com/nabinkhanal00/kafka
 
 go 1.24.3
 
-require github.com/sirupsen/logrus v1.9.3
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/pierrec/lz4/v4 v4.1.22
+	github.com/sirupsen/logrus v1.9.3
+)
 
 require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect